@@ -2,16 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
+	"github.com/Avi18971911/kafka-window/backend/internal/alert"
+	"github.com/Avi18971911/kafka-window/backend/internal/audit"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
 	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/config"
 	messageDecoder "github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/lag"
 	"github.com/Avi18971911/kafka-window/backend/internal/server/router"
+	"github.com/Avi18971911/kafka-window/backend/internal/stats"
+	"github.com/Avi18971911/kafka-window/backend/internal/table"
 	"github.com/IBM/sarama"
 	"go.uber.org/zap"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
 )
 
+// How long requests in flight are given to finish once the server is asked to stop
+const shutdownTimeout = 30 * time.Second
+
 // @title Kafka Window API
 // @version 1.0
 // @description This is a monitoring and analytics tool for Kafka.
@@ -27,31 +45,251 @@ import (
 
 func main() {
 	logger, err := zap.NewProduction()
-	defer logger.Sync()
-	// TODO: Get broker URL from config
-	brokers := []string{"localhost:9092"}
 	if err != nil {
 		log.Fatalf("failed to create logger: %v", err)
 	}
+	defer logger.Sync()
+
+	appConfig, err := config.Load(os.Getenv("KAFKA_WINDOW_CONFIG"))
+	if err != nil {
+		logger.Fatal("could not load config", zap.Error(err))
+	}
 
-	config := sarama.NewConfig()
-	config.ClientID = "kafka-ui"
-	config.Version = sarama.V3_6_0_0
-	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.ClientID = "kafka-ui"
+	saramaConfig.Version = sarama.V3_6_0_0
+	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
 
 	avroConfig := avro.NewConfig(true, []string{"http://schema-registry:8081"})
 	avroService := avro.NewAvroService(avroConfig)
-	decoder := messageDecoder.NewMessageDecoder(avroService)
+	decoder := messageDecoder.NewMessageDecoder(avroService, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	kafkaService := kafka.NewKafkaService(decoder, logger)
-	err = kafkaService.ConnectToCluster(brokers, config)
+	fetchConfig := kafka.DefaultFetchConfig()
+	fetchConfig.MaxPartitionBytes = appConfig.Fetch.MaxPartitionBytes
+	fetchConfig.BrokerConcurrency = appConfig.Fetch.BrokerConcurrency
+	kafkaService.SetFetchConfig(fetchConfig)
+	err = kafkaService.ConnectToCluster(ctx, appConfig.Clusters[0].Brokers, saramaConfig)
 	if err != nil {
 		logger.Fatal("could not connect to broker", zap.Error(err))
 	}
 	defer kafkaService.Close()
-	r := router.CreateRouter(context.Background(), kafkaService, logger)
+	for _, cluster := range appConfig.Clusters {
+		kafkaService.RegisterCluster(cluster.Name, cluster.Brokers, saramaConfig)
+	}
+
+	jobManager, err := job.NewManager(
+		ctx,
+		job.NewFileStore(filepath.Join(appConfig.DataDir, "jobs.json")),
+		job.Config{
+			MaxConcurrent: appConfig.Jobs.MaxConcurrent,
+			HistorySize:   appConfig.Jobs.HistorySize,
+			FileDir:       filepath.Join(appConfig.DataDir, "job-files"),
+		},
+		logger,
+	)
+	if err != nil {
+		logger.Fatal("could not create job manager", zap.Error(err))
+	}
+
+	sampler := stats.NewSampler(
+		kafkaService,
+		stats.Config{
+			SampleInterval: appConfig.Stats.SampleInterval,
+			Windows:        appConfig.Stats.Windows,
+		},
+		logger,
+	)
+	// The background workers use the Kafka client and the lag store, so they are waited for before those are closed
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
+	runWorker(sampler.Run)
+
+	lagStore, err := lag.OpenStore(filepath.Join(appConfig.DataDir, "lag.db"))
+	if err != nil {
+		logger.Fatal("could not open lag history", zap.Error(err))
+	}
+	defer lagStore.Close()
+	lagTiers := make([]lag.Tier, 0, len(appConfig.Lag.Downsampling))
+	for _, downsampling := range appConfig.Lag.Downsampling {
+		lagTiers = append(lagTiers, lag.Tier{Resolution: downsampling.Resolution, Retention: downsampling.Retention})
+	}
+	lagCollector := lag.NewCollector(
+		kafkaService,
+		lagStore,
+		lag.Config{
+			Interval:     appConfig.Lag.Interval,
+			RawRetention: appConfig.Lag.RawRetention,
+			Downsampling: lagTiers,
+		},
+		logger,
+	)
+	runWorker(lagCollector.Run)
+
+	alertSinkConfigs := make([]alert.SinkConfig, 0, len(appConfig.Alerts.Sinks))
+	for _, sink := range appConfig.Alerts.Sinks {
+		alertSinkConfigs = append(alertSinkConfigs, alert.SinkConfig{
+			Name:    sink.Name,
+			Type:    alert.SinkType(sink.Type),
+			URL:     sink.URL,
+			Headers: sink.Headers,
+		})
+	}
+	alertSinks, err := alert.NewSinks(alertSinkConfigs, logger)
+	if err != nil {
+		logger.Fatal("could not create alert sinks", zap.Error(err))
+	}
+	alertRules := make([]alert.Rule, 0, len(appConfig.Alerts.Rules))
+	for _, rule := range appConfig.Alerts.Rules {
+		alertRules = append(alertRules, alert.Rule{
+			Name:      rule.Name,
+			Type:      alert.RuleType(rule.Type),
+			Groups:    rule.Groups,
+			Topics:    rule.Topics,
+			Threshold: rule.Threshold,
+			For:       rule.For,
+			Severity:  rule.Severity,
+			Sinks:     rule.Sinks,
+		})
+	}
+	alertEngine, err := alert.NewEngine(
+		kafkaService,
+		lagCollector,
+		alert.Config{Interval: appConfig.Alerts.Interval, Rules: alertRules},
+		alertSinks,
+		alert.NewFileSilenceStore(filepath.Join(appConfig.DataDir, "silences.json")),
+		logger,
+	)
+	if err != nil {
+		logger.Fatal("could not create alert engine", zap.Error(err))
+	}
+	runWorker(alertEngine.Run)
+
+	tableStore := table.NewStore(table.Config{MaxTables: appConfig.Tables.MaxTables})
+
+	authConfig := auth.Config{HtpasswdFile: appConfig.Auth.HtpasswdFile}
+	for _, token := range appConfig.Auth.Tokens {
+		authConfig.Tokens = append(authConfig.Tokens, auth.Token{Name: token.Name, Token: token.Token})
+	}
+	if oidc := appConfig.Auth.OIDC; oidc != nil {
+		authConfig.OIDC = &auth.OIDCConfig{
+			Issuer:        oidc.Issuer,
+			Audience:      oidc.Audience,
+			JWKSURL:       oidc.JWKSURL,
+			UsernameClaim: oidc.UsernameClaim,
+		}
+	}
+	authenticators, err := auth.NewAuthenticators(authConfig)
+	if err != nil {
+		logger.Fatal("could not set up authentication", zap.Error(err))
+	}
+	if len(authenticators) == 0 {
+		logger.Warn("authentication is disabled, since no credentials are configured")
+	}
+
+	rbacConfig := auth.RBACConfig{DefaultRoles: appConfig.RBAC.DefaultRoles, Cluster: appConfig.Clusters[0].Name}
+	for _, role := range appConfig.RBAC.Roles {
+		authRole := auth.Role{Name: role.Name}
+		for _, permission := range role.Permissions {
+			authPermission := auth.Permission{
+				Clusters: permission.Clusters,
+				Topics:   permission.Topics,
+				Groups:   permission.Groups,
+			}
+			for _, operation := range permission.Operations {
+				authPermission.Operations = append(authPermission.Operations, auth.Operation(operation))
+			}
+			authRole.Permissions = append(authRole.Permissions, authPermission)
+		}
+		rbacConfig.Roles = append(rbacConfig.Roles, authRole)
+	}
+	for _, binding := range appConfig.RBAC.Bindings {
+		rbacConfig.Bindings = append(rbacConfig.Bindings, auth.Binding{Role: binding.Role, Users: binding.Users})
+	}
+	authorizer, err := auth.NewAuthorizer(rbacConfig)
+	if err != nil {
+		logger.Fatal("could not set up access control", zap.Error(err))
+	}
+	if authorizer != nil && len(authenticators) == 0 {
+		logger.Warn("roles are configured but have no effect, since authentication is disabled")
+	}
+	kafkaService.SetAuthorizer(authorizer)
+
+	auditFile, err := audit.OpenFileWriter(appConfig.Audit.File)
+	if err != nil {
+		logger.Fatal("could not open audit log", zap.Error(err))
+	}
+	auditWriters := []audit.Writer{auditFile}
+	if appConfig.Audit.Topic != "" {
+		auditProducer, err := audit.NewKafkaWriter(
+			appConfig.Clusters[0].Brokers,
+			saramaConfig,
+			appConfig.Audit.Topic,
+			logger,
+		)
+		if err != nil {
+			logger.Fatal("could not create audit producer", zap.Error(err))
+		}
+		auditWriters = append(auditWriters, auditProducer)
+	}
+	recentAuditEntries, err := audit.ReadTail(appConfig.Audit.File, appConfig.Audit.RecentEntries)
+	if err != nil {
+		logger.Error("could not restore recent audit entries", zap.Error(err))
+	}
+	auditLog := audit.NewLog(
+		audit.Config{RecentEntries: appConfig.Audit.RecentEntries},
+		recentAuditEntries,
+		auditWriters,
+		logger,
+	)
+	defer func() {
+		if err := auditLog.Close(); err != nil {
+			logger.Error("Failed to close audit log", zap.Error(err))
+		}
+	}()
+
+	server := &http.Server{
+		Addr: ":8085",
+		Handler: router.CreateRouter(
+			kafkaService,
+			jobManager,
+			sampler,
+			lagCollector,
+			alertEngine,
+			tableStore,
+			authenticators,
+			authorizer,
+			auditLog,
+			logger,
+		),
+	}
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		// Running jobs are canceled along with ctx, so they are recorded as canceled rather than interrupted
+		jobManager.Wait()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down server", zap.Error(err))
+		}
+	}()
 	logger.Info("Starting query server at :8085")
-	if err := http.ListenAndServe(":8085", r); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Fatal("Failed to serve: %v", zap.Error(err))
 	}
+	// ListenAndServe returns as soon as the listener is closed, while requests are still being drained. The deferred
+	// closes of the audit log, the lag store and the Kafka client only run once they and the workers are done
+	<-shutdownDone
+	workers.Wait()
 }
@@ -15,40 +15,45 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/topics": {
+        "/alerts": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "topics"
+                    "alerts"
                 ],
-                "summary": "Get a list of all topics.",
+                "summary": "List the alerts that fire and those that resolved in the last day, most recently fired first.",
                 "parameters": [
                     {
-                        "description": "No parameters required",
-                        "name": "-",
-                        "in": "body",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "enum": [
+                            "firing",
+                            "resolved"
+                        ],
+                        "type": "string",
+                        "description": "Only return alerts in this state",
+                        "name": "state",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of topic names",
+                        "description": "List of alerts",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.TopicDetails"
+                                "$ref": "#/definitions/alert.Alert"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorMessage"
                         }
@@ -56,8 +61,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/topics/messages": {
+        "/alerts/silences": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List the silences that haven't ended, ending soonest first.",
+                "responses": {
+                    "200": {
+                        "description": "List of silences",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alert.Silence"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            },
             "post": {
+                "description": "Silenced alerts still fire and resolve, but no notifications are sent for them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,27 +97,155 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "topics"
+                    "alerts"
                 ],
-                "summary": "Get messages from a topic.",
+                "summary": "Silence the alerts matching a rule, group and topic for a while.",
                 "parameters": [
                     {
-                        "description": "Topic messages input",
-                        "name": "topicMessagesInput",
+                        "description": "Silence input",
+                        "name": "silenceInput",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.TopicMessagesInputDTO"
+                            "$ref": "#/definitions/dto.SilenceInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created silence",
+                        "schema": {
+                            "$ref": "#/definitions/alert.Silence"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts/silences/{id}": {
+            "delete": {
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "End a silence, so that notifications are sent for the alerts it matched again.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The ID of the silence",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The silence ended"
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Silence not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
                         }
                     }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "Only the newest entries are kept in memory to be queried. The audit file holds every entry.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Query the recent entries of the audit log, newest first.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Who made the requests",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the method and route of the requests, such as /topics/messages",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "A topic the requests acted on",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "succeeded",
+                            "denied",
+                            "rejected",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "The outcome of the requests",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The earliest time of the requests in RFC 3339",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The latest time of the requests in RFC 3339",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The most entries returned, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of messages",
+                        "description": "The matching entries",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Message"
+                                "$ref": "#/definitions/audit.Entry"
                             }
                         }
                     },
@@ -95,6 +255,72 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorMessage"
                         }
                     },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/consumer-groups/{group}/lag": {
+            "get": {
+                "description": "The lag is polled in the background and kept at coarser resolutions as it ages, so long ranges\nreturn fewer points. Rates and the time to catch up are computed between consecutive points.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consumer-groups"
+                ],
+                "summary": "Get the lag history of a consumer group on a topic.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The ID of the consumer group",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The topic the group consumes",
+                        "name": "topic",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The start of the range in RFC 3339, an hour before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The end of the range in RFC 3339, now by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The lag history, oldest first",
+                        "schema": {
+                            "$ref": "#/definitions/lag.History"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -103,124 +329,2422 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.TopicMessagesInputDTO": {
-            "type": "object",
-            "required": [
-                "partitions",
-                "topicName"
-            ],
-            "properties": {
-                "partitions": {
-                    "description": "The Partition request data of the topic to fetch messages from",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.TopicPartitionInputDTO"
+        },
+        "/jobs": {
+            "get": {
+                "description": "Callers only see the jobs they submitted, unless they are allowed to admin-jobs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "List background jobs, newest first.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "copy",
+                            "diff",
+                            "export",
+                            "import",
+                            "key-distribution",
+                            "table-scan"
+                        ],
+                        "type": "string",
+                        "description": "Job kind",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "queued",
+                            "running",
+                            "succeeded",
+                            "failed",
+                            "canceled"
+                        ],
+                        "type": "string",
+                        "description": "Only return jobs in this state",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of jobs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/job.Job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
                     }
-                },
-                "topicName": {
-                    "description": "The name of the topic to fetch messages from",
-                    "type": "string"
                 }
             }
         },
-        "dto.TopicPartitionInputDTO": {
-            "type": "object",
-            "required": [
-                "endOffset",
-                "partition",
-                "startOffset"
-            ],
-            "properties": {
-                "endOffset": {
-                    "description": "The end offset of the partition to fetch messages from\nNegative if the offset is to be from the latest offset, so for example -30 means 30 messages from the end\n-1 means the latest offset\nInclusive",
-                    "type": "integer"
-                },
-                "partition": {
-                    "description": "The ID of the partition to fetch messages from",
+        "/jobs/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get the status and progress of a background job.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The ID of the job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The job",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Queued jobs are canceled right away. Running jobs are reported as canceled once they have stopped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Cancel a background job.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The ID of the job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The job",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/file": {
+            "get": {
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Download the file a background job produced, such as an export.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The ID of the job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Job or file not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Job has not succeeded",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions": {
+            "get": {
+                "description": "Callers that aren't restricted, since access control or authentication is disabled, may do anything.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Get the roles of the caller and the permissions they grant.",
+                "responses": {
+                    "200": {
+                        "description": "The roles and permissions of the caller",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Grants"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions/check": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Check whether the caller is allowed to perform an operation on a resource.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The operation, such as view-messages or produce",
+                        "name": "operation",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The cluster of the resource, the cluster that is browsed by default",
+                        "name": "cluster",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The topic of the resource",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The consumer group of the resource",
+                        "name": "group",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Whether the operation is allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PermissionCheck"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Get a list of all topics.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "standard",
+                            "connect-internal",
+                            "streams-changelog",
+                            "streams-repartition"
+                        ],
+                        "type": "string",
+                        "description": "Only return topics of this kind",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of topic names",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TopicDetails"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/analysis/keys": {
+            "post": {
+                "description": "The analysis samples the newest messages of every partition in proportion to the offsets it holds.\nIt runs as a background job, whose result is a model.KeyDistribution.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Analyze how the messages and keys of a topic are spread over its partitions.",
+                "parameters": [
+                    {
+                        "description": "Key distribution input",
+                        "name": "keyDistributionInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.KeyDistributionInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "The started analysis job",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/diff": {
+            "post": {
+                "description": "Records are matched by key and by a hash of their value, where JSON values are compared\nstructurally without the ignored paths. The comparison runs as a background job, whose result is a\nmodel.TopicDiff listing the missing, extra and differing records.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Compare the records of a target with those of a source, such as a topic and its migrated copy.",
+                "parameters": [
+                    {
+                        "description": "Topic diff input",
+                        "name": "topicDiffInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TopicDiffInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "The started comparison job",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/messages": {
+            "post": {
+                "description": "Every partition reports whether its whole range was read, which it is unless reading it failed.\nMessages produced in transactions carry the state of their transaction.\nBy default messages are listed partition by partition, and can instead be merged by timestamp.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Get messages from a topic.",
+                "parameters": [
+                    {
+                        "description": "Topic messages input",
+                        "name": "topicMessagesInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TopicMessagesInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The messages, with the part of each partition's range that was read",
+                        "schema": {
+                            "$ref": "#/definitions/model.TopicMessages"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/messages/copy": {
+            "post": {
+                "description": "The copy runs as a background job, whose progress is reported at /jobs/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Copy messages to another topic, possibly on another cluster.",
+                "parameters": [
+                    {
+                        "description": "Topic messages copy input",
+                        "name": "topicMessagesCopyInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TopicMessagesCopyInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "The started copy job",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/messages/export": {
+            "post": {
+                "description": "Messages are streamed to the client as they are consumed, so exports are not bounded by memory.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/avro"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Export messages from a topic as JSONL, CSV or an Avro container file.",
+                "parameters": [
+                    {
+                        "description": "Topic messages export input",
+                        "name": "topicMessagesExportInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TopicMessagesExportInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The exported messages",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/messages/export/jobs": {
+            "post": {
+                "description": "The file can be downloaded from /jobs/{id}/file once the job has succeeded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Start a job exporting messages from a topic as JSONL, CSV or an Avro container file.",
+                "parameters": [
+                    {
+                        "description": "Topic messages export input",
+                        "name": "topicMessagesExportInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TopicMessagesExportInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "The started export job",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/messages/import": {
+            "post": {
+                "description": "The body is a JSONL export, optionally gzip compressed. Exports with raw bytes are reproduced exactly.\nThe import runs as a background job, whose result carries the importId to resume it with should it fail.",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Import messages from a JSONL export into a topic.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The topic to produce the messages to",
+                        "name": "topicName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "keep",
+                            "key"
+                        ],
+                        "type": "string",
+                        "description": "How records are assigned to partitions, by key by default",
+                        "name": "partitioning",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "keep",
+                            "now",
+                            "shift"
+                        ],
+                        "type": "string",
+                        "description": "How record timestamps are set, kept by default",
+                        "name": "timestamps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The maximum number of messages produced per second",
+                        "name": "messagesPerSecond",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The ID of an earlier import to resume",
+                        "name": "importId",
+                        "in": "query"
+                    },
+                    {
+                        "description": "The JSONL export",
+                        "name": "messages",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "The started import job, whose result is a model.ImportResult",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/messages/latest": {
+            "post": {
+                "description": "Returns the newest messages of the topic by timestamp, so that partitions that were written to\nrecently contribute more messages than idle ones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Get the newest messages across a topic.",
+                "parameters": [
+                    {
+                        "description": "Latest topic messages input",
+                        "name": "latestTopicMessagesInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LatestTopicMessagesInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The messages, with the part of each partition's range that was read",
+                        "schema": {
+                            "$ref": "#/definitions/model.TopicMessages"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/schema": {
+            "get": {
+                "description": "The report lists every field path with the types observed, how often it was present and null, and\nexamples. With a format, the schema is returned as a JSON Schema or as a draft of an Avro schema.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Infer the schema of the JSON keys or values of the newest messages of a topic.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The topic to sample",
+                        "name": "topic",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The number of newest messages sampled, 500 by default",
+                        "name": "sampleSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "key",
+                            "value"
+                        ],
+                        "type": "string",
+                        "description": "Whether the keys or the values are inferred, the values by default",
+                        "name": "part",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json-schema",
+                            "avro"
+                        ],
+                        "type": "string",
+                        "description": "Return the schema in this format instead of the report",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The inferred schema, or the schema in the requested format",
+                        "schema": {
+                            "$ref": "#/definitions/model.InferredSchema"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Topic not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/sizes": {
+            "get": {
+                "description": "Sizes are those of the uncompressed records, as p50, p95, p99 and max. They are compared with the\nmax.message.bytes of the topic, or the message.max.bytes of the brokers when the topic doesn't\noverride it, and topics whose largest sampled record reaches the threshold share of it are at risk.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Get the sizes of the keys, values and headers of the newest messages of topics.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only analyze this topic instead of every topic but the internal ones",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The most messages sampled from every topic, 1000 by default",
+                        "name": "sampleSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "The share of the limit a topic is at risk from, 0.8 by default",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The sizes of every topic, highest utilization first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TopicMessageSizes"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Topic not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/stats": {
+            "get": {
+                "description": "Throughput is measured from high watermarks and partition sizes that are sampled in the background,\nso it only covers the time since the server started.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Get throughput, size and retention statistics of topics.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return the statistics of this topic",
+                        "name": "topic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The statistics of every topic, ordered by name",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/stats.TopicStats"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Topic not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/tables": {
+            "post": {
+                "description": "The scan runs as a background job, whose result is a model.TableSummary that includes how many\nsuperseded messages compaction hasn't removed yet. Once it succeeds, the rows can be paged through\nand looked up by key at /topics/tables/{id}, where id is the ID of the job. Only the latest scans\nare kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Scan a compacted topic for the newest message of every key.",
+                "parameters": [
+                    {
+                        "description": "Table scan input",
+                        "name": "tableScanInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TableScanInputDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "The started scan job",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/tables/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Get the newest message of every key of a scanned compacted topic.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The ID of the scan job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The number of rows skipped, 0 by default",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The most rows returned, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether keys whose newest message is a tombstone are included",
+                        "name": "tombstones",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The rows, ordered by key",
+                        "schema": {
+                            "$ref": "#/definitions/model.TablePage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Table not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/topics/tables/{id}/keys": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topics"
+                ],
+                "summary": "Get the newest message of a key of a scanned compacted topic.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The ID of the scan job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The key, as text or base64 encoded",
+                        "name": "key",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether the key is base64 encoded, as keys that aren't valid UTF-8 are listed",
+                        "name": "base64",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether a key whose newest message is a tombstone is returned",
+                        "name": "tombstones",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The row of the key",
+                        "schema": {
+                            "$ref": "#/definitions/model.TableRow"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Table or key not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "alert.Alert": {
+            "type": "object",
+            "required": [
+                "firedAt",
+                "id",
+                "message",
+                "rule",
+                "silenced",
+                "since",
+                "state",
+                "topic",
+                "type",
+                "value"
+            ],
+            "properties": {
+                "firedAt": {
+                    "type": "string"
+                },
+                "group": {
+                    "description": "Only set for lag rules",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Stays the same for as long as the rule fires for the same subject",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "partition": {
+                    "description": "Only set for partition rules",
+                    "type": "integer"
+                },
+                "resolvedAt": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "silenced": {
+                    "description": "Whether a silence matches the alert, which keeps its notifications from being sent",
+                    "type": "boolean"
+                },
+                "since": {
+                    "description": "When the condition started to hold, which is For before the alert fired",
+                    "type": "string"
+                },
+                "state": {
+                    "enum": [
+                        "firing",
+                        "resolved"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/alert.State"
+                        }
+                    ]
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "One of lag_above, lag_growing, consumer_stalled, under_replicated, offline_partition and topic_idle",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/alert.RuleType"
+                        }
+                    ]
+                },
+                "value": {
+                    "description": "What the rule measured when the alert last held, such as the lag or the seconds without messages",
+                    "type": "number"
+                }
+            }
+        },
+        "alert.RuleType": {
+            "type": "string",
+            "enum": [
+                "lag_above",
+                "lag_growing",
+                "consumer_stalled",
+                "under_replicated",
+                "offline_partition",
+                "topic_idle"
+            ],
+            "x-enum-varnames": [
+                "RuleLagAbove",
+                "RuleLagGrowing",
+                "RuleConsumerStalled",
+                "RuleUnderReplicated",
+                "RuleOfflinePartition",
+                "RuleTopicIdle"
+            ]
+        },
+        "alert.Silence": {
+            "type": "object",
+            "required": [
+                "endsAt",
+                "id",
+                "startsAt"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "endsAt": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Globs that the rule, group and topic of an alert must match. Empty matches every alert",
+                    "type": "string"
+                },
+                "startsAt": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "alert.State": {
+            "type": "string",
+            "enum": [
+                "firing",
+                "resolved"
+            ],
+            "x-enum-varnames": [
+                "StateFiring",
+                "StateResolved"
+            ]
+        },
+        "audit.Entry": {
+            "type": "object",
+            "required": [
+                "id",
+                "operation",
+                "outcome",
+                "parameters",
+                "resources",
+                "status",
+                "time"
+            ],
+            "properties": {
+                "authMethod": {
+                    "$ref": "#/definitions/auth.Method"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "operation": {
+                    "description": "The method and route of the request, e.g. POST /topics/messages/copy",
+                    "type": "string"
+                },
+                "outcome": {
+                    "$ref": "#/definitions/audit.Outcome"
+                },
+                "parameters": {
+                    "description": "The path and query parameters and the JSON body of the request, with secrets redacted",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "resources": {
+                    "description": "The clusters, topics and groups named by the parameters. An empty cluster is the cluster that is browsed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.Resource"
+                    }
+                },
+                "status": {
+                    "description": "The status code of the response",
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "Who made the request. Empty while authentication is disabled",
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Outcome": {
+            "type": "string",
+            "enum": [
+                "succeeded",
+                "denied",
+                "rejected",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "OutcomeSucceeded",
+                "OutcomeDenied",
+                "OutcomeRejected",
+                "OutcomeFailed"
+            ]
+        },
+        "auth.Grants": {
+            "type": "object",
+            "properties": {
+                "identity": {
+                    "$ref": "#/definitions/auth.Identity"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.Permission"
+                    }
+                },
+                "restricted": {
+                    "description": "Whether the caller may only do what its permissions grant. Callers that aren't restricted may do anything",
+                    "type": "boolean"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth.Identity": {
+            "type": "object",
+            "required": [
+                "method",
+                "name"
+            ],
+            "properties": {
+                "method": {
+                    "$ref": "#/definitions/auth.Method"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.Method": {
+            "type": "string",
+            "enum": [
+                "token",
+                "basic",
+                "oidc"
+            ],
+            "x-enum-varnames": [
+                "MethodToken",
+                "MethodBasic",
+                "MethodOIDC"
+            ]
+        },
+        "auth.Operation": {
+            "type": "string",
+            "enum": [
+                "view-topics",
+                "view-messages",
+                "produce",
+                "admin-topics",
+                "view-groups",
+                "reset-offsets",
+                "view-acls",
+                "view-alerts",
+                "manage-alerts",
+                "admin-jobs",
+                "view-audit",
+                "*"
+            ],
+            "x-enum-varnames": [
+                "OperationViewTopics",
+                "OperationViewMessages",
+                "OperationProduce",
+                "OperationAdminTopics",
+                "OperationViewGroups",
+                "OperationResetOffsets",
+                "OperationViewACLs",
+                "OperationViewAlerts",
+                "OperationManageAlerts",
+                "OperationAdminJobs",
+                "OperationViewAudit",
+                "OperationAll"
+            ]
+        },
+        "auth.Permission": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.Operation"
+                    }
+                },
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth.Resource": {
+            "type": "object",
+            "properties": {
+                "cluster": {
+                    "description": "The name of the cluster, or the cluster that is browsed when empty",
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CopyDestinationDTO": {
+            "type": "object",
+            "required": [
+                "topicName"
+            ],
+            "properties": {
+                "cluster": {
+                    "description": "The name of a configured cluster. Defaults to the cluster of the source",
+                    "type": "string"
+                },
+                "topicName": {
+                    "description": "The name of the topic to copy messages to",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CopySourceDTO": {
+            "type": "object",
+            "required": [
+                "topicName"
+            ],
+            "properties": {
+                "endTime": {
+                    "description": "Only copy messages produced at or before this time",
+                    "type": "string"
+                },
+                "filter": {
+                    "description": "Only copy messages matching this filter",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.MessageFilterDTO"
+                        }
+                    ]
+                },
+                "partitions": {
+                    "description": "The offset ranges to copy. All partitions are copied in full when omitted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TopicPartitionInputDTO"
+                    }
+                },
+                "startTime": {
+                    "description": "Only copy messages produced at or after this time",
+                    "type": "string"
+                },
+                "topicName": {
+                    "description": "The name of the topic to copy messages from",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DiffSourceDTO": {
+            "type": "object",
+            "required": [
+                "topicName"
+            ],
+            "properties": {
+                "cluster": {
+                    "description": "The name of a configured cluster. Defaults to the connected cluster",
+                    "type": "string"
+                },
+                "partitions": {
+                    "description": "The offset ranges to compare. All partitions are compared in full when omitted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TopicPartitionInputDTO"
+                    }
+                },
+                "topicName": {
+                    "description": "The name of the topic to read",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ExportColumnDTO": {
+            "type": "object",
+            "required": [
+                "field"
+            ],
+            "properties": {
+                "field": {
+                    "description": "The field of the column, either one of topic, partition, offset, timestamp, key, value and headers,\nor a JSON path into the decoded value such as $.order.id",
+                    "type": "string"
+                },
+                "header": {
+                    "description": "The header of the column. Defaults to the field",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.KeyDistributionInputDTO": {
+            "type": "object",
+            "required": [
+                "topicName"
+            ],
+            "properties": {
+                "imbalanceThreshold": {
+                    "description": "How many times the mean messages or size a partition holds for the topic to be imbalanced. Defaults to 1.5",
+                    "type": "number"
+                },
+                "sampleSize": {
+                    "description": "The most messages sampled from the ends of the partitions. Defaults to 100000",
+                    "type": "integer"
+                },
+                "topK": {
+                    "description": "The number of most frequent keys reported. Defaults to 20",
+                    "type": "integer"
+                },
+                "topicName": {
+                    "description": "The name of the topic to analyze",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LatestTopicMessagesInputDTO": {
+            "type": "object",
+            "required": [
+                "count",
+                "topicName"
+            ],
+            "properties": {
+                "count": {
+                    "description": "The number of messages to fetch, which are the newest by timestamp across all partitions",
+                    "type": "integer"
+                },
+                "includeControlRecords": {
+                    "description": "Whether the commit and abort markers of transactions are returned as messages of their own",
+                    "type": "boolean"
+                },
+                "isolationLevel": {
+                    "description": "Whether the messages of aborted and open transactions are left out, read_uncommitted by default",
+                    "type": "string",
+                    "enum": [
+                        "read_uncommitted",
+                        "read_committed"
+                    ]
+                },
+                "order": {
+                    "description": "The order of the messages, partition_offset by default. timestamp merges the partitions by timestamp",
+                    "type": "string",
+                    "enum": [
+                        "partition_offset",
+                        "timestamp",
+                        "newest_first"
+                    ]
+                },
+                "topicName": {
+                    "description": "The name of the topic to fetch messages from",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MessageFilterDTO": {
+            "type": "object",
+            "properties": {
+                "endTime": {
+                    "description": "Only match messages produced at or before this time",
+                    "type": "string"
+                },
+                "keyContains": {
+                    "description": "Only match messages whose decoded key contains this string",
+                    "type": "string"
+                },
+                "startTime": {
+                    "description": "Only match messages produced at or after this time",
+                    "type": "string"
+                },
+                "valueContains": {
+                    "description": "Only match messages whose decoded value contains this string",
+                    "type": "string"
+                },
+                "valueEquals": {
+                    "description": "The value the element at ValuePath must equal, compared as text",
+                    "type": "string"
+                },
+                "valuePath": {
+                    "description": "A JSON path into the decoded value, such as $.order.status, which must equal ValueEquals",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SilenceInputDTO": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "Why the alerts are silenced",
+                    "type": "string"
+                },
+                "durationSeconds": {
+                    "description": "How long the silence lasts from now",
+                    "type": "integer"
+                },
+                "endsAt": {
+                    "description": "When the silence ends. Either this or durationSeconds is required",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "A glob the consumer group of silenced alerts matches. Every group when omitted",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "A glob the rule of silenced alerts matches. Every rule when omitted",
+                    "type": "string"
+                },
+                "topic": {
+                    "description": "A glob the topic of silenced alerts matches. Every topic when omitted",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TableScanInputDTO": {
+            "type": "object",
+            "required": [
+                "topicName"
+            ],
+            "properties": {
+                "topicName": {
+                    "description": "The name of the compacted topic to scan",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TopicDiffInputDTO": {
+            "type": "object",
+            "required": [
+                "source",
+                "target"
+            ],
+            "properties": {
+                "ignorePaths": {
+                    "description": "JSON paths left out when comparing JSON values, such as $.updatedAt",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "maxReported": {
+                    "description": "The most missing, extra and differing records listed each. Defaults to 100",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "The records that are expected",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.DiffSourceDTO"
+                        }
+                    ]
+                },
+                "target": {
+                    "description": "The records that are compared with the source",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.DiffSourceDTO"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.TopicMessagesCopyInputDTO": {
+            "type": "object",
+            "required": [
+                "destination",
+                "source"
+            ],
+            "properties": {
+                "destination": {
+                    "description": "Where to copy the messages to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.CopyDestinationDTO"
+                        }
+                    ]
+                },
+                "keepHeaders": {
+                    "description": "Whether the copies keep the headers of the original messages. Defaults to true",
+                    "type": "boolean"
+                },
+                "keepKey": {
+                    "description": "Whether the copies keep the key of the original messages. Defaults to true",
+                    "type": "boolean"
+                },
+                "keepPartition": {
+                    "description": "Whether the copies are produced to the partition of the original messages instead of being partitioned by key",
+                    "type": "boolean"
+                },
+                "source": {
+                    "description": "Where to copy the messages from",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.CopySourceDTO"
+                        }
+                    ]
+                },
+                "valueTransform": {
+                    "description": "Operations applied in order to JSON values. Values that are not JSON are copied unchanged",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TransformStepDTO"
+                    }
+                }
+            }
+        },
+        "dto.TopicMessagesExportInputDTO": {
+            "type": "object",
+            "required": [
+                "format",
+                "partitions",
+                "topicName"
+            ],
+            "properties": {
+                "columns": {
+                    "description": "The columns of a CSV export. Defaults to partition, offset, timestamp, key and value",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ExportColumnDTO"
+                    }
+                },
+                "filter": {
+                    "description": "Only export messages matching this filter",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.MessageFilterDTO"
+                        }
+                    ]
+                },
+                "format": {
+                    "description": "The file format of the export, one of jsonl, csv or avro",
+                    "type": "string",
+                    "enum": [
+                        "jsonl",
+                        "csv",
+                        "avro"
+                    ]
+                },
+                "gzip": {
+                    "description": "Whether the export is gzip compressed",
+                    "type": "boolean"
+                },
+                "includeRawBytes": {
+                    "description": "Whether a JSONL export also carries the raw key and value bytes as base64, which makes it replayable",
+                    "type": "boolean"
+                },
+                "partitions": {
+                    "description": "The Partition request data of the topic to export messages from",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TopicPartitionInputDTO"
+                    }
+                },
+                "topicName": {
+                    "description": "The name of the topic to export messages from",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TopicMessagesInputDTO": {
+            "type": "object",
+            "required": [
+                "partitions",
+                "topicName"
+            ],
+            "properties": {
+                "includeControlRecords": {
+                    "description": "Whether the commit and abort markers of transactions are returned as messages of their own",
+                    "type": "boolean"
+                },
+                "isolationLevel": {
+                    "description": "Whether the messages of aborted and open transactions are left out, read_uncommitted by default",
+                    "type": "string",
+                    "enum": [
+                        "read_uncommitted",
+                        "read_committed"
+                    ]
+                },
+                "order": {
+                    "description": "The order of the messages, partition_offset by default. timestamp merges the partitions by timestamp",
+                    "type": "string",
+                    "enum": [
+                        "partition_offset",
+                        "timestamp",
+                        "newest_first"
+                    ]
+                },
+                "partitions": {
+                    "description": "The Partition request data of the topic to fetch messages from",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TopicPartitionInputDTO"
+                    }
+                },
+                "topicName": {
+                    "description": "The name of the topic to fetch messages from",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TopicPartitionInputDTO": {
+            "type": "object",
+            "required": [
+                "endOffset",
+                "partition",
+                "startOffset"
+            ],
+            "properties": {
+                "endOffset": {
+                    "description": "The end offset of the partition to fetch messages from\nNegative if the offset is to be from the latest offset, so for example -30 means 30 messages from the end\n-1 means the latest offset\nInclusive",
+                    "type": "integer"
+                },
+                "partition": {
+                    "description": "The ID of the partition to fetch messages from",
+                    "type": "integer"
+                },
+                "startOffset": {
+                    "description": "The start offset of the partition to fetch messages from\nNegative if the offset is to be from the latest offset, so for example -30 means 30 messages from the end\n-1 means the latest offset\nInclusive",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.TransformStepDTO": {
+            "type": "object",
+            "required": [
+                "op",
+                "path"
+            ],
+            "properties": {
+                "op": {
+                    "description": "The operation, either set or remove",
+                    "type": "string",
+                    "enum": [
+                        "set",
+                        "remove"
+                    ]
+                },
+                "path": {
+                    "description": "The JSON path the operation applies to, such as $.customer.email",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "The JSON value a set operation stores at the path",
+                    "type": "object"
+                }
+            }
+        },
+        "handler.ErrorMessage": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.PermissionCheck": {
+            "type": "object",
+            "required": [
+                "allowed",
+                "operation",
+                "resource"
+            ],
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "operation": {
+                    "$ref": "#/definitions/auth.Operation"
+                },
+                "resource": {
+                    "$ref": "#/definitions/auth.Resource"
+                }
+            }
+        },
+        "job.Job": {
+            "type": "object",
+            "required": [
+                "createdAt",
+                "description",
+                "id",
+                "kind",
+                "progress",
+                "status"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "A human readable summary of what the job does",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why the job failed",
+                    "type": "string"
+                },
+                "fileName": {
+                    "description": "The name of the file the job produced, which can be downloaded from /jobs/{id}/file",
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Who submitted the job, when authentication is enabled",
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "Counters reported by the job while it runs, such as the number of records copied so far",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "result": {
+                    "description": "The outcome of the job, specific to its kind. Failed jobs may report how far they got"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/job.Status"
+                }
+            }
+        },
+        "job.Status": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "succeeded",
+                "failed",
+                "canceled"
+            ],
+            "x-enum-varnames": [
+                "StatusQueued",
+                "StatusRunning",
+                "StatusSucceeded",
+                "StatusFailed",
+                "StatusCanceled"
+            ]
+        },
+        "lag.History": {
+            "type": "object",
+            "required": [
+                "group",
+                "points",
+                "resolutionSeconds",
+                "topic"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/lag.HistoryPoint"
+                    }
+                },
+                "resolutionSeconds": {
+                    "description": "The span of time that each point covers. 0 when every sample is returned as it was taken",
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "lag.HistoryPoint": {
+            "type": "object",
+            "required": [
+                "committedOffset",
+                "highWatermark",
+                "lag",
+                "maxLag",
+                "timestamp"
+            ],
+            "properties": {
+                "committedOffset": {
+                    "description": "The committed offsets of every partition the group committed for, summed",
+                    "type": "integer"
+                },
+                "consumeRate": {
+                    "description": "The messages per second the group consumed since the previous point. Missing for the first point, and when\nthe group committed for other partitions or its offsets were reset since",
+                    "type": "number"
+                },
+                "highWatermark": {
+                    "description": "The high watermarks of the same partitions, summed",
+                    "type": "integer"
+                },
+                "lag": {
+                    "type": "integer"
+                },
+                "maxLag": {
+                    "description": "The highest lag of the samples the point holds",
+                    "type": "integer"
+                },
+                "produceRate": {
+                    "description": "The messages per second produced to the partitions since the previous point, missing likewise",
+                    "type": "number"
+                },
+                "timeToCatchUpSeconds": {
+                    "description": "How long the group takes to consume its lag when both rates hold. Missing when it doesn't catch up at them",
+                    "type": "number"
+                },
+                "timestamp": {
+                    "description": "When the offsets were sampled. For downsampled points, when the latest sample they hold was taken",
+                    "type": "string"
+                }
+            }
+        },
+        "model.CleanupPolicy": {
+            "type": "string",
+            "enum": [
+                "delete",
+                "compact",
+                "both",
+                "unknown"
+            ],
+            "x-enum-varnames": [
+                "CleanupPolicyDelete",
+                "CleanupPolicyCompact",
+                "CleanupPolicyBoth",
+                "CleanupPolicyUnknown"
+            ]
+        },
+        "model.CompressionType": {
+            "type": "string",
+            "enum": [
+                "gzip",
+                "zstd",
+                "snappy",
+                "lz4"
+            ],
+            "x-enum-varnames": [
+                "CompressionGzip",
+                "CompressionZstd",
+                "CompressionSnappy",
+                "CompressionLZ4"
+            ]
+        },
+        "model.ControlType": {
+            "type": "string",
+            "enum": [
+                "commit",
+                "abort"
+            ],
+            "x-enum-varnames": [
+                "ControlCommit",
+                "ControlAbort"
+            ]
+        },
+        "model.FieldSchema": {
+            "type": "object",
+            "required": [
+                "conflict",
+                "examples",
+                "nullability",
+                "path",
+                "presence",
+                "types"
+            ],
+            "properties": {
+                "conflict": {
+                    "description": "Whether values of more than one type other than null were observed. Integers and other numbers don't conflict",
+                    "type": "boolean"
+                },
+                "examples": {
+                    "description": "Distinct values that were observed, rendered as JSON. Objects, arrays and nulls aren't given as examples",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "nullability": {
+                    "description": "The share of the present values that were null",
+                    "type": "number"
+                },
+                "path": {
+                    "description": "The path of the field, where [*] stands for every element of an array",
+                    "type": "string"
+                },
+                "presence": {
+                    "description": "The share of the objects holding the field that it was present in",
+                    "type": "number"
+                },
+                "types": {
+                    "description": "How many values of each type were observed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "model.InferredSchema": {
+            "type": "object",
+            "required": [
+                "fields",
+                "jsonMessages",
+                "part",
+                "sampledMessages",
+                "topic"
+            ],
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FieldSchema"
+                    }
+                },
+                "jsonMessages": {
+                    "description": "The sampled messages whose payload was JSON, which the schema was inferred from",
+                    "type": "integer"
+                },
+                "part": {
+                    "description": "Whether the schema is of the keys or the values",
+                    "type": "string",
+                    "enum": [
+                        "key",
+                        "value"
+                    ]
+                },
+                "sampledMessages": {
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.JSONValue": {
+            "type": "object",
+            "properties": {
+                "arrayVal": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.JSONValue"
+                    }
+                },
+                "boolVal": {
+                    "type": "boolean"
+                },
+                "nullVal": {
+                    "type": "boolean"
+                },
+                "numberVal": {
+                    "type": "number"
+                },
+                "objectVal": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.JSONValue"
+                    }
+                },
+                "stringVal": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Message": {
+            "type": "object",
+            "required": [
+                "key",
+                "keyPayloadType",
+                "offset",
+                "partition",
+                "timestamp",
+                "topic",
+                "value",
+                "valuePayloadType"
+            ],
+            "properties": {
+                "controlType": {
+                    "description": "Set when the message is the commit or abort marker of a transaction, which has no key or value",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ControlType"
+                        }
+                    ]
+                },
+                "key": {
+                    "type": "string"
+                },
+                "keyCompression": {
+                    "description": "Application-level compression stripped from the key before decoding, outermost first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CompressionType"
+                    }
+                },
+                "keyJsonPayload": {
+                    "$ref": "#/definitions/model.JSONValue"
+                },
+                "keyPayloadType": {
+                    "$ref": "#/definitions/model.PayloadType"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "transactionState": {
+                    "description": "The state of the transaction the message was produced in, empty when it wasn't produced in one",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TransactionState"
+                        }
+                    ]
+                },
+                "value": {
+                    "type": "string"
+                },
+                "valueCompression": {
+                    "description": "Application-level compression stripped from the value before decoding, outermost first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CompressionType"
+                    }
+                },
+                "valueJsonPayload": {
+                    "$ref": "#/definitions/model.JSONValue"
+                },
+                "valuePayloadType": {
+                    "$ref": "#/definitions/model.PayloadType"
+                }
+            }
+        },
+        "model.PartitionCoverage": {
+            "type": "object",
+            "required": [
+                "complete",
+                "endOffset",
+                "highWatermark",
+                "lastStableOffset",
+                "nextOffset",
+                "partition",
+                "startOffset"
+            ],
+            "properties": {
+                "complete": {
+                    "description": "Whether reading reached the end of the range, or the high watermark when the range reaches past it. Under\nread_committed, reading stops at the last stable offset instead",
+                    "type": "boolean"
+                },
+                "endOffset": {
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Why reading stopped before the end of the range",
+                    "type": "string"
+                },
+                "highWatermark": {
+                    "description": "The high watermark of the partition when reading stopped",
+                    "type": "integer"
+                },
+                "lastStableOffset": {
+                    "description": "The offset below which every transaction is either committed or aborted",
+                    "type": "integer"
+                },
+                "nextOffset": {
+                    "description": "The offset reading stopped at. Every offset from StartOffset up to it was read, including the gaps that\ncompaction and transaction markers leave, which hold no messages",
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "startOffset": {
+                    "description": "The requested range as absolute, inclusive offsets. Both are -1 when the partition is empty",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PayloadType": {
+            "type": "string",
+            "enum": [
+                "json",
+                "string",
+                "consumerOffset",
+                "transactionState",
+                "null"
+            ],
+            "x-enum-varnames": [
+                "JSONPayload",
+                "StringPayload",
+                "ConsumerOffsetPayload",
+                "TransactionStatePayload",
+                "NullPayload"
+            ]
+        },
+        "model.RetentionMs": {
+            "type": "object",
+            "required": [
+                "indefinite",
+                "value"
+            ],
+            "properties": {
+                "indefinite": {
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SizeDistribution": {
+            "type": "object",
+            "required": [
+                "max",
+                "mean",
+                "p50",
+                "p95",
+                "p99"
+            ],
+            "properties": {
+                "max": {
+                    "type": "integer"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "p50": {
+                    "type": "integer"
+                },
+                "p95": {
                     "type": "integer"
                 },
-                "startOffset": {
-                    "description": "The start offset of the partition to fetch messages from\nNegative if the offset is to be from the latest offset, so for example -30 means 30 messages from the end\n-1 means the latest offset\nInclusive",
+                "p99": {
                     "type": "integer"
                 }
             }
         },
-        "handler.ErrorMessage": {
+        "model.TablePage": {
             "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.CleanupPolicy": {
-            "type": "string",
-            "enum": [
-                "delete",
-                "compact",
-                "both",
-                "unknown"
+            "required": [
+                "offset",
+                "rows",
+                "summary",
+                "total"
             ],
-            "x-enum-varnames": [
-                "CleanupPolicyDelete",
-                "CleanupPolicyCompact",
-                "CleanupPolicyBoth",
-                "CleanupPolicyUnknown"
-            ]
-        },
-        "model.JSONValue": {
-            "type": "object",
             "properties": {
-                "arrayVal": {
+                "offset": {
+                    "type": "integer"
+                },
+                "rows": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.JSONValue"
+                        "$ref": "#/definitions/model.TableRow"
                     }
                 },
-                "boolVal": {
-                    "type": "boolean"
-                },
-                "nullVal": {
-                    "type": "boolean"
-                },
-                "numberVal": {
-                    "type": "number"
-                },
-                "objectVal": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.JSONValue"
-                    }
+                "summary": {
+                    "$ref": "#/definitions/model.TableSummary"
                 },
-                "stringVal": {
-                    "type": "string"
+                "total": {
+                    "description": "The number of rows there are, tombstones included only when they were asked for",
+                    "type": "integer"
                 }
             }
         },
-        "model.Message": {
+        "model.TableRow": {
             "type": "object",
             "required": [
                 "key",
-                "keyPayloadType",
                 "offset",
                 "partition",
                 "timestamp",
-                "topic",
-                "value",
-                "valuePayloadType"
+                "tombstone"
             ],
             "properties": {
+                "base64": {
+                    "type": "boolean"
+                },
                 "key": {
+                    "description": "The key as text, or base64 encoded when it isn't valid UTF-8",
                     "type": "string"
                 },
-                "keyJsonPayload": {
-                    "$ref": "#/definitions/model.JSONValue"
-                },
-                "keyPayloadType": {
-                    "$ref": "#/definitions/model.PayloadType"
+                "message": {
+                    "description": "The newest message of the key. Missing when it is a tombstone or couldn't be decoded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Message"
+                        }
+                    ]
                 },
                 "offset": {
                     "type": "integer"
@@ -231,45 +2755,53 @@ const docTemplate = `{
                 "timestamp": {
                     "type": "string"
                 },
-                "topic": {
-                    "type": "string"
-                },
-                "value": {
-                    "type": "string"
-                },
-                "valueJsonPayload": {
-                    "$ref": "#/definitions/model.JSONValue"
-                },
-                "valuePayloadType": {
-                    "$ref": "#/definitions/model.PayloadType"
+                "tombstone": {
+                    "description": "Whether the newest message of the key has no value, deleting the key",
+                    "type": "boolean"
                 }
             }
         },
-        "model.PayloadType": {
-            "type": "string",
-            "enum": [
-                "json",
-                "string",
-                "consumerOffset"
-            ],
-            "x-enum-varnames": [
-                "JSONPayload",
-                "StringPayload",
-                "ConsumerOffsetPayload"
-            ]
-        },
-        "model.RetentionMs": {
+        "model.TableSummary": {
             "type": "object",
             "required": [
-                "indefinite",
-                "value"
+                "dirtyRatio",
+                "duplicateMessages",
+                "keys",
+                "nullKeys",
+                "scannedMessages",
+                "tombstones",
+                "topic"
             ],
             "properties": {
-                "indefinite": {
-                    "type": "boolean"
+                "dirtyRatio": {
+                    "description": "DuplicateMessages as a share of the scanned messages",
+                    "type": "number"
                 },
-                "value": {
+                "duplicateMessages": {
+                    "description": "Messages superseded by a newer message with the same key that compaction hasn't removed yet",
+                    "type": "integer"
+                },
+                "keys": {
+                    "description": "The keys whose newest message has a value",
                     "type": "integer"
+                },
+                "minCleanableDirtyRatio": {
+                    "description": "The dirty ratio from which the log cleaner compacts the topic. Missing when it couldn't be read",
+                    "type": "number"
+                },
+                "nullKeys": {
+                    "description": "Messages without a key, which compaction doesn't keep track of",
+                    "type": "integer"
+                },
+                "scannedMessages": {
+                    "type": "integer"
+                },
+                "tombstones": {
+                    "description": "The keys whose newest message is a tombstone, which compaction removes along with the key",
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
                 }
             }
         },
@@ -279,6 +2811,7 @@ const docTemplate = `{
                 "additionalConfigs",
                 "cleanupPolicy",
                 "isInternal",
+                "kind",
                 "name",
                 "numPartitions",
                 "replicationFactor"
@@ -296,6 +2829,9 @@ const docTemplate = `{
                 "isInternal": {
                     "type": "boolean"
                 },
+                "kind": {
+                    "$ref": "#/definitions/model.TopicKind"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -312,6 +2848,268 @@ const docTemplate = `{
                     "$ref": "#/definitions/model.RetentionMs"
                 }
             }
+        },
+        "model.TopicKind": {
+            "type": "string",
+            "enum": [
+                "standard",
+                "connect-internal",
+                "streams-changelog",
+                "streams-repartition"
+            ],
+            "x-enum-varnames": [
+                "TopicKindStandard",
+                "TopicKindConnectInternal",
+                "TopicKindStreamsChangelog",
+                "TopicKindStreamsRepartition"
+            ]
+        },
+        "model.TopicMessageSizes": {
+            "type": "object",
+            "required": [
+                "atRisk",
+                "headers",
+                "key",
+                "record",
+                "sampledMessages",
+                "topic",
+                "topicOverride",
+                "value"
+            ],
+            "properties": {
+                "atRisk": {
+                    "description": "Whether the largest sampled record reaches the threshold share of the limit",
+                    "type": "boolean"
+                },
+                "brokerMaxMessageBytes": {
+                    "description": "The message.max.bytes of the brokers. Missing when it couldn't be read",
+                    "type": "integer"
+                },
+                "headers": {
+                    "description": "The keys and values of every header of a record together",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SizeDistribution"
+                        }
+                    ]
+                },
+                "key": {
+                    "$ref": "#/definitions/model.SizeDistribution"
+                },
+                "maxMessageBytes": {
+                    "description": "The largest record batch the topic accepts, from its max.message.bytes or else the limit of the brokers.\nMissing when neither could be read",
+                    "type": "integer"
+                },
+                "record": {
+                    "description": "The key, value and headers of a record together",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SizeDistribution"
+                        }
+                    ]
+                },
+                "sampledMessages": {
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "topicOverride": {
+                    "description": "Whether the topic overrides the limit of the brokers",
+                    "type": "boolean"
+                },
+                "utilization": {
+                    "description": "The largest sampled record as a share of MaxMessageBytes. Missing when the limit is",
+                    "type": "number"
+                },
+                "value": {
+                    "$ref": "#/definitions/model.SizeDistribution"
+                }
+            }
+        },
+        "model.TopicMessages": {
+            "type": "object",
+            "required": [
+                "messages",
+                "partitions"
+            ],
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Message"
+                    }
+                },
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PartitionCoverage"
+                    }
+                }
+            }
+        },
+        "model.TransactionState": {
+            "type": "string",
+            "enum": [
+                "committed",
+                "aborted",
+                "open"
+            ],
+            "x-enum-varnames": [
+                "TransactionCommitted",
+                "TransactionAborted",
+                "TransactionOpen"
+            ]
+        },
+        "stats.PartitionStats": {
+            "type": "object",
+            "required": [
+                "newestOffset",
+                "oldestOffset",
+                "partition",
+                "rates",
+                "replicatedSizeBytes",
+                "sizeBytes"
+            ],
+            "properties": {
+                "newestOffset": {
+                    "description": "The high watermark",
+                    "type": "integer"
+                },
+                "oldestOffset": {
+                    "type": "integer"
+                },
+                "oldestTimestamp": {
+                    "description": "The timestamp of the oldest record. Only read for topics whose records are deleted by time",
+                    "type": "string"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.Rate"
+                    }
+                },
+                "replicatedSizeBytes": {
+                    "description": "The size of every replica. -1 when it couldn't be read",
+                    "type": "integer"
+                },
+                "sizeBytes": {
+                    "description": "The size of the largest replica. -1 when it couldn't be read",
+                    "type": "integer"
+                }
+            }
+        },
+        "stats.Rate": {
+            "type": "object",
+            "required": [
+                "bytesPerSecond",
+                "coveredSeconds",
+                "messagesPerSecond",
+                "windowSeconds"
+            ],
+            "properties": {
+                "bytesPerSecond": {
+                    "description": "Measured from the growth of the partitions on disk. When retention or compaction shrank a partition within\nthe window, it is estimated from the messages instead",
+                    "type": "number"
+                },
+                "coveredSeconds": {
+                    "description": "The part of the window the samples cover, which is less than the window until the sampler ran long enough",
+                    "type": "number"
+                },
+                "messagesPerSecond": {
+                    "type": "number"
+                },
+                "windowSeconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stats.RetentionStats": {
+            "type": "object",
+            "required": [
+                "cleanupPolicy"
+            ],
+            "properties": {
+                "cleanupPolicy": {
+                    "$ref": "#/definitions/model.CleanupPolicy"
+                },
+                "oldestRecordAgeMs": {
+                    "description": "The age of the oldest record of the topic. Only read for topics whose records are deleted by time",
+                    "type": "integer"
+                },
+                "retentionBytes": {
+                    "description": "The limit on each partition. Missing when there is none",
+                    "type": "integer"
+                },
+                "retentionMs": {
+                    "description": "Missing when records are kept indefinitely",
+                    "type": "integer"
+                },
+                "sizeUtilization": {
+                    "description": "The largest share of RetentionBytes that a partition uses, since the limit applies to each partition",
+                    "type": "number"
+                },
+                "timeUtilization": {
+                    "description": "The age of the oldest record as a share of RetentionMs. It goes past 1 since cleanup deletes whole segments,\nonce their newest record is past retention",
+                    "type": "number"
+                }
+            }
+        },
+        "stats.TopicStats": {
+            "type": "object",
+            "required": [
+                "offsets",
+                "partitions",
+                "rates",
+                "replicatedSizeBytes",
+                "retention",
+                "sizeBytes",
+                "topic"
+            ],
+            "properties": {
+                "offsets": {
+                    "description": "The number of offsets between the oldest and the newest of every partition, which compaction and\ntransaction markers leave without records",
+                    "type": "integer"
+                },
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.PartitionStats"
+                    }
+                },
+                "rates": {
+                    "description": "The throughput over each window, empty until the sampler has taken two samples",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.Rate"
+                    }
+                },
+                "replicatedSizeBytes": {
+                    "description": "The size of every replica of every partition. -1 when the size of a partition couldn't be read",
+                    "type": "integer"
+                },
+                "retention": {
+                    "$ref": "#/definitions/stats.RetentionStats"
+                },
+                "sizeBytes": {
+                    "description": "The size of the largest replica of every partition. -1 when the size of a partition couldn't be read",
+                    "type": "integer"
+                },
+                "sizeSkew": {
+                    "description": "How many times the mean size of the partitions the largest one is",
+                    "type": "number"
+                },
+                "throughputSkew": {
+                    "description": "How many times the mean throughput of the partitions the busiest one received over the longest window, where 1\nmeans the partitions are even. Missing when the topic received no messages",
+                    "type": "number"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,57 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+)
+
+type State string
+
+const (
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+)
+
+// Alert is a rule that fired for a group, topic or partition.
+type Alert struct {
+	// Stays the same for as long as the rule fires for the same subject
+	ID   string `json:"id" validate:"required"`
+	Rule string `json:"rule" validate:"required"`
+	// One of lag_above, lag_growing, consumer_stalled, under_replicated, offline_partition and topic_idle
+	Type     RuleType `json:"type" validate:"required"`
+	Severity string   `json:"severity,omitempty"`
+	// Only set for lag rules
+	Group string `json:"group,omitempty"`
+	Topic string `json:"topic" validate:"required"`
+	// Only set for partition rules
+	Partition *int32 `json:"partition,omitempty"`
+	State     State  `json:"state" validate:"required" enums:"firing,resolved"`
+	Message   string `json:"message" validate:"required"`
+	// What the rule measured when the alert last held, such as the lag or the seconds without messages
+	Value float64 `json:"value" validate:"required"`
+	// When the condition started to hold, which is For before the alert fired
+	Since      time.Time  `json:"since" validate:"required"`
+	FiredAt    time.Time  `json:"firedAt" validate:"required"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	// Whether a silence matches the alert, which keeps its notifications from being sent
+	Silenced bool `json:"silenced" validate:"required"`
+}
+
+// condition is a rule holding for a subject at the time the rules were evaluated.
+type condition struct {
+	rule      Rule
+	group     string
+	topic     string
+	partition *int32
+	message   string
+	value     float64
+	since     time.Time
+}
+
+func (c condition) id() string {
+	id := c.rule.Name + "/" + c.group + "/" + c.topic
+	if c.partition != nil {
+		id += fmt.Sprintf("/%d", *c.partition)
+	}
+	return id
+}
@@ -0,0 +1,209 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/lag"
+	"time"
+)
+
+// The number of sample intervals after which the lag of a group that wasn't sampled is no longer current, such as
+// when the group was deleted
+const staleLagIntervals = 3
+
+// getLagConditions tells from the lag history of every group and topic whether the lag rules hold for it.
+func (e *Engine) getLagConditions(rules []Rule, now time.Time) ([]condition, error) {
+	interval := e.lagCollector.Interval()
+	longest := time.Duration(0)
+	for _, rule := range rules {
+		longest = max(longest, rule.For)
+	}
+	// A sample from before the longest duration is needed to tell that a condition held for all of it
+	samples, err := e.lagCollector.GetSamples(now.Add(-longest - 2*interval))
+	if err != nil {
+		return nil, err
+	}
+	var conditions []condition
+	for group, topicSamples := range samples {
+		for topic, points := range topicSamples {
+			latest := points[len(points)-1]
+			if now.Sub(latest.Time) > staleLagIntervals*interval {
+				continue
+			}
+			for _, rule := range rules {
+				if !rule.matchesGroup(group) || !rule.matchesTopic(topic, kafka.IsInternalTopic(topic)) {
+					continue
+				}
+				lagCondition, ok := getLagCondition(rule, points)
+				if ok {
+					lagCondition.rule = rule
+					lagCondition.group = group
+					lagCondition.topic = topic
+					conditions = append(conditions, lagCondition)
+				}
+			}
+		}
+	}
+	return conditions, nil
+}
+
+// getLagCondition tells whether rule holds at the latest of points, and since when it held without a break.
+func getLagCondition(rule Rule, points []lag.Point) (condition, bool) {
+	latest := points[len(points)-1]
+	// The earliest point of the run up to the latest one that every point of satisfies the rule
+	start := len(points) - 1
+	switch rule.Type {
+	case RuleLagAbove:
+		if latest.Lag <= rule.Threshold {
+			return condition{}, false
+		}
+		for start > 0 && points[start-1].Lag > rule.Threshold {
+			start--
+		}
+		return condition{
+			message: fmt.Sprintf("lag of %d is above %d", latest.Lag, rule.Threshold),
+			value:   float64(latest.Lag),
+			since:   points[start].Time,
+		}, true
+
+	case RuleLagGrowing:
+		for start > 0 && sameCoverage(points[start-1], points[start]) && points[start-1].Lag < points[start].Lag {
+			start--
+		}
+		if start == len(points)-1 {
+			return condition{}, false
+		}
+		return condition{
+			message: fmt.Sprintf(
+				"lag grew from %d to %d since %s",
+				points[start].Lag,
+				latest.Lag,
+				points[start].Time.Format(time.RFC3339),
+			),
+			value: float64(latest.Lag),
+			since: points[start].Time,
+		}, true
+
+	case RuleConsumerStalled:
+		for start > 0 && sameCoverage(points[start-1], latest) && points[start-1].Committed == latest.Committed {
+			start--
+		}
+		produced := latest.HighWatermark - points[start].HighWatermark
+		if produced <= 0 || latest.Lag == 0 {
+			return condition{}, false
+		}
+		return condition{
+			message: fmt.Sprintf(
+				"committed offsets haven't moved since %s while %d messages were produced",
+				points[start].Time.Format(time.RFC3339),
+				produced,
+			),
+			value: float64(latest.Lag),
+			since: points[start].Time,
+		}, true
+	}
+	return condition{}, false
+}
+
+// sameCoverage tells whether the sums of two points cover the same partitions.
+func sameCoverage(a lag.Point, b lag.Point) bool {
+	return a.Partitions == b.Partitions
+}
+
+// getPartitionConditions tells from the replicas of every partition whether the partition rules hold for it. How
+// long they held is tracked from the evaluations the engine made.
+func (e *Engine) getPartitionConditions(ctx context.Context, rules []Rule, now time.Time) ([]condition, error) {
+	replicas, err := e.kafkaService.GetPartitionReplicas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var conditions []condition
+	for topic, partitionReplicas := range replicas {
+		for _, rule := range rules {
+			if !rule.matchesTopic(topic, kafka.IsInternalTopic(topic)) {
+				continue
+			}
+			for partition, partitionReplica := range partitionReplicas {
+				partitionCondition := condition{rule: rule, topic: topic, partition: &partition}
+				switch {
+				case rule.Type == RuleOfflinePartition && partitionReplica.Leader < 0:
+					partitionCondition.message = fmt.Sprintf("partition %d of %s has no leader", partition, topic)
+					partitionCondition.value = float64(len(partitionReplica.Offline))
+				case rule.Type == RuleUnderReplicated && len(partitionReplica.InSync) < len(partitionReplica.Replicas):
+					missing := len(partitionReplica.Replicas) - len(partitionReplica.InSync)
+					partitionCondition.message = fmt.Sprintf(
+						"partition %d of %s has %d of %d replicas in sync",
+						partition,
+						topic,
+						len(partitionReplica.InSync),
+						len(partitionReplica.Replicas),
+					)
+					partitionCondition.value = float64(missing)
+				default:
+					continue
+				}
+				conditions = append(conditions, partitionCondition)
+			}
+		}
+	}
+	e.trackPending(conditions, now)
+	return conditions, nil
+}
+
+// trackPending sets when every condition started to hold, from the first evaluation that found it, and forgets the
+// conditions that no longer hold.
+func (e *Engine) trackPending(conditions []condition, now time.Time) {
+	pending := make(map[string]time.Time, len(conditions))
+	for i, pendingCondition := range conditions {
+		id := pendingCondition.id()
+		since, ok := e.pending[id]
+		if !ok {
+			since = now
+		}
+		pending[id] = since
+		conditions[i].since = since
+	}
+	e.pending = pending
+}
+
+// getTopicConditions tells from the newest offsets of every topic whether the topic rules hold for it. Topics are
+// taken to have received messages when the engine first saw them.
+func (e *Engine) getTopicConditions(ctx context.Context, rules []Rule, now time.Time) ([]condition, error) {
+	offsets, err := e.kafkaService.GetPartitionOffsets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	activity := make(map[string]topicActivity, len(offsets))
+	for topic, partitionOffsets := range offsets {
+		newest := int64(0)
+		for _, partitionOffset := range partitionOffsets {
+			newest += partitionOffset.Newest
+		}
+		topicActivity, ok := e.topicActivity[topic]
+		if !ok || topicActivity.newest != newest {
+			topicActivity.newest = newest
+			topicActivity.changedAt = now
+		}
+		activity[topic] = topicActivity
+	}
+	e.topicActivity = activity
+
+	var conditions []condition
+	for topic, topicActivity := range activity {
+		for _, rule := range rules {
+			if !rule.matchesTopic(topic, kafka.IsInternalTopic(topic)) || topicActivity.changedAt.Equal(now) {
+				continue
+			}
+			idle := now.Sub(topicActivity.changedAt)
+			conditions = append(conditions, condition{
+				rule:    rule,
+				topic:   topic,
+				message: fmt.Sprintf("%s received no messages for %s", topic, idle.Round(time.Second)),
+				value:   idle.Seconds(),
+				since:   topicActivity.changedAt,
+			})
+		}
+	}
+	return conditions, nil
+}
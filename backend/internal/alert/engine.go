@@ -0,0 +1,346 @@
+package alert
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/lag"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"slices"
+	"sync"
+	"time"
+)
+
+// How long resolved alerts are still listed
+const resolvedRetention = 24 * time.Hour
+
+var ErrInvalidSilence = errors.New("invalid silence")
+
+type Config struct {
+	// How often the rules are evaluated
+	Interval time.Duration
+	Rules    []Rule
+}
+
+func DefaultConfig() Config {
+	return Config{Interval: time.Minute}
+}
+
+// Engine evaluates the rules against the state of the cluster in the background, and notifies the sinks of the
+// alerts that fire and resolve.
+type Engine struct {
+	kafkaService *kafka.KafkaService
+	lagCollector *lag.Collector
+	config       Config
+	sinks        map[string]Sink
+	silenceStore SilenceStore
+	// The alerts that fire, and those that resolved within resolvedRetention, by ID
+	alerts   map[string]Alert
+	silences []Silence
+	mutex    sync.RWMutex
+	// When the partition conditions started to hold, by alert ID. Only used by Run
+	pending map[string]time.Time
+	// When the newest offsets of every topic last changed. Only used by Run
+	topicActivity map[string]topicActivity
+	logger        *zap.Logger
+}
+
+type topicActivity struct {
+	newest    int64
+	changedAt time.Time
+}
+
+// NewEngine creates an engine that evaluates nothing until it is run. It fails when a rule is invalid.
+func NewEngine(
+	kafkaService *kafka.KafkaService,
+	lagCollector *lag.Collector,
+	config Config,
+	sinks map[string]Sink,
+	silenceStore SilenceStore,
+	logger *zap.Logger,
+) (*Engine, error) {
+	names := make(map[string]struct{}, len(config.Rules))
+	for _, rule := range config.Rules {
+		if err := rule.validate(sinks); err != nil {
+			return nil, err
+		}
+		if _, ok := names[rule.Name]; ok {
+			return nil, fmt.Errorf("rule %s is configured more than once", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+	}
+	silences, err := silenceStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{
+		kafkaService:  kafkaService,
+		lagCollector:  lagCollector,
+		config:        config,
+		sinks:         sinks,
+		silenceStore:  silenceStore,
+		alerts:        make(map[string]Alert),
+		silences:      silences,
+		pending:       make(map[string]time.Time),
+		topicActivity: make(map[string]topicActivity),
+		logger:        logger,
+	}, nil
+}
+
+// Run evaluates the rules every interval until ctx is done.
+func (e *Engine) Run(ctx context.Context) {
+	if len(e.config.Rules) == 0 {
+		return
+	}
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+	for {
+		e.evaluate(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Engine) evaluate(ctx context.Context, now time.Time) {
+	conditions, evaluated := e.getConditions(ctx, now)
+	if ctx.Err() != nil {
+		return
+	}
+
+	e.mutex.Lock()
+	var notifications []Alert
+	holding := make(map[string]struct{}, len(conditions))
+	for _, condition := range conditions {
+		if now.Sub(condition.since) < condition.rule.For {
+			continue
+		}
+		id := condition.id()
+		holding[id] = struct{}{}
+		alert, ok := e.alerts[id]
+		firing := ok && alert.State == StateFiring
+		if !firing {
+			alert = Alert{
+				ID:        id,
+				Rule:      condition.rule.Name,
+				Type:      condition.rule.Type,
+				Severity:  condition.rule.Severity,
+				Group:     condition.group,
+				Topic:     condition.topic,
+				Partition: condition.partition,
+				State:     StateFiring,
+				FiredAt:   now,
+			}
+		}
+		alert.Message = condition.message
+		alert.Value = condition.value
+		alert.Since = condition.since
+		alert.Silenced = e.isSilenced(alert, now)
+		e.alerts[id] = alert
+		if !firing {
+			notifications = append(notifications, alert)
+		}
+	}
+	for id, alert := range e.alerts {
+		if alert.State == StateResolved {
+			if now.Sub(*alert.ResolvedAt) > resolvedRetention {
+				delete(e.alerts, id)
+			}
+			continue
+		}
+		// Alerts of rules that couldn't be evaluated are left firing
+		if _, ok := holding[id]; ok || !evaluated[alert.Rule] {
+			continue
+		}
+		resolvedAt := now
+		alert.State = StateResolved
+		alert.ResolvedAt = &resolvedAt
+		alert.Silenced = e.isSilenced(alert, now)
+		e.alerts[id] = alert
+		notifications = append(notifications, alert)
+	}
+	e.mutex.Unlock()
+
+	for _, alert := range notifications {
+		e.notify(ctx, alert)
+	}
+}
+
+// getConditions returns the conditions that hold for every rule, and which rules could be evaluated.
+func (e *Engine) getConditions(ctx context.Context, now time.Time) ([]condition, map[string]bool) {
+	evaluated := make(map[string]bool, len(e.config.Rules))
+	var conditions []condition
+	var lagRules, partitionRules, topicRules []Rule
+	for _, rule := range e.config.Rules {
+		switch rule.Type {
+		case RuleLagAbove, RuleLagGrowing, RuleConsumerStalled:
+			lagRules = append(lagRules, rule)
+		case RuleUnderReplicated, RuleOfflinePartition:
+			partitionRules = append(partitionRules, rule)
+		case RuleTopicIdle:
+			topicRules = append(topicRules, rule)
+		}
+	}
+
+	if len(lagRules) > 0 {
+		lagConditions, err := e.getLagConditions(lagRules, now)
+		if err != nil {
+			e.logger.Warn("failed to evaluate lag rules", zap.Error(err))
+		} else {
+			conditions = append(conditions, lagConditions...)
+			markEvaluated(evaluated, lagRules)
+		}
+	}
+	if len(partitionRules) > 0 {
+		partitionConditions, err := e.getPartitionConditions(ctx, partitionRules, now)
+		if err != nil {
+			e.logger.Warn("failed to evaluate partition rules", zap.Error(err))
+		} else {
+			conditions = append(conditions, partitionConditions...)
+			markEvaluated(evaluated, partitionRules)
+		}
+	}
+	if len(topicRules) > 0 {
+		topicConditions, err := e.getTopicConditions(ctx, topicRules, now)
+		if err != nil {
+			e.logger.Warn("failed to evaluate topic rules", zap.Error(err))
+		} else {
+			conditions = append(conditions, topicConditions...)
+			markEvaluated(evaluated, topicRules)
+		}
+	}
+	return conditions, evaluated
+}
+
+func markEvaluated(evaluated map[string]bool, rules []Rule) {
+	for _, rule := range rules {
+		evaluated[rule.Name] = true
+	}
+}
+
+func (e *Engine) notify(ctx context.Context, alert Alert) {
+	if alert.Silenced {
+		return
+	}
+	sinks := e.config.Rules[slices.IndexFunc(e.config.Rules, func(rule Rule) bool {
+		return rule.Name == alert.Rule
+	})].Sinks
+	if len(sinks) == 0 {
+		for name := range e.sinks {
+			sinks = append(sinks, name)
+		}
+	}
+	for _, name := range sinks {
+		sendCtx, cancel := context.WithTimeout(ctx, sinkTimeout)
+		err := e.sinks[name].Send(sendCtx, alert)
+		cancel()
+		if err != nil {
+			e.logger.Warn(
+				"failed to send alert",
+				zap.String("sink", name),
+				zap.String("id", alert.ID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Alerts returns the alerts that fire and those that resolved recently, or only those in state when it isn't empty,
+// most recently fired first.
+func (e *Engine) Alerts(state State) []Alert {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, alert := range e.alerts {
+		if state == "" || alert.State == state {
+			alerts = append(alerts, alert)
+		}
+	}
+	slices.SortFunc(alerts, func(a, b Alert) int {
+		return cmp.Or(b.FiredAt.Compare(a.FiredAt), cmp.Compare(a.ID, b.ID))
+	})
+	return alerts
+}
+
+// Silences returns the silences that haven't ended, ending soonest first.
+func (e *Engine) Silences() []Silence {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	now := time.Now()
+	silences := make([]Silence, 0, len(e.silences))
+	for _, silence := range e.silences {
+		if silence.EndsAt.After(now) {
+			silences = append(silences, silence)
+		}
+	}
+	slices.SortFunc(silences, func(a, b Silence) int {
+		return a.EndsAt.Compare(b.EndsAt)
+	})
+	return silences
+}
+
+// AddSilence starts silence now, and marks the alerts it matches as silenced.
+func (e *Engine) AddSilence(silence Silence) (Silence, error) {
+	now := time.Now()
+	if !silence.EndsAt.After(now) {
+		return Silence{}, fmt.Errorf("%w: it has to end in the future", ErrInvalidSilence)
+	}
+	if err := silence.validate(); err != nil {
+		return Silence{}, fmt.Errorf("%w: %w", ErrInvalidSilence, err)
+	}
+	silence.ID = uuid.NewString()
+	silence.StartsAt = now
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	silences := make([]Silence, 0, len(e.silences)+1)
+	for _, existing := range e.silences {
+		if existing.EndsAt.After(now) {
+			silences = append(silences, existing)
+		}
+	}
+	silences = append(silences, silence)
+	if err := e.silenceStore.Save(silences); err != nil {
+		return Silence{}, err
+	}
+	e.silences = silences
+	e.updateSilenced(now)
+	return silence, nil
+}
+
+// RemoveSilence ends the silence with id. It returns false when there is no such silence.
+func (e *Engine) RemoveSilence(id string) (bool, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	index := slices.IndexFunc(e.silences, func(silence Silence) bool {
+		return silence.ID == id
+	})
+	if index < 0 {
+		return false, nil
+	}
+	silences := slices.Delete(slices.Clone(e.silences), index, index+1)
+	if err := e.silenceStore.Save(silences); err != nil {
+		return false, err
+	}
+	e.silences = silences
+	e.updateSilenced(time.Now())
+	return true, nil
+}
+
+func (e *Engine) isSilenced(alert Alert, now time.Time) bool {
+	return slices.ContainsFunc(e.silences, func(silence Silence) bool {
+		return silence.matches(alert, now)
+	})
+}
+
+func (e *Engine) updateSilenced(now time.Time) {
+	for id, alert := range e.alerts {
+		alert.Silenced = e.isSilenced(alert, now)
+		e.alerts[id] = alert
+	}
+}
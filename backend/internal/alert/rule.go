@@ -0,0 +1,94 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+)
+
+type RuleType string
+
+const (
+	// The lag of a group on a topic is above the threshold
+	RuleLagAbove RuleType = "lag_above"
+	// The lag of a group on a topic grew at every sample
+	RuleLagGrowing RuleType = "lag_growing"
+	// The committed offsets of a group on a topic didn't move while messages were produced to it
+	RuleConsumerStalled RuleType = "consumer_stalled"
+	// A partition has replicas that are out of sync with its leader
+	RuleUnderReplicated RuleType = "under_replicated"
+	// A partition has no leader
+	RuleOfflinePartition RuleType = "offline_partition"
+	// A topic received no messages
+	RuleTopicIdle RuleType = "topic_idle"
+)
+
+// Rule is a condition on the state of the cluster that fires an alert for every group, topic or partition it holds
+// for.
+type Rule struct {
+	// Identifies the rule in alerts and silences
+	Name string
+	Type RuleType
+	// Globs that the groups a lag rule applies to must match. Empty matches every group
+	Groups []string
+	// Globs that the topics the rule applies to must match. Empty matches every topic but internal ones
+	Topics []string
+	// The lag above which lag_above fires
+	Threshold int64
+	// How long the condition has to hold before the alert fires
+	For      time.Duration
+	Severity string
+	// The names of the sinks that are notified, or every sink when empty
+	Sinks []string
+}
+
+func (r Rule) validate(sinks map[string]Sink) error {
+	if r.Name == "" {
+		return errors.New("every rule requires a name")
+	}
+	switch r.Type {
+	case RuleLagAbove, RuleLagGrowing, RuleConsumerStalled, RuleUnderReplicated, RuleOfflinePartition, RuleTopicIdle:
+	default:
+		return fmt.Errorf("rule %s has unknown type %q", r.Name, r.Type)
+	}
+	if r.For < 0 || r.Threshold < 0 {
+		return fmt.Errorf("rule %s must not have a negative threshold or duration", r.Name)
+	}
+	for _, glob := range slices.Concat(r.Groups, r.Topics) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("rule %s has invalid glob %q: %w", r.Name, glob, err)
+		}
+	}
+	for _, sink := range r.Sinks {
+		if _, ok := sinks[sink]; !ok {
+			return fmt.Errorf("rule %s notifies unknown sink %s", r.Name, sink)
+		}
+	}
+	return nil
+}
+
+func (r Rule) isLagRule() bool {
+	return r.Type == RuleLagAbove || r.Type == RuleLagGrowing || r.Type == RuleConsumerStalled
+}
+
+func (r Rule) matchesGroup(group string) bool {
+	return len(r.Groups) == 0 || matchesAny(r.Groups, group)
+}
+
+func (r Rule) matchesTopic(topic string, internal bool) bool {
+	if len(r.Topics) == 0 {
+		return !internal
+	}
+	return matchesAny(r.Topics, topic)
+}
+
+func matchesAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
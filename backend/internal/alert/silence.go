@@ -0,0 +1,108 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Silence keeps the notifications of the alerts it matches from being sent until it ends.
+type Silence struct {
+	ID string `json:"id" validate:"required"`
+	// Globs that the rule, group and topic of an alert must match. Empty matches every alert
+	Rule     string    `json:"rule,omitempty"`
+	Group    string    `json:"group,omitempty"`
+	Topic    string    `json:"topic,omitempty"`
+	Comment  string    `json:"comment,omitempty"`
+	StartsAt time.Time `json:"startsAt" validate:"required"`
+	EndsAt   time.Time `json:"endsAt" validate:"required"`
+}
+
+func (s Silence) validate() error {
+	for _, glob := range []string{s.Rule, s.Group, s.Topic} {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+func (s Silence) matches(alert Alert, now time.Time) bool {
+	if now.After(s.EndsAt) {
+		return false
+	}
+	for _, match := range []struct {
+		glob  string
+		value string
+	}{
+		{glob: s.Rule, value: alert.Rule},
+		{glob: s.Group, value: alert.Group},
+		{glob: s.Topic, value: alert.Topic},
+	} {
+		if match.glob != "" && !matchesAny([]string{match.glob}, match.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// SilenceStore persists silences, so that they survive restarts.
+type SilenceStore interface {
+	Load() ([]Silence, error)
+	Save(silences []Silence) error
+}
+
+// FileSilenceStore keeps the silences in a single JSON file, which is replaced atomically on every save.
+type FileSilenceStore struct {
+	path string
+}
+
+func NewFileSilenceStore(path string) *FileSilenceStore {
+	return &FileSilenceStore{path: path}
+}
+
+// Load returns the saved silences, or none if nothing was saved yet.
+func (f *FileSilenceStore) Load() ([]Silence, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read silences: %w", err)
+	}
+	var silences []Silence
+	if err := json.Unmarshal(data, &silences); err != nil {
+		return nil, fmt.Errorf("failed to parse silences: %w", err)
+	}
+	return silences, nil
+}
+
+func (f *FileSilenceStore) Save(silences []Silence) error {
+	data, err := json.Marshal(silences)
+	if err != nil {
+		return fmt.Errorf("failed to encode silences: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create silences directory: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create silences file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write silences: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write silences: %w", err)
+	}
+	if err := os.Rename(temp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace silences: %w", err)
+	}
+	return nil
+}
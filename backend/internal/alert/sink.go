@@ -0,0 +1,144 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type SinkType string
+
+const (
+	// Posts every alert as JSON
+	SinkWebhook SinkType = "webhook"
+	// Posts every alert as a message to a Slack incoming webhook, or anything that accepts the same payload
+	SinkSlack SinkType = "slack"
+	// Logs every alert
+	SinkLog SinkType = "log"
+)
+
+// How long a sink has to accept a notification
+const sinkTimeout = 10 * time.Second
+
+// Sink is where the alerts that fire or resolve are sent.
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+}
+
+type SinkConfig struct {
+	// Identifies the sink in rules
+	Name string
+	Type SinkType
+	// Where webhooks are posted
+	URL string
+	// Sent along with webhooks, e.g. to authenticate
+	Headers map[string]string
+}
+
+// NewSinks creates the sinks configured by name.
+func NewSinks(configs []SinkConfig, logger *zap.Logger) (map[string]Sink, error) {
+	sinks := make(map[string]Sink, len(configs))
+	client := &http.Client{Timeout: sinkTimeout}
+	for _, config := range configs {
+		if config.Name == "" {
+			return nil, errors.New("every sink requires a name")
+		}
+		if _, ok := sinks[config.Name]; ok {
+			return nil, fmt.Errorf("sink %s is configured more than once", config.Name)
+		}
+		if (config.Type == SinkWebhook || config.Type == SinkSlack) && config.URL == "" {
+			return nil, fmt.Errorf("sink %s requires a url", config.Name)
+		}
+		switch config.Type {
+		case SinkWebhook:
+			sinks[config.Name] = &WebhookSink{url: config.URL, headers: config.Headers, client: client}
+		case SinkSlack:
+			sinks[config.Name] = &SlackSink{url: config.URL, headers: config.Headers, client: client}
+		case SinkLog:
+			sinks[config.Name] = &LogSink{logger: logger}
+		default:
+			return nil, fmt.Errorf("sink %s has unknown type %q", config.Name, config.Type)
+		}
+	}
+	return sinks, nil
+}
+
+// WebhookSink posts alerts as JSON.
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (s *WebhookSink) Send(ctx context.Context, alert Alert) error {
+	return post(ctx, s.client, s.url, s.headers, alert)
+}
+
+// SlackSink posts alerts as messages to a Slack incoming webhook.
+type SlackSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (s *SlackSink) Send(ctx context.Context, alert Alert) error {
+	return post(ctx, s.client, s.url, s.headers, struct {
+		Text string `json:"text"`
+	}{Text: slackText(alert)})
+}
+
+func slackText(alert Alert) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[%s] *%s*", strings.ToUpper(string(alert.State)), alert.Rule)
+	if alert.Severity != "" {
+		fmt.Fprintf(&text, " (%s)", alert.Severity)
+	}
+	fmt.Fprintf(&text, ": %s", alert.Message)
+	return text.String()
+}
+
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("failed to post notification: webhook responded with %s", response.Status)
+	}
+	return nil
+}
+
+// LogSink logs alerts, for when no other sink is at hand.
+type LogSink struct {
+	logger *zap.Logger
+}
+
+func (s *LogSink) Send(_ context.Context, alert Alert) error {
+	s.logger.Warn(
+		"alert "+string(alert.State),
+		zap.String("rule", alert.Rule),
+		zap.String("id", alert.ID),
+		zap.String("severity", alert.Severity),
+		zap.String("message", alert.Message),
+	)
+	return nil
+}
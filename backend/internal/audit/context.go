@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"slices"
+	"sync"
+)
+
+// tracker collects the resources a request turned out to act on, beyond those its parameters name, such as the topic
+// of a scanned table that is looked up by ID, and who made the request once it has been authenticated.
+type tracker struct {
+	mu        sync.Mutex
+	resources []auth.Resource
+	identity  *auth.Identity
+}
+
+type trackerKey struct{}
+
+// Track returns a context that collects the resources added with AddResource and the identity set with SetIdentity.
+func Track(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trackerKey{}, &tracker{})
+}
+
+// AddResource notes that the request of ctx acts on resource. It does nothing when ctx isn't tracked.
+func AddResource(ctx context.Context, resource auth.Resource) {
+	tracked, ok := ctx.Value(trackerKey{}).(*tracker)
+	if !ok {
+		return
+	}
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+	tracked.resources = appendResource(tracked.resources, resource)
+}
+
+// TrackedResources returns the resources added to ctx.
+func TrackedResources(ctx context.Context) []auth.Resource {
+	tracked, ok := ctx.Value(trackerKey{}).(*tracker)
+	if !ok {
+		return nil
+	}
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+	return slices.Clone(tracked.resources)
+}
+
+// SetIdentity notes who made the request of ctx. It does nothing when ctx isn't tracked.
+func SetIdentity(ctx context.Context, identity *auth.Identity) {
+	tracked, ok := ctx.Value(trackerKey{}).(*tracker)
+	if !ok {
+		return
+	}
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+	tracked.identity = identity
+}
+
+// TrackedIdentity returns the identity set on ctx, or nil when the request wasn't authenticated.
+func TrackedIdentity(ctx context.Context) *auth.Identity {
+	tracked, ok := ctx.Value(trackerKey{}).(*tracker)
+	if !ok {
+		return nil
+	}
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+	return tracked.identity
+}
+
+// MergeResources appends the resources of others that resources don't hold yet.
+func MergeResources(resources []auth.Resource, others ...auth.Resource) []auth.Resource {
+	for _, resource := range others {
+		resources = appendResource(resources, resource)
+	}
+	return resources
+}
+
+func appendResource(resources []auth.Resource, resource auth.Resource) []auth.Resource {
+	if resource == (auth.Resource{}) || slices.Contains(resources, resource) {
+		return resources
+	}
+	return append(resources, resource)
+}
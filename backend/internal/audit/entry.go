@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Outcome string
+
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	// The caller wasn't authenticated or wasn't allowed to perform the operation
+	OutcomeDenied Outcome = "denied"
+	// The request was invalid, or named something that doesn't exist
+	OutcomeRejected Outcome = "rejected"
+	OutcomeFailed   Outcome = "failed"
+)
+
+// redacted replaces the values of parameters that look like secrets.
+const redacted = "[REDACTED]"
+
+// The parts of parameter names that mark their values as secrets
+var secretNames = []string{"password", "secret", "token", "authorization", "apikey", "api_key", "credential"}
+
+// Entry records a request made to the API.
+type Entry struct {
+	ID   string    `json:"id" validate:"required"`
+	Time time.Time `json:"time" validate:"required"`
+	// Who made the request. Empty while authentication is disabled
+	User       string      `json:"user,omitempty"`
+	AuthMethod auth.Method `json:"authMethod,omitempty"`
+	// The method and route of the request, e.g. POST /topics/messages/copy
+	Operation string `json:"operation" validate:"required"`
+	// The clusters, topics and groups named by the parameters. An empty cluster is the cluster that is browsed
+	Resources []auth.Resource `json:"resources" validate:"required"`
+	// The path and query parameters and the JSON body of the request, with secrets redacted
+	Parameters map[string]interface{} `json:"parameters" validate:"required"`
+	Outcome    Outcome                `json:"outcome" validate:"required"`
+	// The status code of the response
+	Status int `json:"status" validate:"required"`
+}
+
+// GetOutcome tells the outcome of a request by the status code of its response.
+func GetOutcome(status int) Outcome {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= http.StatusInternalServerError:
+		return OutcomeFailed
+	case status >= http.StatusBadRequest:
+		return OutcomeRejected
+	default:
+		return OutcomeSucceeded
+	}
+}
+
+// Redact replaces the values of the fields of value whose names look like secrets, at any depth.
+func Redact(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(typed))
+		for name, field := range typed {
+			if isSecretName(name) {
+				fields[name] = redacted
+			} else {
+				fields[name] = Redact(field)
+			}
+		}
+		return fields
+	case []interface{}:
+		items := make([]interface{}, len(typed))
+		for i, item := range typed {
+			items[i] = Redact(item)
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(secretNames, func(secret string) bool {
+		return strings.Contains(name, secret)
+	})
+}
+
+// FindResources returns the resources that parameters name as cluster, topic or topicName and group, at the top level
+// or in nested objects such as the source and destination of a copy.
+func FindResources(parameters map[string]interface{}) []auth.Resource {
+	resources := make([]auth.Resource, 0)
+	var visit func(fields map[string]interface{})
+	visit = func(fields map[string]interface{}) {
+		cluster, _ := fields["cluster"].(string)
+		group, _ := fields["group"].(string)
+		var topics []string
+		for _, name := range []string{"topic", "topicName"} {
+			switch value := fields[name].(type) {
+			case string:
+				topics = append(topics, value)
+			case []string:
+				topics = append(topics, value...)
+			}
+		}
+		for _, topic := range topics {
+			resources = appendResource(resources, auth.Resource{Cluster: cluster, Topic: topic, Group: group})
+		}
+		if len(topics) == 0 && (cluster != "" || group != "") {
+			resources = appendResource(resources, auth.Resource{Cluster: cluster, Group: group})
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if nested, ok := fields[name].(map[string]interface{}); ok {
+				visit(nested)
+			}
+		}
+	}
+	visit(parameters)
+	return resources
+}
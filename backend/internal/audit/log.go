@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"errors"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// The number of the newest entries that are kept in memory to be queried
+	RecentEntries int
+}
+
+// Filter narrows down the entries a query returns. Empty fields match every entry.
+type Filter struct {
+	User string
+	// Part of the operation, such as /topics/messages or DELETE
+	Operation string
+	// A topic among the resources
+	Topic   string
+	Outcome Outcome
+	Since   time.Time
+	Until   time.Time
+	// The most entries returned
+	Limit int
+}
+
+// Log records the requests made to the API with its writers, and keeps the newest entries in memory.
+type Log struct {
+	config  Config
+	writers []Writer
+	logger  *zap.Logger
+
+	mu     sync.Mutex
+	recent []Entry
+}
+
+// NewLog creates a log that starts out with the recent entries of a previous run, oldest first.
+func NewLog(config Config, recent []Entry, writers []Writer, logger *zap.Logger) *Log {
+	if len(recent) > config.RecentEntries {
+		recent = recent[len(recent)-config.RecentEntries:]
+	}
+	return &Log{
+		config:  config,
+		writers: writers,
+		logger:  logger,
+		recent:  slices.Clone(recent),
+	}
+}
+
+// Record assigns entry an ID and writes it to every writer. Failing writers are logged, since the request was
+// already served.
+func (l *Log) Record(entry Entry) {
+	entry.ID = uuid.NewString()
+	for _, writer := range l.writers {
+		if err := writer.Write(entry); err != nil {
+			l.logger.Error("Failed to record audit entry", zap.String("operation", entry.Operation), zap.Error(err))
+		}
+	}
+	if l.config.RecentEntries == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.recent) == l.config.RecentEntries {
+		// Shift rather than reslice, so that the backing array doesn't grow
+		copy(l.recent, l.recent[1:])
+		l.recent[len(l.recent)-1] = entry
+	} else {
+		l.recent = append(l.recent, entry)
+	}
+}
+
+// Query returns the recent entries that match filter, newest first.
+func (l *Log) Query(filter Filter) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]Entry, 0)
+	for i := len(l.recent) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+		if filter.matches(l.recent[i]) {
+			entries = append(entries, l.recent[i])
+		}
+	}
+	return entries
+}
+
+// Close closes every writer.
+func (l *Log) Close() error {
+	var errs []error
+	for _, writer := range l.writers {
+		errs = append(errs, writer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (f Filter) matches(entry Entry) bool {
+	if f.User != "" && entry.User != f.User {
+		return false
+	}
+	if f.Operation != "" && !strings.Contains(entry.Operation, f.Operation) {
+		return false
+	}
+	if f.Outcome != "" && entry.Outcome != f.Outcome {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	if f.Topic != "" {
+		return slices.ContainsFunc(entry.Resources, func(resource auth.Resource) bool {
+			return resource.Topic == f.Topic
+		})
+	}
+	return true
+}
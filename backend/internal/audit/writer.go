@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// How much of the end of an audit file is read to restore the recent entries
+const maxTailBytes = 8 * 1024 * 1024
+
+// ErrWriterClosed is returned for entries written after their writer was closed, such as those of requests that
+// finish while the server shuts down.
+var ErrWriterClosed = errors.New("the audit writer is closed")
+
+// Writer is where audit entries are recorded.
+type Writer interface {
+	Write(entry Entry) error
+	Close() error
+}
+
+// FileWriter appends every entry to a file as a line of JSON. The file is never rewritten, so that entries cannot
+// be lost or altered by the server.
+type FileWriter struct {
+	mu     sync.Mutex
+	file   *os.File
+	closed bool
+}
+
+func OpenFileWriter(path string) (*FileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileWriter{file: file}, nil
+}
+
+func (f *FileWriter) Write(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrWriterClosed
+	}
+	if _, err := f.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+func (f *FileWriter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.file.Close()
+}
+
+// ReadTail returns the last n entries of an audit file, oldest first, or none if it doesn't exist yet. Only the end
+// of large files is read, and lines that cannot be parsed are skipped.
+func ReadTail(path string, n int) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	offset := max(info.Size()-maxTailBytes, 0)
+	data, err := io.ReadAll(io.NewSectionReader(file, offset, info.Size()-offset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if offset > 0 {
+		// The first line is most likely cut off
+		if newline := bytes.IndexByte(data, '\n'); newline >= 0 {
+			data = data[newline+1:]
+		}
+	}
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, maxTailBytes)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, nil
+}
+
+// KafkaWriter produces every entry to a topic as JSON, keyed by user. Entries are produced in the background, and
+// dropped when the cluster cannot keep up, so that requests are never held up by it.
+type KafkaWriter struct {
+	topic    string
+	producer sarama.AsyncProducer
+	logger   *zap.Logger
+	done     chan struct{}
+	// Guards the input of producer, which panics when sent to once it is closed
+	mu     sync.Mutex
+	closed bool
+}
+
+func NewKafkaWriter(brokers []string, base *sarama.Config, topic string, logger *zap.Logger) (*KafkaWriter, error) {
+	config := *base
+	config.Producer.Return.Successes = false
+	config.Producer.Return.Errors = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	producer, err := sarama.NewAsyncProducer(brokers, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit producer: %w", err)
+	}
+	writer := &KafkaWriter{topic: topic, producer: producer, logger: logger, done: make(chan struct{})}
+	go writer.logErrors()
+	return writer, nil
+}
+
+func (k *KafkaWriter) Write(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	message := &sarama.ProducerMessage{
+		Topic: k.topic,
+		Key:   sarama.StringEncoder(entry.User),
+		Value: sarama.ByteEncoder(data),
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return ErrWriterClosed
+	}
+	select {
+	case k.producer.Input() <- message:
+		return nil
+	default:
+		return fmt.Errorf("audit topic %s is backed up, dropped entry %s", k.topic, entry.ID)
+	}
+}
+
+// Close waits for the entries that are in flight to be produced.
+func (k *KafkaWriter) Close() error {
+	k.mu.Lock()
+	if k.closed {
+		k.mu.Unlock()
+		return nil
+	}
+	k.closed = true
+	k.producer.AsyncClose()
+	k.mu.Unlock()
+	<-k.done
+	return nil
+}
+
+func (k *KafkaWriter) logErrors() {
+	defer close(k.done)
+	for err := range k.producer.Errors() {
+		k.logger.Error("Failed to produce audit entry", zap.String("topic", k.topic), zap.Error(err))
+	}
+}
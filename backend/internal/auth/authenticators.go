@@ -0,0 +1,39 @@
+package auth
+
+import "net/http"
+
+type Config struct {
+	Tokens []Token
+	// An htpasswd file of the users accepted with basic auth
+	HtpasswdFile string
+	// Accepts the bearer JWTs of an OIDC provider when set
+	OIDC *OIDCConfig
+}
+
+// NewAuthenticators creates an authenticator for every kind of credentials config sets up. Authentication is
+// disabled when there are none.
+func NewAuthenticators(config Config) ([]Authenticator, error) {
+	var authenticators []Authenticator
+	if len(config.Tokens) > 0 {
+		tokenAuthenticator, err := NewTokenAuthenticator(config.Tokens)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, tokenAuthenticator)
+	}
+	if config.HtpasswdFile != "" {
+		basicAuthenticator, err := LoadHtpasswd(config.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, basicAuthenticator)
+	}
+	if config.OIDC != nil {
+		jwtAuthenticator, err := NewJWTAuthenticator(*config.OIDC, &http.Client{Timeout: jwksFetchTimeout})
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+	return authenticators, nil
+}
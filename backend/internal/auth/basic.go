@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// BasicAuthenticator accepts basic auth against the users of an htpasswd file. Passwords must be hashed with bcrypt
+// (htpasswd -B) or SHA-1 (htpasswd -s).
+type BasicAuthenticator struct {
+	hashes map[string]string
+	// The SHA-256 of the password every user last authenticated with, since bcrypt is too slow for every request
+	verified map[string][sha256.Size]byte
+	mutex    sync.RWMutex
+}
+
+// LoadHtpasswd reads an htpasswd file of user:hash lines. Empty lines and lines starting with # are skipped.
+func LoadHtpasswd(path string) (*BasicAuthenticator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file: %w", err)
+	}
+	defer file.Close()
+
+	hashes := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(text, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("line %d of the htpasswd file is not user:hash", line)
+		}
+		if !isBcrypt(hash) && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("the password of user %s must be hashed with bcrypt or SHA-1", user)
+		}
+		hashes[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read htpasswd file: %w", err)
+	}
+	return NewBasicAuthenticator(hashes), nil
+}
+
+// NewBasicAuthenticator accepts the users of hashes, whose values are htpasswd hashes.
+func NewBasicAuthenticator(hashes map[string]string) *BasicAuthenticator {
+	return &BasicAuthenticator{
+		hashes:   hashes,
+		verified: make(map[string][sha256.Size]byte),
+	}
+}
+
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	hash, ok := a.hashes[user]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown user %s", ErrInvalidCredentials, user)
+	}
+	passwordHash := sha256.Sum256([]byte(password))
+	a.mutex.RLock()
+	verified, ok := a.verified[user]
+	a.mutex.RUnlock()
+	if ok && subtle.ConstantTimeCompare(verified[:], passwordHash[:]) == 1 {
+		return &Identity{Name: user, Method: MethodBasic}, nil
+	}
+	if !checkPassword(hash, password) {
+		return nil, fmt.Errorf("%w: wrong password of user %s", ErrInvalidCredentials, user)
+	}
+	a.mutex.Lock()
+	a.verified[user] = passwordHash
+	a.mutex.Unlock()
+	return &Identity{Name: user, Method: MethodBasic}, nil
+}
+
+func (a *BasicAuthenticator) Challenge() string {
+	return `Basic realm="kafka-window", charset="UTF-8"`
+}
+
+func checkPassword(hash string, password string) bool {
+	if isBcrypt(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	sum := sha1.Sum([]byte(password))
+	expected := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(expected)) == 1
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrNoCredentials is returned by an authenticator when the request carries no credentials of its kind.
+var ErrNoCredentials = errors.New("no credentials")
+
+// ErrInvalidCredentials is returned by an authenticator when the credentials of a request don't hold.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+type Method string
+
+const (
+	MethodToken Method = "token"
+	MethodBasic Method = "basic"
+	MethodOIDC  Method = "oidc"
+)
+
+// Identity is who made a request.
+type Identity struct {
+	Name   string `json:"name" validate:"required"`
+	Method Method `json:"method" validate:"required"`
+}
+
+// Authenticator tells who made a request from its credentials.
+type Authenticator interface {
+	// Authenticate returns ErrNoCredentials when r carries no credentials of the kind it checks, and an error
+	// wrapping ErrInvalidCredentials when they don't hold.
+	Authenticate(r *http.Request) (*Identity, error)
+	// Challenge is the WWW-Authenticate header that asks for credentials of the kind it checks
+	Challenge() string
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom returns the identity a request was authenticated as, or nil when authentication is disabled.
+func IdentityFrom(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How often the JWKS is fetched again at most, when a token is signed with a key it doesn't know
+	jwksRefreshInterval = time.Minute
+	jwksFetchTimeout    = 10 * time.Second
+)
+
+// errJWKSUnavailable tells tokens that can't be checked, since the keys couldn't be fetched, from invalid ones.
+var errJWKSUnavailable = errors.New("the JWKS is unavailable")
+
+var jwtSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type OIDCConfig struct {
+	// The issuer tokens must be issued by. The JWKS is discovered from it unless JWKSURL is set
+	Issuer string
+	// The audience tokens must be issued to. Not checked when empty
+	Audience string
+	// Where the keys that sign tokens are published
+	JWKSURL string
+	// The claim that names the caller, sub by default
+	UsernameClaim string
+}
+
+// JWTAuthenticator accepts bearer JWTs signed with a key of the JWKS of an OIDC provider. Tokens must not be expired
+// and must have been issued by the issuer to the audience.
+type JWTAuthenticator struct {
+	config OIDCConfig
+	client *http.Client
+	parser *jwt.Parser
+	// The keys of the JWKS by their ID
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	mutex     sync.Mutex
+	// Shares a fetch of the JWKS among the requests that wait for it
+	fetches singleflight.Group
+}
+
+func NewJWTAuthenticator(config OIDCConfig, client *http.Client) (*JWTAuthenticator, error) {
+	if config.Issuer == "" && config.JWKSURL == "" {
+		return nil, errors.New("OIDC requires an issuer or a JWKS URL")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+	options := []jwt.ParserOption{jwt.WithValidMethods(jwtSigningMethods), jwt.WithExpirationRequired()}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	return &JWTAuthenticator{
+		config: config,
+		client: client,
+		parser: jwt.NewParser(options...),
+	}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	tokenString, ok := getBearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		return a.getKey(r.Context(), keyID)
+	})
+	if errors.Is(err, errJWKSUnavailable) {
+		return nil, fmt.Errorf("failed to check token: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	name, ok := claims[a.config.UsernameClaim].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("%w: the token has no %s claim", ErrInvalidCredentials, a.config.UsernameClaim)
+	}
+	return &Identity{Name: name, Method: MethodOIDC}, nil
+}
+
+func (a *JWTAuthenticator) Challenge() string {
+	return `Bearer realm="kafka-window"`
+}
+
+// getKey returns the key of the JWKS with keyID, fetching the JWKS again when the key is unknown since keys are
+// rotated. Tokens without a key ID are accepted when the JWKS holds a single key.
+func (a *JWTAuthenticator) getKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	a.mutex.Lock()
+	key, ok := a.lookupKey(keyID)
+	stale := time.Since(a.fetchedAt) >= jwksRefreshInterval
+	a.mutex.Unlock()
+	if !ok && stale {
+		// The request only stops waiting when it is canceled, since the fetch may serve other requests
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", errJWKSUnavailable, ctx.Err())
+		case result := <-a.fetches.DoChan("jwks", func() (interface{}, error) { return nil, a.refreshKeys() }):
+			if result.Err != nil {
+				return nil, fmt.Errorf("%w: %w", errJWKSUnavailable, result.Err)
+			}
+		}
+		a.mutex.Lock()
+		key, ok = a.lookupKey(keyID)
+		a.mutex.Unlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+	return key, nil
+}
+
+// refreshKeys fetches the JWKS unless it has been fetched within jwksRefreshInterval. The fetch doesn't hold the
+// mutex, so that tokens signed with known keys are checked meanwhile.
+func (a *JWTAuthenticator) refreshKeys() error {
+	a.mutex.Lock()
+	stale := time.Since(a.fetchedAt) >= jwksRefreshInterval
+	a.mutex.Unlock()
+	if !stale {
+		return nil
+	}
+	fetchCtx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	keys, err := a.fetchKeys(fetchCtx)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	// Failed fetches count as well, so that a provider that is down isn't asked on every request
+	a.fetchedAt = time.Now()
+	if err != nil {
+		return err
+	}
+	a.keys = keys
+	return nil
+}
+
+func (a *JWTAuthenticator) lookupKey(keyID string) (crypto.PublicKey, bool) {
+	if keyID == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, true
+		}
+	}
+	key, ok := a.keys[keyID]
+	return key, ok
+}
+
+func (a *JWTAuthenticator) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	jwksURL := a.config.JWKSURL
+	if jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		discoveryURL := strings.TrimSuffix(a.config.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := a.getJSON(ctx, discoveryURL, &discovery); err != nil {
+			return nil, fmt.Errorf("failed to discover the JWKS: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, errors.New("failed to discover the JWKS: the issuer publishes no jwks_uri")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := a.getJSON(ctx, jwksURL, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, webKey := range jwks.Keys {
+		if webKey.Use != "" && webKey.Use != "sig" {
+			continue
+		}
+		key, err := webKey.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in the JWKS: %w", webKey.KeyID, err)
+		}
+		if key != nil {
+			keys[webKey.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+func (a *JWTAuthenticator) getJSON(ctx context.Context, url string, target interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := a.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered with status %d", url, response.StatusCode)
+	}
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// publicKey decodes an RSA or EC key. Keys of other types are skipped by returning nil.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("the RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("the point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
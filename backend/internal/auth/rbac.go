@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ErrForbidden is matched by the errors returned when the caller isn't allowed to perform an operation.
+var ErrForbidden = errors.New("forbidden")
+
+// ForbiddenError tells which operation the caller wasn't allowed to perform.
+type ForbiddenError struct {
+	Operation Operation
+	Resource  Resource
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("not allowed to %s on %s", e.Operation, e.Resource)
+}
+
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+type Operation string
+
+const (
+	// Listing topics along with their configs, stats and message sizes
+	OperationViewTopics Operation = "view-topics"
+	// Reading the records of topics, including anything derived from their keys and values
+	OperationViewMessages Operation = "view-messages"
+	// Producing records to topics, by importing or copying them
+	OperationProduce Operation = "produce"
+	// Creating, deleting and reconfiguring topics
+	OperationAdminTopics Operation = "admin-topics"
+	// Reading the committed offsets and lag of consumer groups
+	OperationViewGroups Operation = "view-groups"
+	// Moving the committed offsets of consumer groups
+	OperationResetOffsets Operation = "reset-offsets"
+	OperationViewACLs     Operation = "view-acls"
+	OperationViewAlerts   Operation = "view-alerts"
+	// Silencing alerts and lifting silences
+	OperationManageAlerts Operation = "manage-alerts"
+	// Seeing and canceling the jobs of other users
+	OperationAdminJobs Operation = "admin-jobs"
+	// Querying the audit log
+	OperationViewAudit Operation = "view-audit"
+	// Grants every operation
+	OperationAll Operation = "*"
+)
+
+var operations = []Operation{
+	OperationViewTopics,
+	OperationViewMessages,
+	OperationProduce,
+	OperationAdminTopics,
+	OperationViewGroups,
+	OperationResetOffsets,
+	OperationViewACLs,
+	OperationViewAlerts,
+	OperationManageAlerts,
+	OperationAdminJobs,
+	OperationViewAudit,
+	OperationAll,
+}
+
+func IsOperation(operation Operation) bool {
+	return slices.Contains(operations, operation)
+}
+
+// Resource is what an operation is performed on. Only the fields that are set are matched against permissions, so
+// that operations on the whole cluster only depend on the cluster.
+type Resource struct {
+	// The name of the cluster, or the cluster that is browsed when empty
+	Cluster string `json:"cluster,omitempty"`
+	Topic   string `json:"topic,omitempty"`
+	Group   string `json:"group,omitempty"`
+}
+
+func (r Resource) String() string {
+	parts := []string{"cluster " + r.Cluster}
+	if r.Topic != "" {
+		parts = append(parts, "topic "+r.Topic)
+	}
+	if r.Group != "" {
+		parts = append(parts, "group "+r.Group)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Permission grants operations on the resources that match its globs. Empty globs match every resource.
+type Permission struct {
+	Operations []Operation `json:"operations" validate:"required"`
+	Clusters   []string    `json:"clusters,omitempty"`
+	Topics     []string    `json:"topics,omitempty"`
+	Groups     []string    `json:"groups,omitempty"`
+}
+
+func (p Permission) allows(operation Operation, resource Resource) bool {
+	if !slices.Contains(p.Operations, operation) && !slices.Contains(p.Operations, OperationAll) {
+		return false
+	}
+	if !matchesAny(p.Clusters, resource.Cluster) {
+		return false
+	}
+	if resource.Topic != "" && !matchesAny(p.Topics, resource.Topic) {
+		return false
+	}
+	return resource.Group == "" || matchesAny(p.Groups, resource.Group)
+}
+
+type Role struct {
+	Name        string
+	Permissions []Permission
+}
+
+// Binding gives a role to users, who are matched by the name they authenticated as.
+type Binding struct {
+	Role  string
+	Users []string
+}
+
+type RBACConfig struct {
+	Roles    []Role
+	Bindings []Binding
+	// The roles of every authenticated user, whether or not they are bound to others
+	DefaultRoles []string
+	// The name of the cluster that is browsed, which resources without a cluster are on
+	Cluster string
+}
+
+// Authorizer decides which operations the caller of a request may perform, by the roles of the identity it
+// authenticated as. Anything is allowed when there is no identity, which is the case for requests while authentication
+// is disabled and for the background work of the server, and by a nil Authorizer.
+type Authorizer struct {
+	roles map[string]Role
+	// The names of the roles of every user that is bound to one
+	bindings     map[string][]string
+	defaultRoles []string
+	cluster      string
+}
+
+// NewAuthorizer returns nil when config has no roles, which disables access control.
+func NewAuthorizer(config RBACConfig) (*Authorizer, error) {
+	if len(config.Roles) == 0 {
+		return nil, nil
+	}
+	authorizer := &Authorizer{
+		roles:    make(map[string]Role, len(config.Roles)),
+		bindings: make(map[string][]string),
+		cluster:  config.Cluster,
+	}
+	for _, role := range config.Roles {
+		if role.Name == "" {
+			return nil, errors.New("every role requires a name")
+		}
+		if _, ok := authorizer.roles[role.Name]; ok {
+			return nil, fmt.Errorf("role %s is defined more than once", role.Name)
+		}
+		for _, permission := range role.Permissions {
+			if err := validatePermission(permission); err != nil {
+				return nil, fmt.Errorf("role %s: %w", role.Name, err)
+			}
+		}
+		authorizer.roles[role.Name] = role
+	}
+	for _, binding := range config.Bindings {
+		if _, ok := authorizer.roles[binding.Role]; !ok {
+			return nil, fmt.Errorf("binding of unknown role %s", binding.Role)
+		}
+		for _, user := range binding.Users {
+			if !slices.Contains(authorizer.bindings[user], binding.Role) {
+				authorizer.bindings[user] = append(authorizer.bindings[user], binding.Role)
+			}
+		}
+	}
+	for _, role := range config.DefaultRoles {
+		if _, ok := authorizer.roles[role]; !ok {
+			return nil, fmt.Errorf("unknown default role %s", role)
+		}
+	}
+	authorizer.defaultRoles = config.DefaultRoles
+	return authorizer, nil
+}
+
+func validatePermission(permission Permission) error {
+	if len(permission.Operations) == 0 {
+		return errors.New("every permission requires an operation")
+	}
+	for _, operation := range permission.Operations {
+		if !IsOperation(operation) {
+			return fmt.Errorf("unknown operation %q", operation)
+		}
+	}
+	for _, glob := range slices.Concat(permission.Clusters, permission.Topics, permission.Groups) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// Allows tells whether the caller of ctx may perform operation on resource.
+func (a *Authorizer) Allows(ctx context.Context, operation Operation, resource Resource) bool {
+	identity := IdentityFrom(ctx)
+	if a == nil || identity == nil {
+		return true
+	}
+	if resource.Cluster == "" {
+		resource.Cluster = a.cluster
+	}
+	for _, permission := range a.Permissions(identity) {
+		if permission.allows(operation, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns a ForbiddenError when the caller of ctx may not perform operation on resource.
+func (a *Authorizer) Authorize(ctx context.Context, operation Operation, resource Resource) error {
+	if a.Allows(ctx, operation, resource) {
+		return nil
+	}
+	if resource.Cluster == "" {
+		resource.Cluster = a.cluster
+	}
+	return &ForbiddenError{Operation: operation, Resource: resource}
+}
+
+// Roles returns the names of the roles of identity.
+func (a *Authorizer) Roles(identity *Identity) []string {
+	roles := slices.Clone(a.defaultRoles)
+	for _, role := range a.bindings[identity.Name] {
+		if !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Permissions returns the permissions that the roles of identity grant.
+func (a *Authorizer) Permissions(identity *Identity) []Permission {
+	var permissions []Permission
+	for _, role := range a.Roles(identity) {
+		permissions = append(permissions, a.roles[role].Permissions...)
+	}
+	return permissions
+}
+
+func matchesAny(globs []string, name string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Grants is what the caller of a request may do.
+type Grants struct {
+	Identity *Identity `json:"identity,omitempty"`
+	// Whether the caller may only do what its permissions grant. Callers that aren't restricted may do anything
+	Restricted  bool         `json:"restricted"`
+	Roles       []string     `json:"roles"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Grants returns what the caller of ctx may do.
+func (a *Authorizer) Grants(ctx context.Context) Grants {
+	identity := IdentityFrom(ctx)
+	grants := Grants{Identity: identity, Roles: []string{}, Permissions: []Permission{}}
+	if a == nil || identity == nil {
+		return grants
+	}
+	grants.Restricted = true
+	grants.Roles = a.Roles(identity)
+	grants.Permissions = append(grants.Permissions, a.Permissions(identity)...)
+	return grants
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type Token struct {
+	// Who the token identifies
+	Name  string
+	Token string
+}
+
+// TokenAuthenticator accepts static API tokens, sent as bearer tokens or in the X-API-Token header.
+type TokenAuthenticator struct {
+	tokens []hashedToken
+}
+
+// hashedToken keeps a token as a hash, so that comparisons take the same time however much of a token matches.
+type hashedToken struct {
+	name string
+	hash [sha256.Size]byte
+}
+
+func NewTokenAuthenticator(tokens []Token) (*TokenAuthenticator, error) {
+	hashed := make([]hashedToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Name == "" || token.Token == "" {
+			return nil, errors.New("every API token requires a name and a token")
+		}
+		hashed = append(hashed, hashedToken{name: token.Name, hash: sha256.Sum256([]byte(token.Token))})
+	}
+	return &TokenAuthenticator{tokens: hashed}, nil
+}
+
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token := r.Header.Get("X-API-Token")
+	if token == "" {
+		bearer, ok := getBearerToken(r)
+		if !ok {
+			return nil, ErrNoCredentials
+		}
+		token = bearer
+	}
+	hash := sha256.Sum256([]byte(token))
+	for _, known := range a.tokens {
+		if subtle.ConstantTimeCompare(hash[:], known.hash[:]) == 1 {
+			return &Identity{Name: known.name, Method: MethodToken}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown API token", ErrInvalidCredentials)
+}
+
+func (a *TokenAuthenticator) Challenge() string {
+	return `Bearer realm="kafka-window"`
+}
+
+func getBearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,352 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	defaultBroker            = "localhost:9092"
+	defaultDataDir           = "data"
+	defaultMaxConcurrentJobs = 4
+	defaultJobHistorySize    = 500
+	defaultMaxPartitionBytes = 1024 * 1024
+	defaultBrokerConcurrency = 4
+	defaultSampleInterval    = 15 * time.Second
+	defaultLagInterval       = 30 * time.Second
+	defaultLagRawRetention   = 24 * time.Hour
+	defaultAlertInterval     = time.Minute
+	defaultMaxTables         = 5
+	defaultAuditFileName     = "audit.log"
+	defaultAuditRecent       = 10_000
+)
+
+var defaultStatsWindows = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+var defaultLagDownsampling = []DownsamplingConfig{
+	{Resolution: 5 * time.Minute, Retention: 7 * 24 * time.Hour},
+	{Resolution: time.Hour, Retention: 90 * 24 * time.Hour},
+}
+
+type ClusterConfig struct {
+	// The name other clusters are referred to by, e.g. as the destination of a copy
+	Name    string   `yaml:"name"`
+	Brokers []string `yaml:"brokers"`
+}
+
+type JobsConfig struct {
+	// The number of background jobs that run at the same time
+	MaxConcurrent int `yaml:"maxConcurrent"`
+	// The number of finished jobs that are kept in the history
+	HistorySize int `yaml:"historySize"`
+}
+
+type FetchConfig struct {
+	// The most bytes a single fetch request returns for a partition
+	MaxPartitionBytes int32 `yaml:"maxPartitionBytes"`
+	// The most fetch requests in flight to a single broker
+	BrokerConcurrency int `yaml:"brokerConcurrency"`
+}
+
+type StatsConfig struct {
+	// How often the high watermarks and sizes of every partition are sampled, e.g. 15s
+	SampleInterval time.Duration `yaml:"sampleInterval"`
+	// The windows that topic throughput is computed over, e.g. [1m, 5m, 15m]
+	Windows []time.Duration `yaml:"windows"`
+}
+
+type DownsamplingConfig struct {
+	// The span of time that samples are merged over, e.g. 5m
+	Resolution time.Duration `yaml:"resolution"`
+	// How long the merged points are kept, e.g. 168h
+	Retention time.Duration `yaml:"retention"`
+}
+
+type LagConfig struct {
+	// How often the committed offsets of every consumer group are polled, e.g. 30s
+	Interval time.Duration `yaml:"interval"`
+	// How long every polled sample is kept, e.g. 24h
+	RawRetention time.Duration        `yaml:"rawRetention"`
+	Downsampling []DownsamplingConfig `yaml:"downsampling"`
+}
+
+type AlertRuleConfig struct {
+	Name string `yaml:"name"`
+	// One of lag_above, lag_growing, consumer_stalled, under_replicated, offline_partition and topic_idle
+	Type string `yaml:"type"`
+	// Globs of the consumer groups that lag rules apply to. Every group when empty
+	Groups []string `yaml:"groups"`
+	// Globs of the topics that the rule applies to. Every topic but internal ones when empty
+	Topics []string `yaml:"topics"`
+	// The lag above which lag_above fires
+	Threshold int64 `yaml:"threshold"`
+	// How long the condition has to hold before the alert fires, e.g. 10m
+	For      time.Duration `yaml:"for"`
+	Severity string        `yaml:"severity"`
+	// The names of the sinks that are notified. Every sink when empty
+	Sinks []string `yaml:"sinks"`
+}
+
+type AlertSinkConfig struct {
+	Name string `yaml:"name"`
+	// One of webhook, slack and log
+	Type    string            `yaml:"type"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+type AlertsConfig struct {
+	// How often the rules are evaluated, e.g. 1m
+	Interval time.Duration     `yaml:"interval"`
+	Rules    []AlertRuleConfig `yaml:"rules"`
+	// Alerts are logged when no sink is configured
+	Sinks []AlertSinkConfig `yaml:"sinks"`
+}
+
+type TablesConfig struct {
+	// The number of scanned compacted topic tables that are kept in memory
+	MaxTables int `yaml:"maxTables"`
+}
+
+type APITokenConfig struct {
+	// Who the token identifies
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+}
+
+type OIDCConfig struct {
+	// The issuer tokens must be issued by. The JWKS is discovered from it unless jwksUrl is set
+	Issuer string `yaml:"issuer"`
+	// The audience tokens must be issued to. Not checked when empty
+	Audience string `yaml:"audience"`
+	JWKSURL  string `yaml:"jwksUrl"`
+	// The claim that names the caller, sub by default
+	UsernameClaim string `yaml:"usernameClaim"`
+}
+
+// AuthConfig sets up the credentials the API accepts. Authentication is disabled when none are configured
+type AuthConfig struct {
+	// Tokens accepted as bearer tokens or in the X-API-Token header
+	Tokens []APITokenConfig `yaml:"tokens"`
+	// An htpasswd file of the users accepted with basic auth, whose passwords are hashed with bcrypt or SHA-1
+	HtpasswdFile string `yaml:"htpasswdFile"`
+	// Bearer JWTs of an OIDC provider are accepted when set
+	OIDC *OIDCConfig `yaml:"oidc"`
+}
+
+type PermissionConfig struct {
+	// Any of view-topics, view-messages, produce, admin-topics, view-groups, reset-offsets, view-acls, view-alerts,
+	// manage-alerts, admin-jobs and view-audit, or * for every operation
+	Operations []string `yaml:"operations"`
+	// Globs that the cluster, topic and group an operation is performed on must match. Empty matches every one
+	Clusters []string `yaml:"clusters"`
+	Topics   []string `yaml:"topics"`
+	Groups   []string `yaml:"groups"`
+}
+
+type RoleConfig struct {
+	Name        string             `yaml:"name"`
+	Permissions []PermissionConfig `yaml:"permissions"`
+}
+
+type RoleBindingConfig struct {
+	Role string `yaml:"role"`
+	// The names users authenticate as, such as the name of an API token or the username claim of a JWT
+	Users []string `yaml:"users"`
+}
+
+// RBACConfig restricts what authenticated users may do. Access control is disabled when no roles are configured
+type RBACConfig struct {
+	Roles    []RoleConfig        `yaml:"roles"`
+	Bindings []RoleBindingConfig `yaml:"bindings"`
+	// The roles of every authenticated user, whether or not they are bound to others
+	DefaultRoles []string `yaml:"defaultRoles"`
+}
+
+// AuditConfig sets up where the requests made to the API are recorded
+type AuditConfig struct {
+	// The file every entry is appended to, audit.log in the data directory by default
+	File string `yaml:"file"`
+	// A topic of the browsed cluster that every entry is also produced to, when set
+	Topic string `yaml:"topic"`
+	// The number of the newest entries that are kept in memory to be queried
+	RecentEntries int `yaml:"recentEntries"`
+}
+
+type Config struct {
+	// The first cluster is the one that is browsed. The others can be used as destinations
+	Clusters []ClusterConfig `yaml:"clusters"`
+	// Where state such as the job history and the files jobs produce is kept
+	DataDir string       `yaml:"dataDir"`
+	Jobs    JobsConfig   `yaml:"jobs"`
+	Fetch   FetchConfig  `yaml:"fetch"`
+	Stats   StatsConfig  `yaml:"stats"`
+	Lag     LagConfig    `yaml:"lag"`
+	Alerts  AlertsConfig `yaml:"alerts"`
+	Tables  TablesConfig `yaml:"tables"`
+	Auth    AuthConfig   `yaml:"auth"`
+	RBAC    RBACConfig   `yaml:"rbac"`
+	Audit   AuditConfig  `yaml:"audit"`
+}
+
+func Default() *Config {
+	config := &Config{
+		Clusters: []ClusterConfig{{Name: "default", Brokers: []string{defaultBroker}}},
+	}
+	config.applyDefaults()
+	return config
+}
+
+// Load reads a YAML config file, falling back to Default when path is empty.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	config.applyDefaults()
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.DataDir == "" {
+		c.DataDir = defaultDataDir
+	}
+	if c.Jobs.MaxConcurrent == 0 {
+		c.Jobs.MaxConcurrent = defaultMaxConcurrentJobs
+	}
+	if c.Jobs.HistorySize == 0 {
+		c.Jobs.HistorySize = defaultJobHistorySize
+	}
+	if c.Fetch.MaxPartitionBytes == 0 {
+		c.Fetch.MaxPartitionBytes = defaultMaxPartitionBytes
+	}
+	if c.Fetch.BrokerConcurrency == 0 {
+		c.Fetch.BrokerConcurrency = defaultBrokerConcurrency
+	}
+	if c.Stats.SampleInterval == 0 {
+		c.Stats.SampleInterval = defaultSampleInterval
+	}
+	if len(c.Stats.Windows) == 0 {
+		c.Stats.Windows = defaultStatsWindows
+	}
+	if c.Lag.Interval == 0 {
+		c.Lag.Interval = defaultLagInterval
+	}
+	if c.Lag.RawRetention == 0 {
+		c.Lag.RawRetention = defaultLagRawRetention
+	}
+	if c.Lag.Downsampling == nil {
+		c.Lag.Downsampling = defaultLagDownsampling
+	}
+	if c.Alerts.Interval == 0 {
+		c.Alerts.Interval = defaultAlertInterval
+	}
+	if len(c.Alerts.Sinks) == 0 {
+		c.Alerts.Sinks = []AlertSinkConfig{{Name: "log", Type: "log"}}
+	}
+	if c.Tables.MaxTables == 0 {
+		c.Tables.MaxTables = defaultMaxTables
+	}
+	if c.Audit.File == "" {
+		c.Audit.File = filepath.Join(c.DataDir, defaultAuditFileName)
+	}
+	if c.Audit.RecentEntries == 0 {
+		c.Audit.RecentEntries = defaultAuditRecent
+	}
+}
+
+func (c *Config) validate() error {
+	if len(c.Clusters) == 0 {
+		return errors.New("at least one cluster is required")
+	}
+	if c.Jobs.MaxConcurrent < 0 || c.Jobs.HistorySize < 0 {
+		return errors.New("job limits must not be negative")
+	}
+	if c.Fetch.MaxPartitionBytes < 0 || c.Fetch.BrokerConcurrency < 0 {
+		return errors.New("fetch limits must not be negative")
+	}
+	if c.Stats.SampleInterval < 0 {
+		return errors.New("the stats sample interval must not be negative")
+	}
+	for _, window := range c.Stats.Windows {
+		if window < c.Stats.SampleInterval {
+			return fmt.Errorf("stats window %s is shorter than the sample interval", window)
+		}
+	}
+	if c.Lag.Interval < 0 || c.Lag.RawRetention < 0 {
+		return errors.New("the lag interval and retention must not be negative")
+	}
+	for _, downsampling := range c.Lag.Downsampling {
+		if downsampling.Resolution < c.Lag.Interval {
+			return fmt.Errorf("lag resolution %s is finer than the interval", downsampling.Resolution)
+		}
+		if downsampling.Retention < downsampling.Resolution {
+			return fmt.Errorf("lag resolution %s is kept for less than its span", downsampling.Resolution)
+		}
+	}
+	if c.Alerts.Interval < 0 {
+		return errors.New("the alert interval must not be negative")
+	}
+	if c.Tables.MaxTables < 0 {
+		return errors.New("the number of tables kept must not be negative")
+	}
+	if c.Audit.RecentEntries < 0 {
+		return errors.New("the number of recent audit entries must not be negative")
+	}
+	for _, token := range c.Auth.Tokens {
+		if token.Name == "" || token.Token == "" {
+			return errors.New("every API token requires a name and a token")
+		}
+	}
+	if c.Auth.OIDC != nil && c.Auth.OIDC.Issuer == "" && c.Auth.OIDC.JWKSURL == "" {
+		return errors.New("OIDC requires an issuer or a JWKS URL")
+	}
+	roles := make(map[string]struct{}, len(c.RBAC.Roles))
+	for _, role := range c.RBAC.Roles {
+		if role.Name == "" {
+			return errors.New("every role requires a name")
+		}
+		if _, ok := roles[role.Name]; ok {
+			return fmt.Errorf("role %s is configured more than once", role.Name)
+		}
+		roles[role.Name] = struct{}{}
+	}
+	for _, binding := range c.RBAC.Bindings {
+		if _, ok := roles[binding.Role]; !ok {
+			return fmt.Errorf("binding of unknown role %s", binding.Role)
+		}
+	}
+	for _, role := range c.RBAC.DefaultRoles {
+		if _, ok := roles[role]; !ok {
+			return fmt.Errorf("unknown default role %s", role)
+		}
+	}
+	names := make(map[string]struct{}, len(c.Clusters))
+	for _, cluster := range c.Clusters {
+		if cluster.Name == "" {
+			return errors.New("every cluster requires a name")
+		}
+		if _, ok := names[cluster.Name]; ok {
+			return fmt.Errorf("cluster %s is configured more than once", cluster.Name)
+		}
+		names[cluster.Name] = struct{}{}
+		if len(cluster.Brokers) == 0 {
+			return fmt.Errorf("cluster %s requires at least one broker", cluster.Name)
+		}
+	}
+	return nil
+}
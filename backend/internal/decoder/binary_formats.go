@@ -0,0 +1,182 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"math"
+	"math/big"
+	"time"
+)
+
+// cborSelfDescribeTag is the optional "self-described CBOR" prefix (tag 55799) defined in RFC 8949.
+var cborSelfDescribeTag = []byte{0xd9, 0xd9, 0xf7}
+
+func isBSON(data []byte) bool {
+	if len(data) < 5 {
+		return false
+	}
+	if int(binary.LittleEndian.Uint32(data[:4])) != len(data) || data[len(data)-1] != 0x00 {
+		return false
+	}
+	return bson.Raw(data).Validate() == nil
+}
+
+// isMessagePack only accepts top-level maps and arrays, since scalars are indistinguishable from arbitrary bytes,
+// and requires the whole payload to be consumed by a single value.
+func isMessagePack(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	first := data[0]
+	isContainer := (first >= 0x80 && first <= 0x9f) || (first >= 0xdc && first <= 0xdf)
+	if !isContainer {
+		return false
+	}
+	_, err := decodeMessagePackNative(data)
+	return err == nil
+}
+
+// isCBOR only accepts self-described payloads or top-level maps and arrays, for the same reason as isMessagePack.
+func isCBOR(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	majorType := data[0] >> 5
+	isContainer := majorType == 4 || majorType == 5
+	if !isContainer && !bytes.HasPrefix(data, cborSelfDescribeTag) {
+		return false
+	}
+	return cbor.Wellformed(data) == nil
+}
+
+func (m *MessageDecoder) decodeMessagePack(value []byte) (string, model.JSONValue, error) {
+	native, err := decodeMessagePackNative(value)
+	if err != nil {
+		return "", model.JSONValue{}, fmt.Errorf("failed to decode MessagePack payload: %w", err)
+	}
+	return nativeToJSON(native)
+}
+
+func (m *MessageDecoder) decodeCBOR(value []byte) (string, model.JSONValue, error) {
+	var native interface{}
+	if err := cbor.Unmarshal(value, &native); err != nil {
+		return "", model.JSONValue{}, fmt.Errorf("failed to decode CBOR payload: %w", err)
+	}
+	return nativeToJSON(native)
+}
+
+func (m *MessageDecoder) decodeBSON(value []byte) (string, model.JSONValue, error) {
+	jsonBytes, err := bson.MarshalExtJSON(bson.Raw(value), false, false)
+	if err != nil {
+		return "", model.JSONValue{}, fmt.Errorf("failed to convert BSON to extended JSON: %w", err)
+	}
+	jsonString := string(jsonBytes)
+	parsedJSON, err := parseString(jsonString)
+	if err != nil {
+		return jsonString, model.JSONValue{}, fmt.Errorf("failed to parse BSON extended JSON: %w", err)
+	}
+	return jsonString, parsedJSON, nil
+}
+
+func decodeMessagePackNative(data []byte) (interface{}, error) {
+	reader := bytes.NewReader(data)
+	dec := msgpack.NewDecoder(reader)
+	dec.SetMapDecoder(func(d *msgpack.Decoder) (interface{}, error) {
+		return d.DecodeUntypedMap()
+	})
+	native, err := dec.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+	if reader.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after MessagePack value", reader.Len())
+	}
+	return native, nil
+}
+
+func nativeToJSON(native interface{}) (string, model.JSONValue, error) {
+	jsonBytes, err := json.Marshal(normalizeNative(native))
+	if err != nil {
+		return "", model.JSONValue{}, fmt.Errorf("failed to marshal decoded payload to JSON: %w", err)
+	}
+	jsonString := string(jsonBytes)
+	parsedJSON, err := parseString(jsonString)
+	if err != nil {
+		return jsonString, model.JSONValue{}, fmt.Errorf("failed to parse decoded payload JSON: %w", err)
+	}
+	return jsonString, parsedJSON, nil
+}
+
+// normalizeNative rewrites the values produced by the binary decoders into types that encoding/json can marshal.
+// Non-string map keys are stringified, byte strings become base64 and values without a JSON equivalent
+// (non-finite floats, big integers, timestamps, CBOR tags) are rendered as strings or wrapper objects.
+func normalizeNative(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = normalizeNative(val)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[stringifyKey(key)] = normalizeNative(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = normalizeNative(val)
+		}
+		return out
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case float32:
+		return normalizeFloat(float64(v))
+	case float64:
+		return normalizeFloat(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case big.Int:
+		return v.String()
+	case *big.Int:
+		return v.String()
+	case cbor.Tag:
+		return map[string]interface{}{
+			"tag":   v.Number,
+			"value": normalizeNative(v.Content),
+		}
+	case cbor.SimpleValue:
+		return uint8(v)
+	default:
+		return v
+	}
+}
+
+func normalizeFloat(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprint(f)
+	}
+	return f
+}
+
+func stringifyKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return base64.StdEncoding.EncodeToString(k)
+	case cbor.ByteString:
+		return base64.StdEncoding.EncodeToString(k.Bytes())
+	default:
+		return fmt.Sprint(k)
+	}
+}
@@ -0,0 +1,98 @@
+package decoder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"io"
+)
+
+// maxDecompressedSize caps how much a single compressed payload may expand to, guarding against zip bombs.
+const maxDecompressedSize = 16 * 1024 * 1024
+
+// maxCompressionLayers bounds how many nested compression layers are stripped from a single payload.
+const maxCompressionLayers = 3
+
+var (
+	gzipMagic        = []byte{0x1f, 0x8b}
+	zstdMagic        = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	snappyFrameMagic = []byte{0xff, 0x06, 0x00, 0x00, 's', 'N', 'a', 'P', 'p', 'Y'}
+	lz4FrameMagic    = []byte{0x04, 0x22, 0x4d, 0x18}
+)
+
+func getCompressionType(data []byte) (model.CompressionType, bool) {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		return model.CompressionGzip, true
+	case bytes.HasPrefix(data, zstdMagic):
+		return model.CompressionZstd, true
+	case bytes.HasPrefix(data, snappyFrameMagic):
+		return model.CompressionSnappy, true
+	case bytes.HasPrefix(data, lz4FrameMagic):
+		return model.CompressionLZ4, true
+	default:
+		return "", false
+	}
+}
+
+// decompress strips application-level compression from a payload, returning the innermost payload and the
+// compression layers that were removed, outermost first.
+func (m *MessageDecoder) decompress(data []byte) ([]byte, []model.CompressionType, error) {
+	var layers []model.CompressionType
+	for len(layers) < maxCompressionLayers {
+		compressionType, ok := getCompressionType(data)
+		if !ok {
+			break
+		}
+		decompressed, err := decompressLayer(data, compressionType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress %s payload: %w", compressionType, err)
+		}
+		data = decompressed
+		layers = append(layers, compressionType)
+	}
+	return data, layers, nil
+}
+
+func decompressLayer(data []byte, compressionType model.CompressionType) ([]byte, error) {
+	var reader io.Reader
+	switch compressionType {
+	case model.CompressionGzip:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case model.CompressionZstd:
+		zstdReader, err := zstd.NewReader(
+			bytes.NewReader(data),
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(maxDecompressedSize),
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	case model.CompressionSnappy:
+		reader = snappy.NewReader(bytes.NewReader(data))
+	case model.CompressionLZ4:
+		reader = lz4.NewReader(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported compression type: %s", compressionType)
+	}
+
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed size exceeds limit of %d bytes", maxDecompressedSize)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,188 @@
+package decoder
+
+import (
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"strconv"
+	"strings"
+)
+
+type ConnectTopicType string
+
+const (
+	ConnectTopicNone    ConnectTopicType = ""
+	ConnectTopicConfigs ConnectTopicType = "configs"
+	ConnectTopicOffsets ConnectTopicType = "offsets"
+	ConnectTopicStatus  ConnectTopicType = "status"
+)
+
+type connectOffsetKey struct {
+	Connector string          `json:"connector"`
+	Partition json.RawMessage `json:"partition"`
+}
+
+type connectRecordKey struct {
+	Type      string `json:"type"`
+	Connector string `json:"connector,omitempty"`
+	Task      *int   `json:"task,omitempty"`
+	Topic     string `json:"topic,omitempty"`
+}
+
+// GetConnectTopicType recognizes the Kafka Connect internal topics by the naming convention of their
+// config.storage.topic, offset.storage.topic and status.storage.topic settings, e.g. connect-configs,
+// _connect-offsets or docker-connect-status.
+func GetConnectTopicType(topic string) ConnectTopicType {
+	name := strings.ToLower(topic)
+	if !strings.Contains(name, "connect") {
+		return ConnectTopicNone
+	}
+	switch {
+	case strings.HasSuffix(name, "-configs"), strings.HasSuffix(name, "-config"):
+		return ConnectTopicConfigs
+	case strings.HasSuffix(name, "-offsets"), strings.HasSuffix(name, "-offset"):
+		return ConnectTopicOffsets
+	case strings.HasSuffix(name, "-status"), strings.HasSuffix(name, "-statuses"):
+		return ConnectTopicStatus
+	default:
+		return ConnectTopicNone
+	}
+}
+
+func (m *MessageDecoder) decodeConnectRecord(
+	topic string,
+	topicType ConnectTopicType,
+	keyBytes []byte,
+	valueBytes []byte,
+) (*DecodedKeyAndValue, error) {
+	key, err := m.decodeConnectKey(topicType, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		key, err = m.decodePayload(topic, keyBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(valueBytes) == 0 {
+		value, err := newStructuredPayload(
+			tombstone{Tombstone: true, Reason: connectTombstoneReason(topicType)},
+			model.JSONPayload,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedKeyAndValue{Key: key, Value: value}, nil
+	}
+
+	value, err := m.decodePayload(topic, valueBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedKeyAndValue{Key: key, Value: value}, nil
+}
+
+// decodeConnectKey returns nil without an error when the key does not follow the Connect format for the topic,
+// so that the caller can fall back to regular decoding for topics that merely look like Connect topics.
+func (m *MessageDecoder) decodeConnectKey(topicType ConnectTopicType, keyBytes []byte) (*DecodedPayload, error) {
+	var record interface{}
+	switch topicType {
+	case ConnectTopicOffsets:
+		var parts []json.RawMessage
+		if err := json.Unmarshal(keyBytes, &parts); err != nil || len(parts) != 2 {
+			return nil, nil
+		}
+		var connector string
+		if err := json.Unmarshal(parts[0], &connector); err != nil {
+			return nil, nil
+		}
+		record = connectOffsetKey{Connector: connector, Partition: parts[1]}
+	case ConnectTopicConfigs:
+		configKey, ok := parseConnectConfigKey(string(keyBytes))
+		if !ok {
+			return nil, nil
+		}
+		record = configKey
+	case ConnectTopicStatus:
+		statusKey, ok := parseConnectStatusKey(string(keyBytes))
+		if !ok {
+			return nil, nil
+		}
+		record = statusKey
+	default:
+		return nil, nil
+	}
+	return newStructuredPayload(record, model.JSONPayload)
+}
+
+func parseConnectConfigKey(key string) (connectRecordKey, bool) {
+	switch {
+	case key == "session-key":
+		return connectRecordKey{Type: "sessionKey"}, true
+	case strings.HasPrefix(key, "connector-"):
+		return connectRecordKey{Type: "connector", Connector: strings.TrimPrefix(key, "connector-")}, true
+	case strings.HasPrefix(key, "target-state-"):
+		return connectRecordKey{Type: "targetState", Connector: strings.TrimPrefix(key, "target-state-")}, true
+	case strings.HasPrefix(key, "commit-"):
+		return connectRecordKey{Type: "commit", Connector: strings.TrimPrefix(key, "commit-")}, true
+	case strings.HasPrefix(key, "tasks-fencing-"):
+		return connectRecordKey{Type: "tasksFencing", Connector: strings.TrimPrefix(key, "tasks-fencing-")}, true
+	case strings.HasPrefix(key, "task-"):
+		connector, task, ok := parseConnectorTask(strings.TrimPrefix(key, "task-"))
+		if !ok {
+			return connectRecordKey{}, false
+		}
+		return connectRecordKey{Type: "task", Connector: connector, Task: &task}, true
+	default:
+		return connectRecordKey{}, false
+	}
+}
+
+func parseConnectStatusKey(key string) (connectRecordKey, bool) {
+	switch {
+	case strings.HasPrefix(key, "status-connector-"):
+		return connectRecordKey{Type: "connectorStatus", Connector: strings.TrimPrefix(key, "status-connector-")}, true
+	case strings.HasPrefix(key, "status-task-"):
+		connector, task, ok := parseConnectorTask(strings.TrimPrefix(key, "status-task-"))
+		if !ok {
+			return connectRecordKey{}, false
+		}
+		return connectRecordKey{Type: "taskStatus", Connector: connector, Task: &task}, true
+	case strings.HasPrefix(key, "status-topic-"):
+		// status-topic-<topic>:connector-<connector>
+		topic, connector, found := strings.Cut(strings.TrimPrefix(key, "status-topic-"), ":connector-")
+		if !found {
+			return connectRecordKey{}, false
+		}
+		return connectRecordKey{Type: "topicStatus", Connector: connector, Topic: topic}, true
+	default:
+		return connectRecordKey{}, false
+	}
+}
+
+// parseConnectorTask splits "<connector>-<task>", where the connector name may itself contain dashes.
+func parseConnectorTask(value string) (string, int, bool) {
+	separator := strings.LastIndex(value, "-")
+	if separator <= 0 {
+		return "", 0, false
+	}
+	task, err := strconv.Atoi(value[separator+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return value[:separator], task, true
+}
+
+func connectTombstoneReason(topicType ConnectTopicType) string {
+	switch topicType {
+	case ConnectTopicConfigs:
+		return "connector configuration deleted"
+	case ConnectTopicOffsets:
+		return "source offset reset"
+	case ConnectTopicStatus:
+		return "status removed"
+	default:
+		return "record deleted"
+	}
+}
@@ -0,0 +1,357 @@
+package decoder
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// The highest record versions that kmsg knows how to read for the __consumer_offsets schemas.
+const (
+	offsetCommitValueMaxVersion  = 3
+	groupMetadataValueMaxVersion = 4
+)
+
+const consumerProtocolType = "consumer"
+
+type offsetCommitKey struct {
+	Type      string `json:"type"`
+	Version   int16  `json:"version"`
+	Group     string `json:"group"`
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+}
+
+type offsetCommitValue struct {
+	Version         int16  `json:"version"`
+	Offset          int64  `json:"offset"`
+	LeaderEpoch     *int32 `json:"leaderEpoch,omitempty"`
+	Metadata        string `json:"metadata"`
+	CommitTimestamp int64  `json:"commitTimestamp"`
+	ExpireTimestamp *int64 `json:"expireTimestamp,omitempty"`
+}
+
+type groupMetadataKey struct {
+	Type    string `json:"type"`
+	Version int16  `json:"version"`
+	Group   string `json:"group"`
+}
+
+type groupMetadataValue struct {
+	Version               int16                 `json:"version"`
+	ProtocolType          string                `json:"protocolType"`
+	Generation            int32                 `json:"generation"`
+	Protocol              *string               `json:"protocol"`
+	Leader                *string               `json:"leader"`
+	CurrentStateTimestamp *int64                `json:"currentStateTimestamp,omitempty"`
+	Members               []groupMetadataMember `json:"members"`
+}
+
+type groupMetadataMember struct {
+	MemberID               string                `json:"memberId"`
+	GroupInstanceID        *string               `json:"groupInstanceId,omitempty"`
+	ClientID               string                `json:"clientId"`
+	ClientHost             string                `json:"clientHost"`
+	RebalanceTimeoutMillis *int32                `json:"rebalanceTimeoutMillis,omitempty"`
+	SessionTimeoutMillis   int32                 `json:"sessionTimeoutMillis"`
+	Subscription           *consumerSubscription `json:"subscription,omitempty"`
+	Assignment             []topicPartitions     `json:"assignment,omitempty"`
+	// Set instead of Subscription and Assignment when the group does not use the consumer protocol
+	RawSubscription string `json:"rawSubscription,omitempty"`
+	RawAssignment   string `json:"rawAssignment,omitempty"`
+}
+
+type consumerSubscription struct {
+	Topics          []string          `json:"topics"`
+	OwnedPartitions []topicPartitions `json:"ownedPartitions,omitempty"`
+	Rack            *string           `json:"rack,omitempty"`
+}
+
+type topicPartitions struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"`
+}
+
+type unknownRecord struct {
+	Version int16  `json:"version"`
+	Unknown bool   `json:"unknown"`
+	Raw     string `json:"raw"`
+}
+
+func (m *MessageDecoder) decodeConsumerOffset(keyBytes, valueBytes []byte) (*DecodedKeyAndValue, error) {
+	version, err := readRecordVersion(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read consumer offsets key version: %w", err)
+	}
+
+	switch version {
+	case 0, 1:
+		return m.decodeOffsetCommit(keyBytes, valueBytes)
+	case 2:
+		return m.decodeGroupMetadata(keyBytes, valueBytes)
+	default:
+		m.logger.Warn("unknown consumer offsets key version", zap.Int16("version", version))
+		key, err := newUnknownRecordPayload(version, keyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode unknown consumer offsets key: %w", err)
+		}
+		value, err := m.newUnknownValuePayload(valueBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode unknown consumer offsets value: %w", err)
+		}
+		return &DecodedKeyAndValue{Key: key, Value: value}, nil
+	}
+}
+
+func (m *MessageDecoder) decodeOffsetCommit(keyBytes, valueBytes []byte) (*DecodedKeyAndValue, error) {
+	commitKey := kmsg.NewOffsetCommitKey()
+	if err := commitKey.ReadFrom(keyBytes); err != nil {
+		m.logger.Error("failed to read offset commit key", zap.Error(err))
+		return nil, fmt.Errorf("failed to read offset commit key: %w", err)
+	}
+	key, err := newStructuredPayload(
+		offsetCommitKey{
+			Type:      "offsetCommit",
+			Version:   commitKey.Version,
+			Group:     commitKey.Group,
+			Topic:     commitKey.Topic,
+			Partition: commitKey.Partition,
+		},
+		model.ConsumerOffsetPayload,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode offset commit key: %w", err)
+	}
+
+	if len(valueBytes) == 0 {
+		value, err := newStructuredPayload(
+			tombstone{Tombstone: true, Reason: "offset deleted"},
+			model.ConsumerOffsetPayload,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode offset commit tombstone: %w", err)
+		}
+		return &DecodedKeyAndValue{Key: key, Value: value}, nil
+	}
+
+	valueVersion, err := readRecordVersion(valueBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offset commit value version: %w", err)
+	}
+	if valueVersion > offsetCommitValueMaxVersion {
+		value, err := m.newUnknownValuePayload(valueBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode unknown offset commit value: %w", err)
+		}
+		return &DecodedKeyAndValue{Key: key, Value: value}, nil
+	}
+
+	commitValue := kmsg.NewOffsetCommitValue()
+	if err := commitValue.ReadFrom(valueBytes); err != nil {
+		m.logger.Error(
+			"failed to read offset commit value",
+			zap.String("group", commitKey.Group),
+			zap.String("topic", commitKey.Topic),
+			zap.Int32("partition", commitKey.Partition),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to read offset commit value: %w", err)
+	}
+	summary := offsetCommitValue{
+		Version:         commitValue.Version,
+		Offset:          commitValue.Offset,
+		Metadata:        commitValue.Metadata,
+		CommitTimestamp: commitValue.CommitTimestamp,
+	}
+	if commitValue.Version >= 3 {
+		summary.LeaderEpoch = &commitValue.LeaderEpoch
+	}
+	if commitValue.Version == 1 {
+		summary.ExpireTimestamp = &commitValue.ExpireTimestamp
+	}
+	value, err := newStructuredPayload(summary, model.ConsumerOffsetPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode offset commit value: %w", err)
+	}
+	return &DecodedKeyAndValue{Key: key, Value: value}, nil
+}
+
+func (m *MessageDecoder) decodeGroupMetadata(keyBytes, valueBytes []byte) (*DecodedKeyAndValue, error) {
+	metadataKey := kmsg.NewGroupMetadataKey()
+	if err := metadataKey.ReadFrom(keyBytes); err != nil {
+		m.logger.Error("failed to read group metadata key", zap.Error(err))
+		return nil, fmt.Errorf("failed to read group metadata key: %w", err)
+	}
+	key, err := newStructuredPayload(
+		groupMetadataKey{
+			Type:    "groupMetadata",
+			Version: metadataKey.Version,
+			Group:   metadataKey.Group,
+		},
+		model.ConsumerOffsetPayload,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode group metadata key: %w", err)
+	}
+
+	if len(valueBytes) == 0 {
+		value, err := newStructuredPayload(
+			tombstone{Tombstone: true, Reason: "group deleted"},
+			model.ConsumerOffsetPayload,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode group metadata tombstone: %w", err)
+		}
+		return &DecodedKeyAndValue{Key: key, Value: value}, nil
+	}
+
+	valueVersion, err := readRecordVersion(valueBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group metadata value version: %w", err)
+	}
+	if valueVersion > groupMetadataValueMaxVersion {
+		value, err := m.newUnknownValuePayload(valueBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode unknown group metadata value: %w", err)
+		}
+		return &DecodedKeyAndValue{Key: key, Value: value}, nil
+	}
+
+	metadataValue := kmsg.NewGroupMetadataValue()
+	if err := metadataValue.ReadFrom(valueBytes); err != nil {
+		m.logger.Error(
+			"failed to read group metadata value",
+			zap.String("group", metadataKey.Group),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to read group metadata value: %w", err)
+	}
+	value, err := newStructuredPayload(
+		m.summarizeGroupMetadata(metadataKey.Group, &metadataValue),
+		model.ConsumerOffsetPayload,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode group metadata value: %w", err)
+	}
+	return &DecodedKeyAndValue{Key: key, Value: value}, nil
+}
+
+func (m *MessageDecoder) summarizeGroupMetadata(group string, value *kmsg.GroupMetadataValue) groupMetadataValue {
+	summary := groupMetadataValue{
+		Version:      value.Version,
+		ProtocolType: value.ProtocolType,
+		Generation:   value.Generation,
+		Protocol:     value.Protocol,
+		Leader:       value.Leader,
+		Members:      make([]groupMetadataMember, 0, len(value.Members)),
+	}
+	if value.Version >= 2 {
+		summary.CurrentStateTimestamp = &value.CurrentStateTimestamp
+	}
+
+	for i := range value.Members {
+		member := &value.Members[i]
+		memberSummary := groupMetadataMember{
+			MemberID:             member.MemberID,
+			GroupInstanceID:      member.InstanceID,
+			ClientID:             member.ClientID,
+			ClientHost:           member.ClientHost,
+			SessionTimeoutMillis: member.SessionTimeoutMillis,
+		}
+		if value.Version >= 1 {
+			memberSummary.RebalanceTimeoutMillis = &member.RebalanceTimeoutMillis
+		}
+		if value.ProtocolType == consumerProtocolType {
+			subscription, assignment, err := decodeConsumerProtocol(member)
+			if err != nil {
+				m.logger.Warn(
+					"failed to decode consumer protocol metadata for group member",
+					zap.String("group", group),
+					zap.String("member", member.MemberID),
+					zap.Error(err),
+				)
+				memberSummary.RawSubscription = base64.StdEncoding.EncodeToString(member.Subscription)
+				memberSummary.RawAssignment = base64.StdEncoding.EncodeToString(member.Assignment)
+			} else {
+				memberSummary.Subscription = subscription
+				memberSummary.Assignment = assignment
+			}
+		} else {
+			memberSummary.RawSubscription = base64.StdEncoding.EncodeToString(member.Subscription)
+			memberSummary.RawAssignment = base64.StdEncoding.EncodeToString(member.Assignment)
+		}
+		summary.Members = append(summary.Members, memberSummary)
+	}
+	return summary
+}
+
+func decodeConsumerProtocol(member *kmsg.GroupMetadataValueMember) (*consumerSubscription, []topicPartitions, error) {
+	var subscription *consumerSubscription
+	if len(member.Subscription) > 0 {
+		metadata := kmsg.NewConsumerMemberMetadata()
+		if err := metadata.ReadFrom(member.Subscription); err != nil {
+			return nil, nil, fmt.Errorf("failed to read subscription: %w", err)
+		}
+		subscription = &consumerSubscription{
+			Topics: metadata.Topics,
+			Rack:   metadata.Rack,
+		}
+		for _, owned := range metadata.OwnedPartitions {
+			subscription.OwnedPartitions = append(subscription.OwnedPartitions, topicPartitions{
+				Topic:      owned.Topic,
+				Partitions: owned.Partitions,
+			})
+		}
+	}
+
+	var assignment []topicPartitions
+	if len(member.Assignment) > 0 {
+		memberAssignment := kmsg.NewConsumerMemberAssignment()
+		if err := memberAssignment.ReadFrom(member.Assignment); err != nil {
+			return nil, nil, fmt.Errorf("failed to read assignment: %w", err)
+		}
+		for _, topic := range memberAssignment.Topics {
+			assignment = append(assignment, topicPartitions{
+				Topic:      topic.Topic,
+				Partitions: topic.Partitions,
+			})
+		}
+	}
+	return subscription, assignment, nil
+}
+
+func (m *MessageDecoder) newUnknownValuePayload(valueBytes []byte) (*DecodedPayload, error) {
+	if len(valueBytes) == 0 {
+		return newStructuredPayload(
+			tombstone{Tombstone: true, Reason: "record deleted"},
+			model.ConsumerOffsetPayload,
+		)
+	}
+	version, err := readRecordVersion(valueBytes)
+	if err != nil {
+		return nil, err
+	}
+	m.logger.Warn("unknown consumer offsets value version", zap.Int16("version", version))
+	return newUnknownRecordPayload(version, valueBytes)
+}
+
+func newUnknownRecordPayload(version int16, raw []byte) (*DecodedPayload, error) {
+	return newStructuredPayload(
+		unknownRecord{
+			Version: version,
+			Unknown: true,
+			Raw:     base64.StdEncoding.EncodeToString(raw),
+		},
+		model.ConsumerOffsetPayload,
+	)
+}
+
+func readRecordVersion(data []byte) (int16, error) {
+	if len(data) < 2 {
+		return 0, fmt.Errorf("record is too short to contain a version: %d bytes", len(data))
+	}
+	return int16(binary.BigEndian.Uint16(data[:2])), nil
+}
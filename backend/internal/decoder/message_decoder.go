@@ -9,27 +9,30 @@ import (
 	"github.com/Avi18971911/kafka-window/backend/internal/avro"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
 	"github.com/linkedin/goavro/v2"
-	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
 	"unicode"
 	"unicode/utf8"
 )
 
 type Encoding string
 
-const consumerOffsetEncoding = "__consumer_offsets"
+const consumerOffsetsTopic = "__consumer_offsets"
 
 const (
-	JSON           Encoding = "json"
-	PlainText      Encoding = "plainText"
-	Base64         Encoding = "base64"
-	Avro           Encoding = "avro"
-	ConsumerOffset Encoding = "consumerOffset"
+	JSON        Encoding = "json"
+	PlainText   Encoding = "plainText"
+	Base64      Encoding = "base64"
+	Avro        Encoding = "avro"
+	MessagePack Encoding = "messagePack"
+	CBOR        Encoding = "cbor"
+	BSON        Encoding = "bson"
 )
 
 type DecodedPayload struct {
 	Payload     string
 	JSONPayload model.JSONValue
 	Type        model.PayloadType
+	Compression []model.CompressionType
 }
 
 type DecodedKeyAndValue struct {
@@ -39,51 +42,81 @@ type DecodedKeyAndValue struct {
 
 type MessageDecoder struct {
 	avroService *avro.AvroService
+	logger      *zap.Logger
 }
 
-func NewMessageDecoder(avroService *avro.AvroService) *MessageDecoder {
+func NewMessageDecoder(avroService *avro.AvroService, logger *zap.Logger) *MessageDecoder {
 	return &MessageDecoder{
 		avroService: avroService,
+		logger:      logger,
 	}
 }
 
 func (m *MessageDecoder) DecodeKeyAndValue(topic string, keyBytes, valueBytes []byte) (*DecodedKeyAndValue, error) {
-	keyEncoding, err := m.getEncodingType(topic, keyBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get key encoding: %w", err)
-	}
-
-	valueEncoding, err := m.getEncodingType(topic, valueBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get value encoding: %w", err)
-	}
-
-	if keyEncoding == ConsumerOffset || valueEncoding == ConsumerOffset {
-		if keyEncoding != ConsumerOffset || valueEncoding != ConsumerOffset {
-			return nil, fmt.Errorf(
-				"key and value must both be consumer offset, not one or the other",
-			)
-		}
+	if topic == consumerOffsetsTopic {
 		keyAndValue, err := m.decodeConsumerOffset(keyBytes, valueBytes)
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode consumer offset: %w", err)
 		}
 		return keyAndValue, nil
-	} else {
-		keyDecoded, err := m.decodeMessage(keyBytes, keyEncoding)
+	}
+
+	if topic == transactionStateTopic {
+		keyAndValue, err := m.decodeTransactionState(keyBytes, valueBytes)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode key: %w", err)
+			return nil, fmt.Errorf("failed to decode transaction state: %w", err)
 		}
+		return keyAndValue, nil
+	}
 
-		valueDecoded, err := m.decodeMessage(valueBytes, valueEncoding)
+	if connectTopicType := GetConnectTopicType(topic); connectTopicType != ConnectTopicNone {
+		keyAndValue, err := m.decodeConnectRecord(topic, connectTopicType, keyBytes, valueBytes)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode value: %w", err)
+			return nil, fmt.Errorf("failed to decode Kafka Connect record: %w", err)
 		}
-		return &DecodedKeyAndValue{
-			Key:   keyDecoded,
-			Value: valueDecoded,
-		}, nil
+		return keyAndValue, nil
+	}
+
+	keyDecoded, err := m.decodePayload(topic, keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", err)
+	}
+
+	valueDecoded, err := m.decodePayload(topic, valueBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
 	}
+	return &DecodedKeyAndValue{
+		Key:   keyDecoded,
+		Value: valueDecoded,
+	}, nil
+}
+
+// decodePayload decodes a key or value. Null and empty keys and values, such as the value of a tombstone, have no
+// encoding and are returned as null payloads.
+func (m *MessageDecoder) decodePayload(topic string, raw []byte) (*DecodedPayload, error) {
+	if len(raw) == 0 {
+		return &DecodedPayload{Type: model.NullPayload}, nil
+	}
+	decompressed, compression, err := m.decompress(raw)
+	if err != nil {
+		// A compression magic number can also start an uncompressed payload, such as a BSON document whose length
+		// prefix is 1f 8b, so the payload is decoded as it was received rather than dropped
+		m.logger.Debug("Failed to decompress payload, decoding it as is", zap.String("topic", topic), zap.Error(err))
+		decompressed, compression = raw, nil
+	}
+
+	encoding, err := m.getEncodingType(topic, decompressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encoding: %w", err)
+	}
+
+	decoded, err := m.decodeMessage(decompressed, encoding)
+	if err != nil {
+		return nil, err
+	}
+	decoded.Compression = compression
+	return decoded, nil
 }
 
 func (m *MessageDecoder) decodeMessage(value []byte, encoding Encoding) (*DecodedPayload, error) {
@@ -126,6 +159,36 @@ func (m *MessageDecoder) decodeMessage(value []byte, encoding Encoding) (*Decode
 			JSONPayload: decodedResult,
 			Type:        model.JSONPayload,
 		}, nil
+	case MessagePack:
+		stringResult, decodedResult, err := m.decodeMessagePack(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode MessagePack: %w", err)
+		}
+		return &DecodedPayload{
+			Payload:     stringResult,
+			JSONPayload: decodedResult,
+			Type:        model.JSONPayload,
+		}, nil
+	case CBOR:
+		stringResult, decodedResult, err := m.decodeCBOR(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode CBOR: %w", err)
+		}
+		return &DecodedPayload{
+			Payload:     stringResult,
+			JSONPayload: decodedResult,
+			Type:        model.JSONPayload,
+		}, nil
+	case BSON:
+		stringResult, decodedResult, err := m.decodeBSON(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode BSON: %w", err)
+		}
+		return &DecodedPayload{
+			Payload:     stringResult,
+			JSONPayload: decodedResult,
+			Type:        model.JSONPayload,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
 	}
@@ -190,8 +253,8 @@ func (m *MessageDecoder) getEncodingType(topic string, rawMessage []byte) (Encod
 		return "", fmt.Errorf("empty message")
 	}
 
-	if topic == consumerOffsetEncoding {
-		return ConsumerOffset, nil
+	if isBSON(rawMessage) {
+		return BSON, nil
 	}
 
 	if rawMessage[0] == 0 && len(rawMessage) >= 5 {
@@ -208,6 +271,14 @@ func (m *MessageDecoder) getEncodingType(topic string, rawMessage []byte) (Encod
 		return PlainText, nil
 	}
 
+	if isMessagePack(rawMessage) {
+		return MessagePack, nil
+	}
+
+	if isCBOR(rawMessage) {
+		return CBOR, nil
+	}
+
 	if isValidBase64(trimmed) {
 		return Base64, nil
 	}
@@ -233,95 +304,3 @@ func isValidBase64(data []byte) bool {
 	// Heuristic: if decoding worked but result is garbage, it's not really base64
 	return len(decoded) > 0 && utf8.Valid(decoded)
 }
-
-func (m *MessageDecoder) decodeConsumerOffset(keyBytes, valueBytes []byte) (*DecodedKeyAndValue, error) {
-	if keyBytes == nil {
-		return nil, nil
-	}
-
-	keyReader := bytes.NewReader(keyBytes)
-
-	var version int16
-	if err := binary.Read(keyReader, binary.BigEndian, &version); err != nil {
-		return nil, fmt.Errorf("failed to read key: %w", err)
-	}
-
-	msg := &DecodedKeyAndValue{}
-	msg.Key = &DecodedPayload{}
-	msg.Value = &DecodedPayload{}
-
-	switch version {
-	case 0, 1: // Offset Commit Key
-		offsetCommitKey := kmsg.NewOffsetCommitKey()
-		err := offsetCommitKey.ReadFrom(keyBytes)
-		if err == nil {
-			key, _ := json.Marshal(offsetCommitKey)
-			msg.Key.Payload = string(key)
-			jsonVal, err := parseString(msg.Key.Payload)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse consumer offsets key as JSON: %w", err)
-			}
-			msg.Key.JSONPayload = jsonVal
-			msg.Key.Type = model.ConsumerOffsetPayload
-		} else {
-			fmt.Println("Failed to read key:", err)
-			return nil, fmt.Errorf("failed to read key: %w", err)
-		}
-
-		if valueBytes == nil {
-			break
-		}
-		offsetCommitValue := kmsg.NewOffsetCommitValue()
-		err = offsetCommitValue.ReadFrom(valueBytes)
-		if err == nil {
-			val, _ := json.Marshal(offsetCommitValue)
-			msg.Value.Payload = string(val)
-			jsonVal, err := parseString(msg.Value.Payload)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse consumer offsets value as JSON: %w", err)
-			}
-			msg.Value.JSONPayload = jsonVal
-			msg.Value.Type = model.ConsumerOffsetPayload
-		} else {
-			fmt.Println("Failed to read value:", err)
-			return nil, fmt.Errorf("failed to read value: %w", err)
-		}
-
-	case 2: // Group Metadata Key
-		metadataKey := kmsg.NewGroupMetadataKey()
-		err := metadataKey.ReadFrom(keyBytes)
-		if err == nil {
-			key, _ := json.Marshal(metadataKey)
-			msg.Key.Payload = string(key)
-			jsonVal, err := parseString(msg.Key.Payload)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse consumer offsets key as JSON: %w", err)
-			}
-			msg.Key.JSONPayload = jsonVal
-			msg.Key.Type = model.ConsumerOffsetPayload
-		}
-
-		if valueBytes == nil {
-			break
-		}
-		metadataValue := kmsg.NewGroupMetadataValue()
-		err = metadataValue.ReadFrom(valueBytes)
-		if err == nil {
-			val, _ := json.Marshal(metadataValue)
-			msg.Value.Payload = string(val)
-			jsonVal, err := parseString(msg.Value.Payload)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse consumer offsets value as JSON: %w", err)
-			}
-			msg.Value.JSONPayload = jsonVal
-			msg.Value.Type = model.ConsumerOffsetPayload
-		} else {
-			fmt.Println("Failed to read value:", err)
-			return nil, fmt.Errorf("failed to read value: %w", err)
-		}
-	default:
-		fmt.Println("Unknown key version:", version)
-	}
-
-	return msg, nil
-}
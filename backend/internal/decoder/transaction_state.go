@@ -0,0 +1,111 @@
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+const transactionStateTopic = "__transaction_state"
+
+type transactionStateKey struct {
+	Version         int16  `json:"version"`
+	TransactionalID string `json:"transactionalId"`
+}
+
+type transactionStateValue struct {
+	Version             int16                        `json:"version"`
+	ProducerID          int64                        `json:"producerId"`
+	ProducerEpoch       int16                        `json:"producerEpoch"`
+	TimeoutMillis       int32                        `json:"timeoutMillis"`
+	State               string                       `json:"state"`
+	Partitions          []transactionStatePartitions `json:"partitions"`
+	LastUpdateTimestamp int64                        `json:"lastUpdateTimestamp"`
+	StartTimestamp      int64                        `json:"startTimestamp"`
+}
+
+type transactionStatePartitions struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"`
+}
+
+type tombstone struct {
+	Tombstone bool   `json:"tombstone"`
+	Reason    string `json:"reason"`
+}
+
+func (m *MessageDecoder) decodeTransactionState(keyBytes, valueBytes []byte) (*DecodedKeyAndValue, error) {
+	txnKey := kmsg.NewTxnMetadataKey()
+	if err := txnKey.ReadFrom(keyBytes); err != nil {
+		return nil, fmt.Errorf("failed to read transaction state key: %w", err)
+	}
+	key, err := newStructuredPayload(
+		transactionStateKey{
+			Version:         txnKey.Version,
+			TransactionalID: txnKey.TransactionalID,
+		},
+		model.TransactionStatePayload,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction state key: %w", err)
+	}
+
+	if len(valueBytes) == 0 {
+		value, err := newStructuredPayload(
+			tombstone{Tombstone: true, Reason: "transactional ID expired"},
+			model.TransactionStatePayload,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transaction state tombstone: %w", err)
+		}
+		return &DecodedKeyAndValue{Key: key, Value: value}, nil
+	}
+
+	txnValue := kmsg.NewTxnMetadataValue()
+	if err := txnValue.ReadFrom(valueBytes); err != nil {
+		return nil, fmt.Errorf("failed to read transaction state value: %w", err)
+	}
+	partitions := make([]transactionStatePartitions, 0, len(txnValue.Topics))
+	for _, topic := range txnValue.Topics {
+		partitions = append(partitions, transactionStatePartitions{
+			Topic:      topic.Topic,
+			Partitions: topic.Partitions,
+		})
+	}
+	value, err := newStructuredPayload(
+		transactionStateValue{
+			Version:             txnValue.Version,
+			ProducerID:          txnValue.ProducerID,
+			ProducerEpoch:       txnValue.ProducerEpoch,
+			TimeoutMillis:       txnValue.TimeoutMillis,
+			State:               txnValue.State.String(),
+			Partitions:          partitions,
+			LastUpdateTimestamp: txnValue.LastUpdateTimestamp,
+			StartTimestamp:      txnValue.StartTimestamp,
+		},
+		model.TransactionStatePayload,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction state value: %w", err)
+	}
+	return &DecodedKeyAndValue{Key: key, Value: value}, nil
+}
+
+// newStructuredPayload renders a decoded internal record as both its JSON string and its parsed model.JSONValue.
+func newStructuredPayload(record interface{}, payloadType model.PayloadType) (*DecodedPayload, error) {
+	jsonBytes, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	jsonString := string(jsonBytes)
+	parsedJSON, err := parseString(jsonString)
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedPayload{
+		Payload:     jsonString,
+		JSONPayload: parsedJSON,
+		Type:        payloadType,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column is a CSV column. Field is either one of the record fields (topic, partition, offset, timestamp, key,
+// value, headers) or a JSON path into the decoded value such as $.order.id.
+type Column struct {
+	Header string
+	Field  string
+}
+
+var DefaultColumns = []Column{
+	{Header: "partition", Field: "partition"},
+	{Header: "offset", Field: "offset"},
+	{Header: "timestamp", Field: "timestamp"},
+	{Header: "key", Field: "key"},
+	{Header: "value", Field: "value"},
+}
+
+type csvColumn struct {
+	Column
+	path jsonvalue.Path
+}
+
+type csvWriter struct {
+	writer        *csv.Writer
+	columns       []csvColumn
+	headerWritten bool
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	compiled := make([]csvColumn, len(columns))
+	for i, column := range columns {
+		compiled[i] = csvColumn{Column: column}
+		if compiled[i].Header == "" {
+			compiled[i].Header = column.Field
+		}
+		if strings.HasPrefix(column.Field, "$") {
+			path, err := jsonvalue.ParsePath(column.Field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid column %q: %w", column.Field, err)
+			}
+			compiled[i].path = path
+		} else if !isRecordField(column.Field) {
+			return nil, fmt.Errorf("unknown column field %q", column.Field)
+		}
+	}
+	return &csvWriter{
+		writer:  csv.NewWriter(w),
+		columns: compiled,
+	}, nil
+}
+
+func (c *csvWriter) Write(record *model.Record) error {
+	if !c.headerWritten {
+		header := make([]string, len(c.columns))
+		for i, column := range c.columns {
+			header[i] = column.Header
+		}
+		if err := c.writer.Write(header); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	row := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		cell, err := column.extract(record)
+		if err != nil {
+			return fmt.Errorf("failed to extract column %q: %w", column.Header, err)
+		}
+		row[i] = cell
+	}
+	if err := c.writer.Write(row); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func isRecordField(field string) bool {
+	switch field {
+	case "topic", "partition", "offset", "timestamp", "key", "value", "headers":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *csvColumn) extract(record *model.Record) (string, error) {
+	if c.path != nil {
+		if record.Message == nil {
+			return "", nil
+		}
+		value, ok := c.path.Lookup(record.Message.ValueJsonPayload)
+		if !ok {
+			return "", nil
+		}
+		return jsonvalue.ToText(*value)
+	}
+
+	switch c.Field {
+	case "topic":
+		return record.Topic, nil
+	case "partition":
+		return strconv.FormatInt(int64(record.Partition), 10), nil
+	case "offset":
+		return strconv.FormatInt(record.Offset, 10), nil
+	case "timestamp":
+		return record.Timestamp.UTC().Format(time.RFC3339Nano), nil
+	case "key":
+		if record.Message == nil {
+			return base64.StdEncoding.EncodeToString(record.Key), nil
+		}
+		return record.Message.Key, nil
+	case "value":
+		if record.Message == nil {
+			return base64.StdEncoding.EncodeToString(record.Value), nil
+		}
+		return record.Message.Value, nil
+	case "headers":
+		headers := make([]string, len(record.Headers))
+		for i, header := range record.Headers {
+			headers[i] = header.Key + "=" + string(header.Value)
+		}
+		return strings.Join(headers, ";"), nil
+	default:
+		return "", fmt.Errorf("unknown column field %q", c.Field)
+	}
+}
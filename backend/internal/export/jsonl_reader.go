@@ -0,0 +1,95 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"io"
+	"time"
+)
+
+// maxJSONLLineSize bounds a single line of a JSONL import, which is far above Kafka's default message size limit.
+const maxJSONLLineSize = 16 * 1024 * 1024
+
+// jsonlImportLine is the subset of JSONLRecord needed to reproduce a record. The raw base64 bytes are preferred;
+// without them the decoded key and value text is produced as is, which is only exact for JSON and plain text.
+type jsonlImportLine struct {
+	Topic       string               `json:"topic"`
+	Partition   int32                `json:"partition"`
+	Offset      int64                `json:"offset"`
+	Timestamp   time.Time            `json:"timestamp"`
+	Key         *string              `json:"key"`
+	Value       *string              `json:"value"`
+	Headers     []model.RecordHeader `json:"headers"`
+	KeyBase64   *string              `json:"keyBase64"`
+	ValueBase64 *string              `json:"valueBase64"`
+}
+
+// JSONLReader reads the records of a JSONL export.
+type JSONLReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+	return &JSONLReader{scanner: scanner}
+}
+
+// Read returns the next record, skipping blank lines, or io.EOF at the end of the input.
+func (j *JSONLReader) Read() (*model.Record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		data := bytes.TrimSpace(j.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		record, err := parseJSONLLine(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid record on line %d: %w", j.line, err)
+		}
+		return record, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read line %d: %w", j.line+1, err)
+	}
+	return nil, io.EOF
+}
+
+func parseJSONLLine(data []byte) (*model.Record, error) {
+	var line jsonlImportLine
+	if err := json.Unmarshal(data, &line); err != nil {
+		return nil, err
+	}
+	key, err := recordBytes(line.KeyBase64, line.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyBase64: %w", err)
+	}
+	value, err := recordBytes(line.ValueBase64, line.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid valueBase64: %w", err)
+	}
+	return &model.Record{
+		Topic:     line.Topic,
+		Partition: line.Partition,
+		Offset:    line.Offset,
+		Timestamp: line.Timestamp,
+		Key:       key,
+		Value:     value,
+		Headers:   line.Headers,
+	}, nil
+}
+
+func recordBytes(encoded *string, text *string) ([]byte, error) {
+	if encoded != nil {
+		return base64.StdEncoding.DecodeString(*encoded)
+	}
+	if text != nil {
+		return []byte(*text), nil
+	}
+	return nil, nil
+}
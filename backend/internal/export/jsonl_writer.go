@@ -0,0 +1,86 @@
+package export
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"io"
+	"time"
+)
+
+// JSONLRecord is one line of a JSONL export. Key and Value hold the decoded payloads, and are null for null keys
+// and values, while KeyBase64 and ValueBase64 carry the exact bytes when raw bytes are requested.
+type JSONLRecord struct {
+	Topic            string               `json:"topic"`
+	Partition        int32                `json:"partition"`
+	Offset           int64                `json:"offset"`
+	Timestamp        time.Time            `json:"timestamp"`
+	Key              *string              `json:"key"`
+	KeyPayloadType   model.PayloadType    `json:"keyPayloadType,omitempty"`
+	KeyJSON          interface{}          `json:"keyJson,omitempty"`
+	Value            *string              `json:"value"`
+	ValuePayloadType model.PayloadType    `json:"valuePayloadType,omitempty"`
+	ValueJSON        interface{}          `json:"valueJson,omitempty"`
+	Headers          []model.RecordHeader `json:"headers,omitempty"`
+	KeyBase64        *string              `json:"keyBase64,omitempty"`
+	ValueBase64      *string              `json:"valueBase64,omitempty"`
+}
+
+type jsonlWriter struct {
+	encoder         *json.Encoder
+	includeRawBytes bool
+}
+
+func newJSONLWriter(w io.Writer, includeRawBytes bool) *jsonlWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &jsonlWriter{
+		encoder:         encoder,
+		includeRawBytes: includeRawBytes,
+	}
+}
+
+func (j *jsonlWriter) Write(record *model.Record) error {
+	line := JSONLRecord{
+		Topic:     record.Topic,
+		Partition: record.Partition,
+		Offset:    record.Offset,
+		Timestamp: record.Timestamp,
+		Headers:   record.Headers,
+	}
+	if message := record.Message; message != nil {
+		if record.Key != nil {
+			line.Key = &message.Key
+		}
+		line.KeyPayloadType = message.KeyPayloadType
+		if message.KeyJsonPayload != nil {
+			line.KeyJSON = jsonvalue.ToNative(*message.KeyJsonPayload)
+		}
+		if record.Value != nil {
+			line.Value = &message.Value
+		}
+		line.ValuePayloadType = message.ValuePayloadType
+		if message.ValueJsonPayload != nil {
+			line.ValueJSON = jsonvalue.ToNative(*message.ValueJsonPayload)
+		}
+	}
+	if j.includeRawBytes || record.Message == nil {
+		line.KeyBase64 = encodeBase64(record.Key)
+		line.ValueBase64 = encodeBase64(record.Value)
+	}
+	return j.encoder.Encode(line)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
+
+// encodeBase64 keeps null keys and values distinguishable from empty ones.
+func encodeBase64(data []byte) *string {
+	if data == nil {
+		return nil
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	return &encoded
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"io"
+)
+
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+	FormatAvro  Format = "avro"
+)
+
+// Writer serializes records one at a time. Close must be called to flush buffered output; it does not close
+// the underlying io.Writer.
+type Writer interface {
+	Write(record *model.Record) error
+	Close() error
+}
+
+type Options struct {
+	// Columns of a CSV export. Defaults to DefaultColumns
+	Columns []Column
+	// Whether JSONL exports also carry the raw key and value as base64
+	IncludeRawBytes bool
+}
+
+func NewWriter(format Format, w io.Writer, options Options) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLWriter(w, options.IncludeRawBytes), nil
+	case FormatCSV:
+		columns := options.Columns
+		if len(columns) == 0 {
+			columns = DefaultColumns
+		}
+		return newCSVWriter(w, columns)
+	case FormatAvro:
+		return newAvroWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func ContentType(format Format) string {
+	switch format {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv"
+	case FormatAvro:
+		return "application/avro"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func FileExtension(format Format) string {
+	switch format {
+	case FormatJSONL:
+		return ".jsonl"
+	case FormatCSV:
+		return ".csv"
+	case FormatAvro:
+		return ".avro"
+	default:
+		return ".bin"
+	}
+}
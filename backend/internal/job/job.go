@@ -0,0 +1,86 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+type Job struct {
+	ID   string `json:"id" validate:"required"`
+	Kind string `json:"kind" validate:"required"`
+	// A human readable summary of what the job does
+	Description string `json:"description" validate:"required"`
+	// Who submitted the job, when authentication is enabled
+	Owner  string `json:"owner,omitempty"`
+	Status Status `json:"status" validate:"required"`
+	// Counters reported by the job while it runs, such as the number of records copied so far
+	Progress map[string]int64 `json:"progress" validate:"required"`
+	// The outcome of the job, specific to its kind. Failed jobs may report how far they got
+	Result interface{} `json:"result,omitempty"`
+	// The name of the file the job produced, which can be downloaded from /jobs/{id}/file
+	FileName string `json:"fileName,omitempty"`
+	// Why the job failed
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" validate:"required"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCanceled
+}
+
+// Func is the work of a job. It must return once ctx is canceled.
+type Func func(ctx context.Context, run *Run) error
+
+// Run lets a running job report on itself.
+type Run struct {
+	manager *Manager
+	id      string
+}
+
+// ID returns the ID of the job.
+func (r *Run) ID() string {
+	return r.id
+}
+
+// Report replaces the given progress counters, leaving the others as they are.
+func (r *Run) Report(progress map[string]int64) {
+	r.manager.update(r.id, func(job *Job) {
+		for key, value := range progress {
+			job.Progress[key] = value
+		}
+	})
+}
+
+// SetResult records the outcome of the job.
+func (r *Run) SetResult(result interface{}) {
+	r.manager.update(r.id, func(job *Job) {
+		job.Result = result
+	})
+}
+
+// CreateFile creates the file the job produces, which is offered for download as name. It is deleted along with
+// the job once the job leaves the history.
+func (r *Run) CreateFile(name string) (*os.File, error) {
+	file, err := os.Create(r.manager.filePath(r.id, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job file: %w", err)
+	}
+	r.manager.update(r.id, func(job *Job) {
+		job.FileName = name
+	})
+	return file, nil
+}
@@ -0,0 +1,278 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const interruptedError = "interrupted by a restart of the server"
+
+type Config struct {
+	// The number of jobs that run at the same time. Further jobs wait in the queue
+	MaxConcurrent int
+	// The number of finished jobs that are kept
+	HistorySize int
+	// Where jobs keep the files they produce
+	FileDir string
+}
+
+// Manager runs jobs in the background with bounded concurrency and keeps their history, so that clients can
+// poll, cancel and list them.
+type Manager struct {
+	ctx          context.Context
+	config       Config
+	store        Store
+	slots        chan struct{}
+	jobs         map[string]*managedJob
+	order        []string
+	mutex        sync.RWMutex
+	persistMutex sync.Mutex
+	running      sync.WaitGroup
+	logger       *zap.Logger
+}
+
+type managedJob struct {
+	job    Job
+	cancel context.CancelFunc
+}
+
+// NewManager restores the history of store. Jobs that were still queued or running when the server stopped are
+// marked as failed. All jobs are canceled once ctx is done.
+func NewManager(ctx context.Context, store Store, config Config, logger *zap.Logger) (*Manager, error) {
+	if config.MaxConcurrent <= 0 {
+		return nil, fmt.Errorf("max concurrent jobs must be positive, got %d", config.MaxConcurrent)
+	}
+	if err := os.MkdirAll(config.FileDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job file directory: %w", err)
+	}
+	history, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		ctx:    ctx,
+		config: config,
+		store:  store,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+		jobs:   make(map[string]*managedJob, len(history)),
+		logger: logger,
+	}
+	for _, job := range history {
+		if !job.IsFinished() {
+			finishedAt := time.Now()
+			job.Status = StatusFailed
+			job.Error = interruptedError
+			job.FinishedAt = &finishedAt
+		}
+		if job.Progress == nil {
+			job.Progress = make(map[string]int64)
+		}
+		m.jobs[job.ID] = &managedJob{job: job}
+		m.order = append(m.order, job.ID)
+	}
+	m.prune()
+	m.persist()
+	return m, nil
+}
+
+// Submit queues run and returns the job tracking it.
+func (m *Manager) Submit(kind string, description string, run Func) Job {
+	return m.SubmitAs("", kind, description, run)
+}
+
+// SubmitAs is Submit for a job that owner submitted.
+func (m *Manager) SubmitAs(owner string, kind string, description string, run Func) Job {
+	jobCtx, cancel := context.WithCancel(m.ctx)
+	managed := &managedJob{
+		job: Job{
+			ID:          uuid.NewString(),
+			Kind:        kind,
+			Description: description,
+			Owner:       owner,
+			Status:      StatusQueued,
+			Progress:    make(map[string]int64),
+			CreatedAt:   time.Now(),
+		},
+		cancel: cancel,
+	}
+	m.mutex.Lock()
+	m.jobs[managed.job.ID] = managed
+	m.order = append(m.order, managed.job.ID)
+	snapshot := managed.snapshot()
+	m.mutex.Unlock()
+	m.persist()
+
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		defer cancel()
+		select {
+		case m.slots <- struct{}{}:
+		case <-jobCtx.Done():
+			m.finish(managed.job.ID, jobCtx, jobCtx.Err())
+			return
+		}
+		defer func() { <-m.slots }()
+		if jobCtx.Err() != nil {
+			// Canceled while a slot was freed up at the same time
+			m.finish(managed.job.ID, jobCtx, jobCtx.Err())
+			return
+		}
+
+		m.update(managed.job.ID, func(job *Job) {
+			startedAt := time.Now()
+			job.Status = StatusRunning
+			job.StartedAt = &startedAt
+		})
+		m.persist()
+		err := run(jobCtx, &Run{manager: m, id: managed.job.ID})
+		m.finish(managed.job.ID, jobCtx, err)
+	}()
+	return snapshot
+}
+
+func (m *Manager) finish(id string, jobCtx context.Context, err error) {
+	m.update(id, func(job *Job) {
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+		switch {
+		case err == nil:
+			job.Status = StatusSucceeded
+		case jobCtx.Err() != nil:
+			job.Status = StatusCanceled
+		default:
+			job.Status = StatusFailed
+			job.Error = err.Error()
+			m.logger.Error(
+				"job failed",
+				zap.String("id", job.ID),
+				zap.String("kind", job.Kind),
+				zap.Error(err),
+			)
+		}
+	})
+	m.prune()
+	m.persist()
+}
+
+func (m *Manager) update(id string, apply func(job *Job)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if managed, ok := m.jobs[id]; ok {
+		apply(&managed.job)
+	}
+}
+
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	managed, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return managed.snapshot(), true
+}
+
+// List returns the jobs, newest first. Empty filters match every job.
+func (m *Manager) List(kind string, status Status) []Job {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	jobs := make([]Job, 0, len(m.order))
+	for _, id := range slices.Backward(m.order) {
+		job := m.jobs[id].job
+		if (kind == "" || job.Kind == kind) && (status == "" || job.Status == status) {
+			jobs = append(jobs, m.jobs[id].snapshot())
+		}
+	}
+	return jobs
+}
+
+// Cancel asks a job to stop. Queued jobs are canceled right away, running jobs once they have returned.
+func (m *Manager) Cancel(id string) (Job, bool) {
+	m.mutex.RLock()
+	managed, ok := m.jobs[id]
+	m.mutex.RUnlock()
+	if !ok {
+		return Job{}, false
+	}
+	if managed.cancel != nil {
+		managed.cancel()
+	}
+	return m.Get(id)
+}
+
+// File returns the job and where the file it produced is stored.
+func (m *Manager) File(id string) (Job, string, bool) {
+	job, ok := m.Get(id)
+	if !ok || job.FileName == "" {
+		return Job{}, "", false
+	}
+	return job, m.filePath(id, job.FileName), true
+}
+
+func (m *Manager) filePath(id string, name string) string {
+	return filepath.Join(m.config.FileDir, id+"-"+filepath.Base(name))
+}
+
+// Wait blocks until every submitted job has finished.
+func (m *Manager) Wait() {
+	m.running.Wait()
+}
+
+// prune drops the oldest finished jobs beyond the history size, along with their files.
+func (m *Manager) prune() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	finished := 0
+	for _, id := range m.order {
+		if m.jobs[id].job.IsFinished() {
+			finished++
+		}
+	}
+	kept := m.order[:0]
+	for _, id := range m.order {
+		job := m.jobs[id].job
+		if finished > m.config.HistorySize && job.IsFinished() {
+			finished--
+			delete(m.jobs, id)
+			if job.FileName != "" {
+				err := os.Remove(m.filePath(id, job.FileName))
+				if err != nil && !os.IsNotExist(err) {
+					m.logger.Warn("failed to remove job file", zap.String("id", id), zap.Error(err))
+				}
+			}
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
+
+func (m *Manager) persist() {
+	m.persistMutex.Lock()
+	defer m.persistMutex.Unlock()
+	m.mutex.RLock()
+	jobs := make([]Job, len(m.order))
+	for i, id := range m.order {
+		jobs[i] = m.jobs[id].snapshot()
+	}
+	m.mutex.RUnlock()
+	if err := m.store.Save(jobs); err != nil {
+		m.logger.Error("failed to persist job history", zap.Error(err))
+	}
+}
+
+func (j *managedJob) snapshot() Job {
+	job := j.job
+	job.Progress = maps.Clone(j.job.Progress)
+	return job
+}
@@ -0,0 +1,63 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store persists the job history, so that it survives restarts.
+type Store interface {
+	Load() ([]Job, error)
+	Save(jobs []Job) error
+}
+
+// FileStore keeps the job history in a single JSON file, which is replaced atomically on every save.
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load returns the saved jobs, or none if nothing was saved yet.
+func (f *FileStore) Load() ([]Job, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job history: %w", err)
+	}
+	var jobs []Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse job history: %w", err)
+	}
+	return jobs, nil
+}
+
+func (f *FileStore) Save(jobs []Job) error {
+	data, err := json.Marshal(jobs)
+	if err != nil {
+		return fmt.Errorf("failed to encode job history: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create job history file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write job history: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write job history: %w", err)
+	}
+	if err := os.Rename(temp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace job history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package jsonvalue
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"hash"
+	"hash/fnv"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Differ compares JSON values structurally, leaving out the values at ignored paths. Object fields are compared
+// regardless of their order, and array items by their index.
+type Differ struct {
+	ignore []string
+}
+
+func NewDiffer(ignorePaths []string) (*Differ, error) {
+	ignore := make([]string, 0, len(ignorePaths))
+	for _, ignorePath := range ignorePaths {
+		path, err := ParsePath(ignorePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return nil, fmt.Errorf("ignored path %q must not be the whole document", ignorePath)
+		}
+		ignore = append(ignore, path.String())
+	}
+	return &Differ{ignore: ignore}, nil
+}
+
+// Hash returns a hash of value that is the same for every value Diff finds no changes with.
+func (d *Differ) Hash(value model.JSONValue) uint64 {
+	hasher := fnv.New64a()
+	d.hash(hasher, Path{}, value)
+	return hasher.Sum64()
+}
+
+func (d *Differ) hash(hasher hash.Hash64, path Path, value model.JSONValue) {
+	var buffer [8]byte
+	switch {
+	case value.StringVal != nil:
+		hasher.Write([]byte{'s'})
+		writeString(hasher, *value.StringVal)
+	case value.NumberVal != nil:
+		hasher.Write([]byte{'n'})
+		binary.BigEndian.PutUint64(buffer[:], math.Float64bits(*value.NumberVal))
+		hasher.Write(buffer[:])
+	case value.BoolVal != nil:
+		hasher.Write([]byte{'b', strconv.FormatBool(*value.BoolVal)[0]})
+	case value.ObjectVal != nil:
+		hasher.Write([]byte{'o'})
+		for _, field := range slices.Sorted(maps.Keys(value.ObjectVal)) {
+			fieldPath := path.child(PathSegment{Field: field})
+			if d.isIgnored(fieldPath) {
+				continue
+			}
+			writeString(hasher, field)
+			d.hash(hasher, fieldPath, value.ObjectVal[field])
+		}
+		hasher.Write([]byte{'}'})
+	case value.ArrayVal != nil:
+		hasher.Write([]byte{'a'})
+		for i, item := range value.ArrayVal {
+			itemPath := path.child(PathSegment{Index: i, IsIndex: true})
+			if d.isIgnored(itemPath) {
+				continue
+			}
+			binary.BigEndian.PutUint64(buffer[:], uint64(i))
+			hasher.Write(buffer[:])
+			d.hash(hasher, itemPath, item)
+		}
+		hasher.Write([]byte{']'})
+	default:
+		hasher.Write([]byte{'z'})
+	}
+}
+
+// writeString writes s prefixed by its length, so that consecutive strings can't run into each other.
+func writeString(hasher hash.Hash64, s string) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(s)))
+	hasher.Write(length[:])
+	hasher.Write([]byte(s))
+}
+
+// Diff returns the changes that turn source into target, ordered by path.
+func (d *Differ) Diff(source model.JSONValue, target model.JSONValue) []model.ValueChange {
+	var changes []model.ValueChange
+	d.diff(Path{}, source, target, &changes)
+	return changes
+}
+
+func (d *Differ) diff(path Path, source model.JSONValue, target model.JSONValue, changes *[]model.ValueChange) {
+	switch {
+	case source.ObjectVal != nil && target.ObjectVal != nil:
+		fields := slices.Sorted(maps.Keys(source.ObjectVal))
+		for field := range target.ObjectVal {
+			if _, ok := source.ObjectVal[field]; !ok {
+				fields = append(fields, field)
+			}
+		}
+		slices.Sort(fields)
+		for _, field := range fields {
+			d.diffChild(path.child(PathSegment{Field: field}), source.ObjectVal, target.ObjectVal, field, changes)
+		}
+	case source.ArrayVal != nil && target.ArrayVal != nil:
+		for i := 0; i < max(len(source.ArrayVal), len(target.ArrayVal)); i++ {
+			itemPath := path.child(PathSegment{Index: i, IsIndex: true})
+			if d.isIgnored(itemPath) {
+				continue
+			}
+			switch {
+			case i >= len(target.ArrayVal):
+				*changes = append(*changes, model.ValueChange{
+					Path:   itemPath.String(),
+					Type:   model.ChangeRemoved,
+					Source: &source.ArrayVal[i],
+				})
+			case i >= len(source.ArrayVal):
+				*changes = append(*changes, model.ValueChange{
+					Path:   itemPath.String(),
+					Type:   model.ChangeAdded,
+					Target: &target.ArrayVal[i],
+				})
+			default:
+				d.diff(itemPath, source.ArrayVal[i], target.ArrayVal[i], changes)
+			}
+		}
+	default:
+		sourceHasher, targetHasher := fnv.New64a(), fnv.New64a()
+		d.hash(sourceHasher, path, source)
+		d.hash(targetHasher, path, target)
+		if sourceHasher.Sum64() != targetHasher.Sum64() {
+			*changes = append(*changes, model.ValueChange{
+				Path:   path.String(),
+				Type:   model.ChangeChanged,
+				Source: &source,
+				Target: &target,
+			})
+		}
+	}
+}
+
+func (d *Differ) diffChild(
+	path Path,
+	source map[string]model.JSONValue,
+	target map[string]model.JSONValue,
+	field string,
+	changes *[]model.ValueChange,
+) {
+	if d.isIgnored(path) {
+		return
+	}
+	sourceValue, inSource := source[field]
+	targetValue, inTarget := target[field]
+	switch {
+	case !inTarget:
+		*changes = append(*changes, model.ValueChange{Path: path.String(), Type: model.ChangeRemoved, Source: &sourceValue})
+	case !inSource:
+		*changes = append(*changes, model.ValueChange{Path: path.String(), Type: model.ChangeAdded, Target: &targetValue})
+	default:
+		d.diff(path, sourceValue, targetValue, changes)
+	}
+}
+
+func (d *Differ) isIgnored(path Path) bool {
+	return len(d.ignore) > 0 && slices.Contains(d.ignore, path.String())
+}
+
+// child returns a new path, leaving p as it is.
+func (p Path) child(segment PathSegment) Path {
+	return append(slices.Clip(p), segment)
+}
@@ -0,0 +1,54 @@
+package jsonvalue
+
+import (
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+)
+
+// ToNative converts a model.JSONValue into the plain Go values produced by encoding/json, so that it can be
+// marshalled back into ordinary JSON.
+func ToNative(value model.JSONValue) interface{} {
+	switch {
+	case value.StringVal != nil:
+		return *value.StringVal
+	case value.NumberVal != nil:
+		return *value.NumberVal
+	case value.BoolVal != nil:
+		return *value.BoolVal
+	case value.ObjectVal != nil:
+		obj := make(map[string]interface{}, len(value.ObjectVal))
+		for key, val := range value.ObjectVal {
+			obj[key] = ToNative(val)
+		}
+		return obj
+	case value.ArrayVal != nil:
+		arr := make([]interface{}, len(value.ArrayVal))
+		for i, val := range value.ArrayVal {
+			arr[i] = ToNative(val)
+		}
+		return arr
+	default:
+		return nil
+	}
+}
+
+// ToJSON renders a model.JSONValue as an ordinary JSON document.
+func ToJSON(value model.JSONValue) ([]byte, error) {
+	return json.Marshal(ToNative(value))
+}
+
+// ToText renders scalars as their plain text and containers as JSON, which is what tabular outputs expect.
+func ToText(value model.JSONValue) (string, error) {
+	switch {
+	case value.StringVal != nil:
+		return *value.StringVal, nil
+	case value.NullVal:
+		return "", nil
+	default:
+		text, err := ToJSON(value)
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+}
@@ -0,0 +1,101 @@
+package jsonvalue
+
+import (
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"strconv"
+	"strings"
+)
+
+// PathSegment is a single step of a Path: either an object field or an array index.
+type PathSegment struct {
+	Field   string
+	Index   int
+	IsIndex bool
+}
+
+// Path is a parsed JSON path such as $.order.items[0].price or $["field with spaces"].
+type Path []PathSegment
+
+// ParsePath parses a dot/bracket JSON path. The leading $ is optional.
+func ParsePath(path string) (Path, error) {
+	rest := strings.TrimSpace(path)
+	rest = strings.TrimPrefix(rest, "$")
+	segments := make(Path, 0)
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty field name in path %q", path)
+			}
+			segments = append(segments, PathSegment{Field: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated bracket in path %q", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, PathSegment{Field: inner[1 : len(inner)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid array index %q in path %q", inner, path)
+			}
+			segments = append(segments, PathSegment{Index: index, IsIndex: true})
+		default:
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("unexpected character %q in path %q", rest[0], path)
+			}
+			// Allow paths without the leading "$." such as "order.id"
+			rest = "." + rest
+		}
+	}
+	return segments, nil
+}
+
+// Lookup returns the value the path points to, or false if any segment is missing.
+func (p Path) Lookup(value *model.JSONValue) (*model.JSONValue, bool) {
+	current := value
+	for _, segment := range p {
+		if current == nil {
+			return nil, false
+		}
+		if segment.IsIndex {
+			if segment.Index >= len(current.ArrayVal) {
+				return nil, false
+			}
+			current = &current.ArrayVal[segment.Index]
+			continue
+		}
+		child, ok := current.ObjectVal[segment.Field]
+		if !ok {
+			return nil, false
+		}
+		current = &child
+	}
+	return current, current != nil
+}
+
+func (p Path) String() string {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for _, segment := range p {
+		if segment.IsIndex {
+			builder.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+		} else if strings.ContainsAny(segment.Field, ".[]") {
+			builder.WriteString("[" + strconv.Quote(segment.Field) + "]")
+		} else {
+			builder.WriteString("." + segment.Field)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,172 @@
+package jsonvalue
+
+import (
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The order types are listed in, which puts null first as Avro unions with a null default require
+var schemaTypeOrder = []model.SchemaType{
+	model.SchemaNull,
+	model.SchemaBoolean,
+	model.SchemaInteger,
+	model.SchemaNumber,
+	model.SchemaString,
+	model.SchemaObject,
+	model.SchemaArray,
+}
+
+// SchemaInferrer merges the structure of JSON documents into a schema that every one of them conforms to.
+type SchemaInferrer struct {
+	root        *schemaNode
+	maxExamples int
+}
+
+type schemaNode struct {
+	// The number of values observed, of any type
+	count int64
+	types map[model.SchemaType]int64
+	// The fields of the objects observed
+	fields map[string]*schemaNode
+	// The elements of the arrays observed
+	items    *schemaNode
+	examples []string
+}
+
+func newSchemaNode() *schemaNode {
+	return &schemaNode{types: make(map[model.SchemaType]int64)}
+}
+
+// NewSchemaInferrer creates an inferrer that keeps up to maxExamples example values of every field.
+func NewSchemaInferrer(maxExamples int) *SchemaInferrer {
+	return &SchemaInferrer{root: newSchemaNode(), maxExamples: maxExamples}
+}
+
+// Add merges a document into the schema.
+func (s *SchemaInferrer) Add(value model.JSONValue) {
+	s.add(s.root, value)
+}
+
+func (s *SchemaInferrer) add(node *schemaNode, value model.JSONValue) {
+	node.count++
+	valueType := getSchemaType(value)
+	node.types[valueType]++
+	switch valueType {
+	case model.SchemaObject:
+		if node.fields == nil {
+			node.fields = make(map[string]*schemaNode, len(value.ObjectVal))
+		}
+		for name, field := range value.ObjectVal {
+			fieldNode, ok := node.fields[name]
+			if !ok {
+				fieldNode = newSchemaNode()
+				node.fields[name] = fieldNode
+			}
+			s.add(fieldNode, field)
+		}
+	case model.SchemaArray:
+		if node.items == nil {
+			node.items = newSchemaNode()
+		}
+		for _, item := range value.ArrayVal {
+			s.add(node.items, item)
+		}
+	case model.SchemaNull:
+	default:
+		if len(node.examples) < s.maxExamples {
+			if example, err := ToJSON(value); err == nil && !slices.Contains(node.examples, string(example)) {
+				node.examples = append(node.examples, string(example))
+			}
+		}
+	}
+}
+
+func getSchemaType(value model.JSONValue) model.SchemaType {
+	switch {
+	case value.StringVal != nil:
+		return model.SchemaString
+	case value.NumberVal != nil:
+		if *value.NumberVal == math.Trunc(*value.NumberVal) && !math.IsInf(*value.NumberVal, 0) {
+			return model.SchemaInteger
+		}
+		return model.SchemaNumber
+	case value.BoolVal != nil:
+		return model.SchemaBoolean
+	case value.ObjectVal != nil:
+		return model.SchemaObject
+	case value.ArrayVal != nil:
+		return model.SchemaArray
+	default:
+		return model.SchemaNull
+	}
+}
+
+// Fields returns what was observed at every path, parents before their fields and fields by name.
+func (s *SchemaInferrer) Fields() []model.FieldSchema {
+	fields := make([]model.FieldSchema, 0)
+	if s.root.count > 0 {
+		s.root.appendFields("$", s.root.count, &fields)
+	}
+	return fields
+}
+
+func (n *schemaNode) appendFields(path string, parentCount int64, fields *[]model.FieldSchema) {
+	types := make(map[model.SchemaType]int64, len(n.types))
+	for valueType, count := range n.types {
+		types[valueType] = count
+	}
+	*fields = append(*fields, model.FieldSchema{
+		Path:        path,
+		Types:       types,
+		Conflict:    len(n.nonNullTypes()) > 1,
+		Presence:    float64(n.count) / float64(parentCount),
+		Nullability: float64(n.types[model.SchemaNull]) / float64(n.count),
+		Examples:    slices.Clone(n.examples),
+	})
+	for _, name := range n.fieldNames() {
+		n.fields[name].appendFields(fieldPath(path, name), n.types[model.SchemaObject], fields)
+	}
+	if n.items != nil && n.items.count > 0 {
+		n.items.appendFields(path+"[*]", n.items.count, fields)
+	}
+}
+
+func fieldPath(path string, name string) string {
+	if name == "" || strings.ContainsAny(name, ".[]* ") {
+		return path + "[" + strconv.Quote(name) + "]"
+	}
+	return path + "." + name
+}
+
+func (n *schemaNode) fieldNames() []string {
+	names := make([]string, 0, len(n.fields))
+	for name := range n.fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// nonNullTypes returns the types observed other than null in order, with integers widened to numbers when both
+// were observed.
+func (n *schemaNode) nonNullTypes() []model.SchemaType {
+	types := make([]model.SchemaType, 0, len(n.types))
+	for _, valueType := range schemaTypeOrder {
+		if valueType == model.SchemaNull || n.types[valueType] == 0 {
+			continue
+		}
+		if valueType == model.SchemaInteger && n.types[model.SchemaNumber] > 0 {
+			continue
+		}
+		types = append(types, valueType)
+	}
+	return types
+}
+
+// isOptional tells whether the field was missing from some of the objectCount objects that could hold it.
+func (n *schemaNode) isOptional(objectCount int64) bool {
+	return n.count < objectCount
+}
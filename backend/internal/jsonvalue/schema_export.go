@@ -0,0 +1,199 @@
+package jsonvalue
+
+import (
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema renders the inferred schema as a JSON Schema. Fields present in every object are required, and
+// integers that were observed alongside other numbers are widened to numbers.
+func (s *SchemaInferrer) JSONSchema(title string) map[string]any {
+	schema := s.root.jsonSchema()
+	schema["$schema"] = jsonSchemaDialect
+	if title != "" {
+		schema["title"] = title
+	}
+	return schema
+}
+
+func (n *schemaNode) jsonSchema() map[string]any {
+	schema := make(map[string]any)
+	types := n.nonNullTypes()
+	if n.types[model.SchemaNull] > 0 {
+		types = append([]model.SchemaType{model.SchemaNull}, types...)
+	}
+	switch len(types) {
+	case 0:
+		// Nothing was observed, such as the elements of arrays that were always empty
+		return schema
+	case 1:
+		schema["type"] = types[0]
+	default:
+		schema["type"] = types
+	}
+	if n.types[model.SchemaObject] > 0 {
+		properties := make(map[string]any, len(n.fields))
+		required := make([]string, 0)
+		for _, name := range n.fieldNames() {
+			field := n.fields[name]
+			properties[name] = field.jsonSchema()
+			if !field.isOptional(n.types[model.SchemaObject]) {
+				required = append(required, name)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+	if n.types[model.SchemaArray] > 0 && n.items != nil && n.items.count > 0 {
+		schema["items"] = n.items.jsonSchema()
+	}
+	if len(n.examples) > 0 {
+		examples := make([]json.RawMessage, len(n.examples))
+		for i, example := range n.examples {
+			examples[i] = json.RawMessage(example)
+		}
+		schema["examples"] = examples
+	}
+	return schema
+}
+
+// AvroSchema renders the inferred schema as a draft of an Avro schema, whose top level record is named name.
+// Optional and nullable fields become unions with null that default to null, conflicting types become unions, and
+// field names that Avro doesn't allow are rewritten, with the original name kept in the doc of the field.
+func (s *SchemaInferrer) AvroSchema(name string, namespace string) any {
+	names := make(map[string]int)
+	schema := s.root.avroSchema(avroName(name, "Record"), names)
+	if record, ok := schema.(map[string]any); ok && namespace != "" {
+		record["namespace"] = namespace
+	}
+	return schema
+}
+
+func (n *schemaNode) avroSchema(name string, names map[string]int) any {
+	var union []any
+	if n.types[model.SchemaNull] > 0 {
+		union = append(union, "null")
+	}
+	for _, valueType := range n.nonNullTypes() {
+		switch valueType {
+		case model.SchemaBoolean:
+			union = append(union, "boolean")
+		case model.SchemaInteger:
+			union = append(union, "long")
+		case model.SchemaNumber:
+			union = append(union, "double")
+		case model.SchemaString:
+			union = append(union, "string")
+		case model.SchemaObject:
+			union = append(union, n.avroRecord(name, names))
+		case model.SchemaArray:
+			items := any("null")
+			if n.items != nil && n.items.count > 0 {
+				items = n.items.avroSchema(name+"Item", names)
+			}
+			union = append(union, map[string]any{"type": "array", "items": items})
+		}
+	}
+	switch len(union) {
+	case 0:
+		return "null"
+	case 1:
+		return union[0]
+	default:
+		return union
+	}
+}
+
+func (n *schemaNode) avroRecord(name string, names map[string]int) map[string]any {
+	// Record names have to be unique within the schema
+	names[name]++
+	if names[name] > 1 {
+		name += strconv.Itoa(names[name])
+	}
+	fields := make([]any, 0, len(n.fields))
+	fieldNames := make(map[string]int, len(n.fields))
+	for _, fieldName := range n.fieldNames() {
+		field := n.fields[fieldName]
+		fieldType := field.avroSchema(name+avroName(fieldName, "Field"), names)
+		if field.isOptional(n.types[model.SchemaObject]) {
+			fieldType = withNull(fieldType)
+		}
+		avroField := map[string]any{"type": fieldType}
+		if union, ok := fieldType.([]any); ok && union[0] == "null" {
+			avroField["default"] = nil
+		}
+		avroFieldName := avroFieldName(fieldName)
+		fieldNames[avroFieldName]++
+		if fieldNames[avroFieldName] > 1 {
+			avroFieldName += "_" + strconv.Itoa(fieldNames[avroFieldName])
+		}
+		avroField["name"] = avroFieldName
+		if avroFieldName != fieldName {
+			avroField["doc"] = "Originally " + strconv.Quote(fieldName)
+		}
+		fields = append(fields, avroField)
+	}
+	return map[string]any{"type": "record", "name": name, "fields": fields}
+}
+
+// withNull makes schema a union that starts with null, so that it can default to null.
+func withNull(schema any) any {
+	union, ok := schema.([]any)
+	if !ok {
+		if schema == "null" {
+			return schema
+		}
+		return []any{"null", schema}
+	}
+	if union[0] == "null" {
+		return union
+	}
+	return append([]any{"null"}, union...)
+}
+
+// avroName turns name into a record name such as OrderItems, falling back to fallback when nothing is left of it.
+func avroName(name string, fallback string) string {
+	var builder strings.Builder
+	upper := true
+	for _, r := range name {
+		if !isAvroNameRune(r) || r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		builder.WriteRune(r)
+	}
+	avroName := builder.String()
+	if avroName == "" || unicode.IsDigit(rune(avroName[0])) {
+		avroName = fallback + avroName
+	}
+	return avroName
+}
+
+// avroFieldName replaces the characters that Avro doesn't allow in names with underscores.
+func avroFieldName(name string) string {
+	fieldName := strings.Map(func(r rune) rune {
+		if isAvroNameRune(r) {
+			return r
+		}
+		return '_'
+	}, name)
+	if fieldName == "" || unicode.IsDigit(rune(fieldName[0])) {
+		fieldName = "_" + fieldName
+	}
+	return fieldName
+}
+
+func isAvroNameRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,95 @@
+package jsonvalue
+
+import (
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/valyala/fastjson"
+	"strconv"
+)
+
+type transformStep struct {
+	op    model.TransformOp
+	path  Path
+	value string
+}
+
+// Transform rewrites JSON documents in place. Unlike a round trip through model.JSONValue it keeps the order of
+// object fields and the exact formatting of numbers it does not touch.
+type Transform struct {
+	steps []transformStep
+}
+
+func NewTransform(steps []model.TransformStep) (*Transform, error) {
+	compiled := make([]transformStep, len(steps))
+	for i, step := range steps {
+		path, err := ParsePath(step.Path)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return nil, fmt.Errorf("transform step %d must not target the whole document", i)
+		}
+		compiled[i] = transformStep{op: step.Op, path: path}
+		switch step.Op {
+		case model.TransformSet:
+			if err := fastjson.Validate(step.Value); err != nil {
+				return nil, fmt.Errorf("invalid value of transform step %d: %w", i, err)
+			}
+			compiled[i].value = step.Value
+		case model.TransformRemove:
+		default:
+			return nil, fmt.Errorf("unsupported transform operation %q", step.Op)
+		}
+	}
+	return &Transform{steps: compiled}, nil
+}
+
+// Apply returns the transformed document. Set creates missing objects along the way, while missing fields are
+// ignored by remove.
+func (t *Transform) Apply(document []byte) ([]byte, error) {
+	var parser fastjson.Parser
+	root, err := parser.ParseBytes(document)
+	if err != nil {
+		return nil, fmt.Errorf("document is not JSON: %w", err)
+	}
+	var arena fastjson.Arena
+	for _, step := range t.steps {
+		parent := root
+		parentPath := step.path[:len(step.path)-1]
+		for _, segment := range parentPath {
+			child := parent.Get(segmentKey(segment))
+			if child == nil {
+				if step.op == model.TransformRemove || segment.IsIndex {
+					parent = nil
+					break
+				}
+				child = arena.NewObject()
+				parent.Set(segment.Field, child)
+			}
+			parent = child
+		}
+		if parent == nil {
+			continue
+		}
+		last := step.path[len(step.path)-1]
+		switch step.op {
+		case model.TransformSet:
+			// Parsed for every document, since later steps may modify the value once it is part of the document
+			value, err := fastjson.Parse(step.value)
+			if err != nil {
+				return nil, err
+			}
+			parent.Set(segmentKey(last), value)
+		case model.TransformRemove:
+			parent.Del(segmentKey(last))
+		}
+	}
+	return root.MarshalTo(nil), nil
+}
+
+func segmentKey(segment PathSegment) string {
+	if segment.IsIndex {
+		return strconv.Itoa(segment.Index)
+	}
+	return segment.Field
+}
@@ -0,0 +1,228 @@
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/sketch"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"slices"
+	"unicode/utf8"
+)
+
+const (
+	// The error of key counts as a share of the sample, and the probability that a count exceeds it
+	keyCountEpsilon = 0.0001
+	keyCountDelta   = 0.001
+	// The share of the sample of a hot partition that a key has to make up to be reported as a cause
+	hotKeyShare = 0.1
+	// How often progress is reported, in sampled messages
+	keyDistributionProgressInterval = 1000
+)
+
+// AnalyzeKeyDistribution samples the newest messages of every partition of topic, and reports how its messages,
+// bytes and keys are spread over the partitions. onProgress is called with the number of messages sampled so far.
+// It returns nil when the topic doesn't exist.
+func (k *KafkaService) AnalyzeKeyDistribution(
+	ctx context.Context,
+	topic string,
+	options model.KeyDistributionOptions,
+	onProgress func(sampled int64),
+) (*model.KeyDistribution, error) {
+	err := k.authorizer.Authorize(ctx, auth.OperationViewMessages, auth.Resource{Topic: topic})
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := k.getTopicPartitions(ctx, topic)
+	if err != nil || len(partitions) == 0 {
+		return nil, err
+	}
+	newestOffsets, err := k.getOffsets(ctx, topic, partitions, sarama.OffsetNewest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newest offsets: %w", err)
+	}
+	oldestOffsets, err := k.getOffsets(ctx, topic, partitions, sarama.OffsetOldest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oldest offsets: %w", err)
+	}
+	sizes, err := k.GetPartitionSizes(ctx)
+	if err != nil {
+		k.logger.Warn("failed to get partition sizes", zap.String("topic", topic), zap.Error(err))
+	}
+
+	distribution := &model.KeyDistribution{
+		Topic:      topic,
+		Partitions: make([]model.PartitionKeyDistribution, 0, len(partitions)),
+	}
+	indexes := make(map[int32]int, len(partitions))
+	slices.Sort(partitions)
+	for _, partition := range partitions {
+		size, ok := sizes[topic][partition]
+		if !ok {
+			size.Bytes = -1
+		}
+		indexes[partition] = len(distribution.Partitions)
+		distribution.Partitions = append(distribution.Partitions, model.PartitionKeyDistribution{
+			Partition: partition,
+			Messages:  newestOffsets[partition] - oldestOffsets[partition],
+			SizeBytes: size.Bytes,
+		})
+	}
+	sampleInput := getSampleRanges(partitions, oldestOffsets, newestOffsets, options.SampleSize)
+
+	sampler := newKeySampler(options.TopK, len(partitions))
+	err = k.streamMessagesForTopic(ctx, topic, sampleInput, nil, func(record *model.Record) error {
+		sampler.add(&distribution.Partitions[indexes[record.Partition]], record)
+		if distribution.SampledMessages++; distribution.SampledMessages%keyDistributionProgressInterval == 0 {
+			onProgress(distribution.SampledMessages)
+		}
+		return nil
+	})
+	onProgress(distribution.SampledMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range distribution.Partitions {
+		partitionDistribution := &distribution.Partitions[i]
+		partitionDistribution.DistinctKeys = sampler.partitionKeys[partitionDistribution.Partition].Count()
+		distribution.NullKeys += partitionDistribution.NullKeys
+	}
+	distribution.DistinctKeys = sampler.keys.Count()
+	addImbalance(distribution, options.ImbalanceThreshold)
+	distribution.TopKeys, distribution.HotKeys = sampler.getTopKeys(distribution, indexes)
+	return distribution, nil
+}
+
+// keySampler counts the keys of the sampled messages.
+type keySampler struct {
+	topKeys       *sketch.TopK
+	keys          *sketch.HyperLogLog
+	partitionKeys map[int32]*sketch.HyperLogLog
+	// How many messages with each of the top keys were sampled from every partition, since the key entered the top
+	keyPartitions map[string]map[int32]int64
+	topK          int
+}
+
+func newKeySampler(topK int, partitions int) *keySampler {
+	return &keySampler{
+		topKeys:       sketch.NewTopK(topK, sketch.NewCountMin(keyCountEpsilon, keyCountDelta)),
+		keys:          sketch.NewHyperLogLog(),
+		partitionKeys: make(map[int32]*sketch.HyperLogLog, partitions),
+		keyPartitions: make(map[string]map[int32]int64, topK),
+		topK:          topK,
+	}
+}
+
+func (s *keySampler) add(partitionDistribution *model.PartitionKeyDistribution, record *model.Record) {
+	partitionDistribution.SampledMessages++
+	partitionDistribution.SampledBytes += int64(len(record.Key) + len(record.Value))
+	if record.Key == nil {
+		partitionDistribution.NullKeys++
+		return
+	}
+	s.keys.Add(record.Key)
+	partitionKeys, ok := s.partitionKeys[record.Partition]
+	if !ok {
+		partitionKeys = sketch.NewHyperLogLog()
+		s.partitionKeys[record.Partition] = partitionKeys
+	}
+	partitionKeys.Add(record.Key)
+
+	if !s.topKeys.Add(record.Key) {
+		return
+	}
+	key := string(record.Key)
+	if s.keyPartitions[key] == nil {
+		s.keyPartitions[key] = make(map[int32]int64)
+	}
+	s.keyPartitions[key][record.Partition]++
+	// Keys that dropped out of the top are forgotten now and then
+	if len(s.keyPartitions) > 4*s.topK {
+		for tracked := range s.keyPartitions {
+			if !s.topKeys.Contains(tracked) {
+				delete(s.keyPartitions, tracked)
+			}
+		}
+	}
+}
+
+// getTopKeys returns the top keys, and those among them that make up a large part of the sample of a hot partition.
+func (s *keySampler) getTopKeys(
+	distribution *model.KeyDistribution,
+	indexes map[int32]int,
+) ([]model.KeyFrequency, []model.KeyFrequency) {
+	topKeys := make([]model.KeyFrequency, 0, s.topK)
+	hotKeys := make([]model.KeyFrequency, 0)
+	for _, keyCount := range s.topKeys.Top() {
+		frequency := model.KeyFrequency{
+			Key:   keyCount.Key,
+			Count: keyCount.Count,
+			Share: float64(keyCount.Count) / float64(distribution.SampledMessages),
+		}
+		if !utf8.ValidString(keyCount.Key) {
+			frequency.Key = base64.StdEncoding.EncodeToString([]byte(keyCount.Key))
+			frequency.Base64 = true
+		}
+		mostSampled := int64(-1)
+		for partition, sampled := range s.keyPartitions[keyCount.Key] {
+			if sampled > mostSampled || (sampled == mostSampled && partition < frequency.Partition) {
+				frequency.Partition, mostSampled = partition, sampled
+			}
+		}
+		topKeys = append(topKeys, frequency)
+
+		partitionDistribution := distribution.Partitions[indexes[frequency.Partition]]
+		if partitionDistribution.Hot &&
+			float64(keyCount.Count) >= hotKeyShare*float64(partitionDistribution.SampledMessages) {
+			hotKeys = append(hotKeys, frequency)
+		}
+	}
+	return topKeys, hotKeys
+}
+
+// addImbalance flags the partitions that hold at least threshold times the mean messages or size.
+func addImbalance(distribution *model.KeyDistribution, threshold float64) {
+	messages := make([]int64, 0, len(distribution.Partitions))
+	sizes := make([]int64, 0, len(distribution.Partitions))
+	for _, partitionDistribution := range distribution.Partitions {
+		messages = append(messages, partitionDistribution.Messages)
+		if partitionDistribution.SizeBytes >= 0 {
+			sizes = append(sizes, partitionDistribution.SizeBytes)
+		}
+	}
+	meanMessages := mean(messages)
+	meanSize := mean(sizes)
+	if meanMessages > 0 {
+		distribution.MessageSkew = float64(slices.Max(messages)) / meanMessages
+	}
+	if len(sizes) == len(messages) && meanSize > 0 {
+		sizeSkew := float64(slices.Max(sizes)) / meanSize
+		distribution.SizeSkew = &sizeSkew
+	}
+	// A single partition can't be imbalanced
+	if len(distribution.Partitions) < 2 {
+		return
+	}
+	for i := range distribution.Partitions {
+		partitionDistribution := &distribution.Partitions[i]
+		hotMessages := meanMessages > 0 && float64(partitionDistribution.Messages) >= threshold*meanMessages
+		hotSize := distribution.SizeSkew != nil && float64(partitionDistribution.SizeBytes) >= threshold*meanSize
+		partitionDistribution.Hot = hotMessages || hotSize
+		distribution.Imbalanced = distribution.Imbalanced || partitionDistribution.Hot
+	}
+}
+
+func mean(values []int64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := int64(0)
+	for _, value := range values {
+		sum += value
+	}
+	return float64(sum) / float64(len(values))
+}
@@ -0,0 +1,155 @@
+package kafka
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"go.uber.org/zap"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// AnalyzeMessageSizes samples the newest messages of topics and measures their records against the largest record
+// batch each topic accepts. Every topic but the internal ones is analyzed when topics is empty, and topics that don't
+// exist are left out. The result is ordered by utilization, highest first.
+func (k *KafkaService) AnalyzeMessageSizes(
+	ctx context.Context,
+	topics []string,
+	options model.MessageSizeOptions,
+) ([]model.TopicMessageSizes, error) {
+	topicDetails, err := k.GetTopics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+	detailsByName := make(map[string]model.TopicDetails, len(topicDetails))
+	for _, details := range topicDetails {
+		detailsByName[details.Name] = details
+		if len(topics) == 0 && !details.IsInternal {
+			topics = append(topics, details.Name)
+		}
+	}
+	offsets, err := k.GetPartitionOffsets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition offsets: %w", err)
+	}
+	var brokerLimit *int64
+	brokerConfig, err := k.getBrokerConfig(ctx)
+	if err != nil {
+		// The sizes can still be told
+		k.logger.Warn("failed to get the broker message size limit", zap.Error(err))
+	} else {
+		brokerLimit = parseMessageSizeLimit(brokerConfig["message.max.bytes"])
+	}
+
+	results := make([]model.TopicMessageSizes, 0, len(topics))
+	for _, topic := range topics {
+		details, ok := detailsByName[topic]
+		if !ok {
+			continue
+		}
+		sizes, err := k.sampleMessageSizes(ctx, topic, offsets[topic], options.SampleSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample messages of topic %s: %w", topic, err)
+		}
+		// ListTopics only returns the configs a topic overrides
+		sizes.BrokerMaxMessageBytes = brokerLimit
+		sizes.MaxMessageBytes = brokerLimit
+		if topicLimit := parseMessageSizeLimit(details.AdditionalConfigs["max.message.bytes"]); topicLimit != nil {
+			sizes.MaxMessageBytes = topicLimit
+			sizes.TopicOverride = true
+		}
+		if sizes.MaxMessageBytes != nil {
+			utilization := float64(sizes.Record.Max) / float64(*sizes.MaxMessageBytes)
+			sizes.Utilization = &utilization
+			sizes.AtRisk = utilization >= options.Threshold
+		}
+		results = append(results, sizes)
+	}
+	slices.SortFunc(results, func(a, b model.TopicMessageSizes) int {
+		if a.Utilization == nil || b.Utilization == nil {
+			if a.Utilization != nil {
+				return -1
+			}
+			if b.Utilization != nil {
+				return 1
+			}
+		} else if c := cmp.Compare(*b.Utilization, *a.Utilization); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Topic, b.Topic)
+	})
+	return results, nil
+}
+
+// sampleMessageSizes measures the records of a sample of the newest messages of topic.
+func (k *KafkaService) sampleMessageSizes(
+	ctx context.Context,
+	topic string,
+	offsets map[int32]model.PartitionOffsets,
+	sampleSize int64,
+) (model.TopicMessageSizes, error) {
+	partitions := make([]int32, 0, len(offsets))
+	oldestOffsets := make(map[int32]int64, len(offsets))
+	newestOffsets := make(map[int32]int64, len(offsets))
+	for partition, partitionOffsets := range offsets {
+		partitions = append(partitions, partition)
+		oldestOffsets[partition] = partitionOffsets.Oldest
+		newestOffsets[partition] = partitionOffsets.Newest
+	}
+	sampleInput := getSampleRanges(partitions, oldestOffsets, newestOffsets, sampleSize)
+	if len(sampleInput.PartitionDetailsMap) == 0 {
+		return model.TopicMessageSizes{Topic: topic}, nil
+	}
+
+	var keys, values, headers, records []int64
+	err := k.streamMessagesForTopic(ctx, topic, sampleInput, nil, func(record *model.Record) error {
+		headerSize := 0
+		for _, header := range record.Headers {
+			headerSize += len(header.Key) + len(header.Value)
+		}
+		keys = append(keys, int64(len(record.Key)))
+		values = append(values, int64(len(record.Value)))
+		headers = append(headers, int64(headerSize))
+		records = append(records, int64(len(record.Key)+len(record.Value)+headerSize))
+		return nil
+	})
+	if err != nil {
+		return model.TopicMessageSizes{}, err
+	}
+	return model.TopicMessageSizes{
+		Topic:           topic,
+		SampledMessages: int64(len(records)),
+		Key:             getSizeDistribution(keys),
+		Value:           getSizeDistribution(values),
+		Headers:         getSizeDistribution(headers),
+		Record:          getSizeDistribution(records),
+	}, nil
+}
+
+// getSizeDistribution tells the nearest-rank percentiles of sizes, which it sorts.
+func getSizeDistribution(sizes []int64) model.SizeDistribution {
+	if len(sizes) == 0 {
+		return model.SizeDistribution{}
+	}
+	slices.Sort(sizes)
+	percentile := func(p float64) int64 {
+		return sizes[int(math.Ceil(p*float64(len(sizes))))-1]
+	}
+	return model.SizeDistribution{
+		Mean: mean(sizes),
+		P50:  percentile(0.5),
+		P95:  percentile(0.95),
+		P99:  percentile(0.99),
+		Max:  sizes[len(sizes)-1],
+	}
+}
+
+func parseMessageSizeLimit(value string) *int64 {
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 {
+		return nil
+	}
+	return &limit
+}
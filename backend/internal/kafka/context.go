@@ -0,0 +1,36 @@
+package kafka
+
+import (
+	"context"
+)
+
+// runWithContext returns as soon as ctx is done, even though sarama's blocking calls can't be interrupted.
+// The abandoned call still finishes within sarama's network timeouts, and its result is dropped into a buffered
+// channel, so that its goroutine exits instead of leaking.
+func runWithContext[T any](ctx context.Context, operation func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := operation()
+		done <- outcome{value: value, err: err}
+	}()
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func (k *KafkaService) getOffset(ctx context.Context, topic string, partition int32, time int64) (int64, error) {
+	return runWithContext(ctx, func() (int64, error) {
+		return k.client.GetOffset(topic, partition, time)
+	})
+}
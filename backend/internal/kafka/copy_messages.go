@@ -0,0 +1,218 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"time"
+)
+
+const copyBatchSize = 100
+
+// CopyMessages produces the records of source that match its filter into destination, which may be on another
+// registered cluster. onProgress is called after every batch that reaches the destination. Canceling ctx stops
+// the copy after the current batch; the records copied so far stay in the destination.
+func (k *KafkaService) CopyMessages(
+	ctx context.Context,
+	source model.CopySource,
+	destination model.CopyDestination,
+	options model.CopyOptions,
+	onProgress func(progress model.CopyProgress),
+) (*model.CopyProgress, error) {
+	err := k.authorizer.Authorize(ctx, auth.OperationViewMessages, auth.Resource{Topic: source.Topic})
+	if err != nil {
+		return nil, err
+	}
+	destinationResource := auth.Resource{Cluster: destination.Cluster, Topic: destination.Topic}
+	if err := k.authorizer.Authorize(ctx, auth.OperationProduce, destinationResource); err != nil {
+		return nil, err
+	}
+
+	var transform *jsonvalue.Transform
+	if len(options.ValueTransform) > 0 {
+		transform, err = jsonvalue.NewTransform(options.ValueTransform)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value transform: %w", err)
+		}
+	}
+
+	partitionInput, err := k.resolveCopyRanges(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	filter := &model.MessageFilter{}
+	if source.Filter != nil {
+		*filter = *source.Filter
+	}
+	// Offsets found by timestamp are only a starting point, since timestamps need not increase within a partition
+	if filter.StartTime == nil {
+		filter.StartTime = source.StartTime
+	}
+	if filter.EndTime == nil {
+		filter.EndTime = source.EndTime
+	}
+
+	cluster, err := k.getCluster(destination.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sarama.NewClient(cluster.brokers, newIdempotentProducerConfig(cluster.config, options.KeepPartition))
+	if err != nil {
+		k.logger.Error(
+			"failed to connect to copy destination",
+			zap.String("cluster", destination.Cluster),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to connect to destination cluster: %w", err)
+	}
+	defer client.Close()
+	destinationPartitions, err := client.Partitions(destination.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions of destination topic %s: %w", destination.Topic, err)
+	}
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+	defer producer.Close()
+
+	progress := &model.CopyProgress{}
+	batch := make([]*sarama.ProducerMessage, 0, copyBatchSize)
+	flush := func() error {
+		produced, err := sendBatch(producer, batch)
+		progress.Copied += int64(produced)
+		batch = batch[:0]
+		if produced > 0 && onProgress != nil {
+			onProgress(*progress)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to produce to destination: %w", err)
+		}
+		return nil
+	}
+
+	err = k.streamMessagesForTopic(ctx, source.Topic, partitionInput, filter, func(record *model.Record) error {
+		message := &sarama.ProducerMessage{
+			Topic:     destination.Topic,
+			Timestamp: record.Timestamp,
+		}
+		if options.KeepKey && record.Key != nil {
+			message.Key = sarama.ByteEncoder(record.Key)
+		}
+		if options.KeepHeaders {
+			for _, header := range record.Headers {
+				message.Headers = append(message.Headers, sarama.RecordHeader{
+					Key:   []byte(header.Key),
+					Value: header.Value,
+				})
+			}
+		}
+		if options.KeepPartition {
+			if int(record.Partition) >= len(destinationPartitions) {
+				return fmt.Errorf(
+					"record from partition %d can't keep its partition, destination topic %s has %d partitions",
+					record.Partition,
+					destination.Topic,
+					len(destinationPartitions),
+				)
+			}
+			message.Partition = record.Partition
+		}
+		value := record.Value
+		if transform != nil && value != nil {
+			transformed, err := transform.Apply(value)
+			if err != nil {
+				progress.Untransformed++
+			} else {
+				value = transformed
+			}
+		}
+		if value != nil {
+			message.Value = sarama.ByteEncoder(value)
+		}
+
+		batch = append(batch, message)
+		if len(batch) >= copyBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return progress, fmt.Errorf("failed to copy messages: %w", err)
+	}
+	if err := flush(); err != nil {
+		return progress, err
+	}
+	return progress, nil
+}
+
+// resolveCopyRanges turns the requested offset and time ranges into absolute offsets per partition. Partitions
+// without records in the requested range are left out.
+func (k *KafkaService) resolveCopyRanges(ctx context.Context, source model.CopySource) (model.PartitionInput, error) {
+	requested := source.Partitions.PartitionDetailsMap
+	if len(requested) == 0 {
+		partitions, err := k.client.Partitions(source.Topic)
+		if err != nil {
+			return model.PartitionInput{}, fmt.Errorf("failed to get partitions of topic %s: %w", source.Topic, err)
+		}
+		requested = make(map[int32]model.PartitionDetails, len(partitions))
+		for _, partition := range partitions {
+			requested[partition] = model.PartitionDetails{StartOffset: 0, EndOffset: -1}
+		}
+	}
+
+	resolved := model.PartitionInput{PartitionDetailsMap: make(map[int32]model.PartitionDetails, len(requested))}
+	for partition, details := range requested {
+		startOffset, endOffset, hasMessages, err := k.resolvePartitionRange(
+			ctx,
+			source.Topic,
+			partition,
+			details.StartOffset,
+			details.EndOffset,
+		)
+		if err != nil {
+			return model.PartitionInput{}, err
+		}
+		if !hasMessages {
+			continue
+		}
+		oldestOffset, err := k.getOffset(ctx, source.Topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return model.PartitionInput{}, fmt.Errorf("failed to get oldest offset: %w", err)
+		}
+		startOffset = max(startOffset, oldestOffset)
+
+		if source.StartTime != nil {
+			offset, err := k.getOffset(ctx, source.Topic, partition, source.StartTime.UnixMilli())
+			if err != nil {
+				return model.PartitionInput{}, fmt.Errorf("failed to get offset for start time: %w", err)
+			}
+			if offset == -1 {
+				// Nothing was produced after the start time
+				continue
+			}
+			startOffset = max(startOffset, offset)
+		}
+		if source.EndTime != nil {
+			offset, err := k.getOffset(ctx, source.Topic, partition, source.EndTime.Add(time.Millisecond).UnixMilli())
+			if err != nil {
+				return model.PartitionInput{}, fmt.Errorf("failed to get offset for end time: %w", err)
+			}
+			if offset != -1 {
+				endOffset = min(endOffset, offset-1)
+			}
+		}
+		if startOffset > endOffset {
+			continue
+		}
+		resolved.PartitionDetailsMap[partition] = model.PartitionDetails{
+			StartOffset: startOffset,
+			EndOffset:   endOffset,
+		}
+	}
+	return resolved, nil
+}
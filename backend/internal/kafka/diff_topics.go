@@ -0,0 +1,295 @@
+package kafka
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"go.uber.org/zap"
+	"hash/fnv"
+	"slices"
+	"unicode/utf8"
+)
+
+const diffProgressInterval = 1000
+
+// recordKey identifies the records that are compared with each other, telling records without a key apart from
+// records with an empty one.
+type recordKey struct {
+	key  string
+	null bool
+}
+
+type diffMatch struct {
+	recordKey
+	hash uint64
+}
+
+type diffEntry struct {
+	// The position of the record among those read from its side
+	seq    int64
+	record model.DiffRecord
+}
+
+// DiffTopics compares the records of target with those of source, which may be topics on different clusters or
+// ranges of the same topic. Values are only hashed while reading, and the differing records that are reported are
+// read again to tell how their values differ. Every source record is kept with its key, position and value hash
+// until a target record matches it, as is every target record that matches none, so memory grows with the number of
+// source records and the size of their keys rather than with the size of their values.
+func (k *KafkaService) DiffTopics(
+	ctx context.Context,
+	source model.DiffSource,
+	target model.DiffSource,
+	options model.DiffOptions,
+	onProgress func(read int64),
+) (*model.TopicDiff, error) {
+	for _, side := range []model.DiffSource{source, target} {
+		resource := auth.Resource{Cluster: side.Cluster, Topic: side.Topic}
+		if err := k.authorizer.Authorize(ctx, auth.OperationViewMessages, resource); err != nil {
+			return nil, err
+		}
+	}
+	differ, err := jsonvalue.NewDiffer(options.IgnorePaths)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignored path: %w", err)
+	}
+	sourceService, err := k.connectToCluster(ctx, source.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if sourceService != k {
+		defer sourceService.Close()
+	}
+	targetService, err := k.connectToCluster(ctx, target.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if targetService != k {
+		defer targetService.Close()
+	}
+
+	diff := &model.TopicDiff{}
+	reportProgress := func() {
+		if read := diff.SourceMessages + diff.TargetMessages; read%diffProgressInterval == 0 {
+			onProgress(read)
+		}
+	}
+	// The source records that found no identical target record yet, by key and hash, in the order they were read
+	unmatched := make(map[diffMatch][]diffEntry)
+	err = sourceService.streamDiffSource(ctx, source, func(record *model.Record) error {
+		match := diffMatch{recordKey: getRecordKey(record), hash: hashRecordValue(differ, record)}
+		entry := diffEntry{seq: diff.SourceMessages, record: toDiffRecord(record, match.recordKey)}
+		unmatched[match] = append(unmatched[match], entry)
+		diff.SourceMessages++
+		reportProgress()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source: %w", err)
+	}
+	targetUnmatched := make(map[recordKey][]diffEntry)
+	err = targetService.streamDiffSource(ctx, target, func(record *model.Record) error {
+		match := diffMatch{recordKey: getRecordKey(record), hash: hashRecordValue(differ, record)}
+		if entries := unmatched[match]; len(entries) > 0 {
+			diff.Matched++
+			if len(entries) == 1 {
+				delete(unmatched, match)
+			} else {
+				unmatched[match] = entries[1:]
+			}
+		} else {
+			entry := diffEntry{seq: diff.TargetMessages, record: toDiffRecord(record, match.recordKey)}
+			targetUnmatched[match.recordKey] = append(targetUnmatched[match.recordKey], entry)
+		}
+		diff.TargetMessages++
+		reportProgress()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target: %w", err)
+	}
+	onProgress(diff.SourceMessages + diff.TargetMessages)
+
+	sourceUnmatched := make(map[recordKey][]diffEntry)
+	for match, entries := range unmatched {
+		sourceUnmatched[match.recordKey] = append(sourceUnmatched[match.recordKey], entries...)
+	}
+	keys := make([]recordKey, 0, len(sourceUnmatched)+len(targetUnmatched))
+	for key := range sourceUnmatched {
+		keys = append(keys, key)
+	}
+	for key := range targetUnmatched {
+		if _, ok := sourceUnmatched[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b recordKey) int {
+		if a.null != b.null {
+			if a.null {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.key, b.key)
+	})
+
+	diff.MissingRecords = make([]model.DiffRecord, 0)
+	diff.ExtraRecords = make([]model.DiffRecord, 0)
+	diff.DifferingRecords = make([]model.RecordDiff, 0)
+	bySeq := func(a, b diffEntry) int {
+		return cmp.Compare(a.seq, b.seq)
+	}
+	for _, key := range keys {
+		sourceEntries, targetEntries := sourceUnmatched[key], targetUnmatched[key]
+		slices.SortFunc(sourceEntries, bySeq)
+		slices.SortFunc(targetEntries, bySeq)
+		pairs := min(len(sourceEntries), len(targetEntries))
+		diff.Differing += int64(pairs)
+		diff.Missing += int64(len(sourceEntries) - pairs)
+		diff.Extra += int64(len(targetEntries) - pairs)
+		for i := 0; i < pairs && len(diff.DifferingRecords) < options.MaxReported; i++ {
+			diff.DifferingRecords = append(diff.DifferingRecords, model.RecordDiff{
+				Source: sourceEntries[i].record,
+				Target: targetEntries[i].record,
+			})
+		}
+		for _, entry := range sourceEntries[pairs:] {
+			if len(diff.MissingRecords) < options.MaxReported {
+				diff.MissingRecords = append(diff.MissingRecords, entry.record)
+			}
+		}
+		for _, entry := range targetEntries[pairs:] {
+			if len(diff.ExtraRecords) < options.MaxReported {
+				diff.ExtraRecords = append(diff.ExtraRecords, entry.record)
+			}
+		}
+	}
+
+	for i := range diff.DifferingRecords {
+		recordDiff := &diff.DifferingRecords[i]
+		sourceRecord, err := sourceService.fetchRecord(ctx, source.Topic, recordDiff.Source)
+		if err != nil {
+			return nil, err
+		}
+		targetRecord, err := targetService.fetchRecord(ctx, target.Topic, recordDiff.Target)
+		if err != nil {
+			return nil, err
+		}
+		if sourceRecord != nil && targetRecord != nil {
+			recordDiff.Changes = diffRecordValues(differ, sourceRecord, targetRecord)
+		}
+	}
+	return diff, nil
+}
+
+// streamDiffSource hands every record of the ranges of source to handle.
+func (k *KafkaService) streamDiffSource(
+	ctx context.Context,
+	source model.DiffSource,
+	handle func(record *model.Record) error,
+) error {
+	partitionInput, err := k.resolveCopyRanges(ctx, model.CopySource{Topic: source.Topic, Partitions: source.Partitions})
+	if err != nil {
+		return err
+	}
+	return k.streamMessagesForTopic(ctx, source.Topic, partitionInput, nil, handle)
+}
+
+// fetchRecord reads a single record again, returning nil when it has been removed since.
+func (k *KafkaService) fetchRecord(
+	ctx context.Context,
+	topic string,
+	diffRecord model.DiffRecord,
+) (*model.Record, error) {
+	var found *model.Record
+	partitionInput := model.PartitionInput{PartitionDetailsMap: map[int32]model.PartitionDetails{
+		diffRecord.Partition: {StartOffset: diffRecord.Offset, EndOffset: diffRecord.Offset},
+	}}
+	err := k.streamMessagesForTopic(ctx, topic, partitionInput, nil, func(record *model.Record) error {
+		if record.Offset == diffRecord.Offset {
+			found = record
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read record %d of partition %d again: %w",
+			diffRecord.Offset,
+			diffRecord.Partition,
+			err,
+		)
+	}
+	if found == nil {
+		k.logger.Warn(
+			"record to diff was removed",
+			zap.String("topic", topic),
+			zap.Int32("partition", diffRecord.Partition),
+			zap.Int64("offset", diffRecord.Offset),
+		)
+	}
+	return found, nil
+}
+
+func getRecordKey(record *model.Record) recordKey {
+	return recordKey{key: string(record.Key), null: record.Key == nil}
+}
+
+// hashRecordValue hashes JSON values structurally and other values by their bytes.
+func hashRecordValue(differ *jsonvalue.Differ, record *model.Record) uint64 {
+	if record.Message != nil && record.Message.ValueJsonPayload != nil {
+		return differ.Hash(*record.Message.ValueJsonPayload)
+	}
+	hasher := fnv.New64a()
+	if record.Value == nil {
+		hasher.Write([]byte{'x'})
+	} else {
+		hasher.Write([]byte{'r'})
+		hasher.Write(record.Value)
+	}
+	return hasher.Sum64()
+}
+
+func diffRecordValues(differ *jsonvalue.Differ, source *model.Record, target *model.Record) []model.ValueChange {
+	sourceValue, sourceJSON := recordJSONValue(source)
+	targetValue, targetJSON := recordJSONValue(target)
+	if sourceJSON && targetJSON {
+		return differ.Diff(sourceValue, targetValue)
+	}
+	return []model.ValueChange{{Path: "$", Type: model.ChangeChanged, Source: &sourceValue, Target: &targetValue}}
+}
+
+// recordJSONValue returns the JSON value of a record, or its value as a string when it isn't JSON.
+func recordJSONValue(record *model.Record) (model.JSONValue, bool) {
+	if record.Message != nil && record.Message.ValueJsonPayload != nil {
+		return *record.Message.ValueJsonPayload, true
+	}
+	if record.Value == nil {
+		return model.JSONValue{NullVal: true}, false
+	}
+	value := string(record.Value)
+	if record.Message != nil {
+		value = record.Message.Value
+	}
+	return model.JSONValue{StringVal: &value}, false
+}
+
+// toDiffRecord describes record, sharing the text of its key with key so that unmatched records hold it once.
+func toDiffRecord(record *model.Record, key recordKey) model.DiffRecord {
+	diffRecord := model.DiffRecord{
+		Partition: record.Partition,
+		Offset:    record.Offset,
+		Timestamp: record.Timestamp,
+	}
+	if !key.null {
+		text := key.key
+		if !utf8.Valid(record.Key) {
+			text = base64.StdEncoding.EncodeToString(record.Key)
+			diffRecord.Base64 = true
+		}
+		diffRecord.Key = &text
+	}
+	return diffRecord
+}
@@ -0,0 +1,243 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"sync"
+	"time"
+)
+
+const (
+	maxFetchAttempts = 3
+	// Enough for the first record batch of most partitions
+	firstFetchBytes = 64 * 1024
+)
+
+// FetchConfig bounds the fetch requests that message reads send to the brokers.
+type FetchConfig struct {
+	// The most bytes a fetch returns for a partition. A record batch that is larger is still returned whole
+	MaxPartitionBytes int32
+	// The most fetch requests in flight to a single broker, shared by all reads
+	BrokerConcurrency int
+	// How long a broker holds a fetch while it has no records at the requested offset
+	MaxWait time.Duration
+}
+
+func DefaultFetchConfig() FetchConfig {
+	return FetchConfig{
+		MaxPartitionBytes: 1024 * 1024,
+		BrokerConcurrency: 4,
+		MaxWait:           250 * time.Millisecond,
+	}
+}
+
+// fetcher reads partitions with Fetch requests sent straight to their leaders over the client's broker
+// connections, so reads don't set up and tear down a consumer each time.
+type fetcher struct {
+	client  sarama.Client
+	config  FetchConfig
+	version int16
+	// A semaphore per broker ID
+	brokerSlots      map[int32]chan struct{}
+	brokerSlotsMutex sync.Mutex
+}
+
+func newFetcher(client sarama.Client, clientConfig *sarama.Config, config FetchConfig) *fetcher {
+	return &fetcher{
+		client:      client,
+		config:      config,
+		version:     fetchRequestVersion(clientConfig.Version),
+		brokerSlots: make(map[int32]chan struct{}),
+	}
+}
+
+// fetchRequestVersion picks the newest Fetch request version the cluster understands, the same way sarama's
+// consumer does.
+func fetchRequestVersion(version sarama.KafkaVersion) int16 {
+	switch {
+	case version.IsAtLeast(sarama.V2_3_0_0):
+		return 11
+	case version.IsAtLeast(sarama.V2_1_0_0):
+		return 10
+	case version.IsAtLeast(sarama.V2_0_0_0):
+		return 8
+	case version.IsAtLeast(sarama.V1_1_0_0):
+		return 7
+	case version.IsAtLeast(sarama.V1_0_0_0):
+		return 6
+	case version.IsAtLeast(sarama.V0_11_0_0):
+		return 5
+	case version.IsAtLeast(sarama.V0_10_1_0):
+		return 3
+	case version.IsAtLeast(sarama.V0_10_0_0):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// fetch returns the records of partition from offset on, as much as one fetch of the configured size holds.
+func (f *fetcher) fetch(
+	ctx context.Context,
+	topic string,
+	partition int32,
+	offset int64,
+	isolation sarama.IsolationLevel,
+) (*sarama.FetchResponseBlock, error) {
+	return f.fetchUpTo(ctx, topic, partition, offset, isolation, f.config.MaxPartitionBytes)
+}
+
+// fetchFirst returns little more than the record batch at offset, for when only the first record is of interest.
+func (f *fetcher) fetchFirst(
+	ctx context.Context,
+	topic string,
+	partition int32,
+	offset int64,
+) (*sarama.FetchResponseBlock, error) {
+	maxBytes := min(firstFetchBytes, f.config.MaxPartitionBytes)
+	return f.fetchUpTo(ctx, topic, partition, offset, sarama.ReadUncommitted, maxBytes)
+}
+
+func (f *fetcher) fetchUpTo(
+	ctx context.Context,
+	topic string,
+	partition int32,
+	offset int64,
+	isolation sarama.IsolationLevel,
+	maxBytes int32,
+) (*sarama.FetchResponseBlock, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxFetchAttempts; attempt++ {
+		if attempt > 0 {
+			// The leader may have moved, or the connection to it broke
+			if err := f.client.RefreshMetadata(topic); err != nil {
+				lastErr = errors.Join(lastErr, err)
+			}
+		}
+		block, err := f.fetchFromLeader(ctx, topic, partition, offset, maxBytes, isolation)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			lastErr = err
+			if isRetriableFetchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		if len(block.RecordsSet) == 1 && isPartialRecords(block.RecordsSet[0]) && maxBytes < sarama.MaxResponseSize {
+			// Brokers that predate KIP-74 cut a batch larger than the limit short instead of returning it whole
+			maxBytes = min(maxBytes*2, sarama.MaxResponseSize)
+			attempt--
+			continue
+		}
+		return block, nil
+	}
+	return nil, fmt.Errorf("failed to fetch partition %d of topic %s: %w", partition, topic, lastErr)
+}
+
+func (f *fetcher) fetchFromLeader(
+	ctx context.Context,
+	topic string,
+	partition int32,
+	offset int64,
+	maxBytes int32,
+	isolation sarama.IsolationLevel,
+) (*sarama.FetchResponseBlock, error) {
+	leader, leaderEpoch, err := f.client.LeaderAndEpoch(topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	request := &sarama.FetchRequest{
+		Version:      f.version,
+		MaxWaitTime:  int32(f.config.MaxWait / time.Millisecond),
+		MinBytes:     1,
+		MaxBytes:     maxBytes,
+		Isolation:    isolation,
+		SessionEpoch: -1,
+	}
+	request.AddBlock(topic, partition, offset, maxBytes, leaderEpoch)
+
+	slots := f.getBrokerSlots(leader.ID())
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	type outcome struct {
+		response *sarama.FetchResponse
+		err      error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		// The slot is held until the broker answers, even when the caller gave up waiting
+		defer func() { <-slots }()
+		response, err := leader.Fetch(request)
+		done <- outcome{response: response, err: err}
+	}()
+	var response *sarama.FetchResponse
+	select {
+	case result := <-done:
+		if result.err != nil {
+			return nil, result.err
+		}
+		response = result.response
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	block := response.GetBlock(topic, partition)
+	if block == nil {
+		return nil, sarama.ErrIncompleteResponse
+	}
+	if !errors.Is(block.Err, sarama.ErrNoError) {
+		return nil, block.Err
+	}
+	if f.version < 4 {
+		// Versions without transactions report no last stable offset, and every record is stable
+		block.LastStableOffset = block.HighWaterMarkOffset
+	}
+	return block, nil
+}
+
+func (f *fetcher) getBrokerSlots(brokerID int32) chan struct{} {
+	f.brokerSlotsMutex.Lock()
+	defer f.brokerSlotsMutex.Unlock()
+	slots, ok := f.brokerSlots[brokerID]
+	if !ok {
+		slots = make(chan struct{}, max(1, f.config.BrokerConcurrency))
+		f.brokerSlots[brokerID] = slots
+	}
+	return slots
+}
+
+func isRetriableFetchError(err error) bool {
+	var kafkaErr sarama.KError
+	if !errors.As(err, &kafkaErr) {
+		// A network error, after which sarama reconnects to the broker
+		return true
+	}
+	switch kafkaErr {
+	case sarama.ErrNotLeaderForPartition,
+		sarama.ErrLeaderNotAvailable,
+		sarama.ErrReplicaNotAvailable,
+		sarama.ErrFencedLeaderEpoch,
+		sarama.ErrUnknownLeaderEpoch,
+		sarama.ErrUnknownTopicOrPartition:
+		return true
+	default:
+		return false
+	}
+}
+
+func isPartialRecords(records *sarama.Records) bool {
+	if records.RecordBatch != nil {
+		return records.RecordBatch.PartialTrailingRecord && len(records.RecordBatch.Records) == 0
+	}
+	if records.MsgSet != nil {
+		return records.MsgSet.PartialTrailingMessage && len(records.MsgSet.Messages) == 0
+	}
+	return false
+}
@@ -1,14 +1,18 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
 	"github.com/IBM/sarama"
 	"go.uber.org/zap"
 )
 
-func (k *KafkaService) GetConsumerGroupsDetailsListeningToTopic(topic string) ([]model.ConsumerGroupDetails, error) {
-	consumerGroups, err := k.GetConsumerGroups()
+func (k *KafkaService) GetConsumerGroupsDetailsListeningToTopic(
+	ctx context.Context,
+	topic string,
+) ([]model.ConsumerGroupDetails, error) {
+	consumerGroups, err := k.GetConsumerGroups(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get consumer groups listening to topic %s: %w", topic, err)
 	}
@@ -16,7 +20,7 @@ func (k *KafkaService) GetConsumerGroupsDetailsListeningToTopic(topic string) ([
 	consumerGroupDetailsMap := make(map[string]*model.ConsumerGroupDetails)
 
 	consumerGroupToConsumersToTopicToPartitionsMap, err :=
-		k.getConsumerGroupToConsumersToTopicsToPartitionsMap(consumerGroups, map[string]bool{topic: true})
+		k.getConsumerGroupToConsumersToTopicsToPartitionsMap(ctx, consumerGroups, map[string]bool{topic: true})
 	if err != nil {
 		k.logger.Error(
 			"%s failed to get consumer group details for topic",
@@ -28,7 +32,11 @@ func (k *KafkaService) GetConsumerGroupsDetailsListeningToTopic(topic string) ([
 
 	for consumerGroup, consumersToTopicToPartitionsMap := range consumerGroupToConsumersToTopicToPartitionsMap {
 		topicPartitionsMap := k.getTopicPartitionsMap(consumersToTopicToPartitionsMap)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		consumerToTopicToPartitionOffsetsMap, err := k.getConsumerToTopicToPartitionOffsetsMap(
+			ctx,
 			consumerGroup,
 			consumersToTopicToPartitionsMap,
 			topicPartitionsMap,
@@ -68,8 +76,8 @@ func (k *KafkaService) GetConsumerGroupsDetailsListeningToTopic(topic string) ([
 	return consumerGroupDetails, nil
 }
 
-func (k *KafkaService) GetConsumerGroups() ([]string, error) {
-	consumerToTypeMap, err := k.admin.ListConsumerGroups()
+func (k *KafkaService) GetConsumerGroups(ctx context.Context) ([]string, error) {
+	consumerToTypeMap, err := runWithContext(ctx, k.admin.ListConsumerGroups)
 	if err != nil {
 		k.logger.Error("KafkaService can't get consumers: failed to get consumers", zap.Error(err))
 		return nil, fmt.Errorf("failed to get consumer groups: %w", err)
@@ -84,12 +92,15 @@ func (k *KafkaService) GetConsumerGroups() ([]string, error) {
 }
 
 func (k *KafkaService) getConsumerGroupToConsumersToTopicsToPartitionsMap(
+	ctx context.Context,
 	consumerGroups []string,
 	topicsToInclude map[string]bool,
 ) (map[string]map[string]map[string]map[int32]bool, error) {
 	returnMap := make(map[string]map[string]map[string]map[int32]bool)
 
-	consumerGroupDescription, err := k.admin.DescribeConsumerGroups(consumerGroups)
+	consumerGroupDescription, err := runWithContext(ctx, func() ([]*sarama.GroupDescription, error) {
+		return k.admin.DescribeConsumerGroups(consumerGroups)
+	})
 	if err != nil {
 		k.logger.Error("failed to get consumer group descriptions", zap.Error(err))
 		return nil, fmt.Errorf("failed to get consumer group descriptions: %w", err)
@@ -158,12 +169,15 @@ func (k *KafkaService) getTopicPartitionsMap(
 }
 
 func (k *KafkaService) getConsumerToTopicToPartitionOffsetsMap(
+	ctx context.Context,
 	consumerGroupId string,
 	consumerToTopicToPartitionMap map[string]map[string]map[int32]bool,
 	topicPartitionMap map[string]map[int32]bool,
 ) (map[string]map[string]map[int32]model.ConsumerDetails, error) {
 	topicToPartitionList := getTopicToPartitionListFromMap(topicPartitionMap)
-	committedOffsets, err := k.admin.ListConsumerGroupOffsets(consumerGroupId, topicToPartitionList)
+	committedOffsets, err := runWithContext(ctx, func() (*sarama.OffsetFetchResponse, error) {
+		return k.admin.ListConsumerGroupOffsets(consumerGroupId, topicToPartitionList)
+	})
 	if err != nil {
 		k.logger.Error(
 			"failed to fetch committed offsets",
@@ -177,7 +191,10 @@ func (k *KafkaService) getConsumerToTopicToPartitionOffsetsMap(
 
 	for topic, partitions := range topicPartitionMap {
 		for partition, _ := range partitions {
-			highWatermark, err := k.client.GetOffset(topic, partition, sarama.OffsetNewest)
+			highWatermark, err := k.getOffset(ctx, topic, partition, sarama.OffsetNewest)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			if err != nil {
 				k.logger.Error(
 					"failed to fetch high watermark",
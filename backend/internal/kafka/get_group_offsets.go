@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"slices"
+)
+
+// GetGroupOffsets returns the committed offset and high watermark of every partition that a consumer group committed
+// an offset for, by group and topic, whether or not the group has members right now. Groups whose offsets can't be
+// read are left out, as are topics that no longer exist.
+func (k *KafkaService) GetGroupOffsets(
+	ctx context.Context,
+) (map[string]map[string]map[int32]model.GroupPartitionOffsets, error) {
+	groups, err := k.GetConsumerGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	committedOffsets := make(map[string]map[string]map[int32]int64, len(groups))
+	topicPartitions := make(map[string][]int32)
+	for _, group := range groups {
+		groupOffsets, err := k.getCommittedOffsets(ctx, group)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			k.logger.Warn(
+				"skipping consumer group whose committed offsets can't be read",
+				zap.String("consumerGroup", group),
+				zap.Error(err),
+			)
+			continue
+		}
+		committedOffsets[group] = groupOffsets
+		for topic, partitions := range groupOffsets {
+			for partition := range partitions {
+				if !slices.Contains(topicPartitions[topic], partition) {
+					topicPartitions[topic] = append(topicPartitions[topic], partition)
+				}
+			}
+		}
+	}
+
+	// Groups keep the offsets they committed for topics that were deleted since
+	for topic, partitions := range topicPartitions {
+		existing, err := k.client.Partitions(topic)
+		if err != nil {
+			delete(topicPartitions, topic)
+			continue
+		}
+		topicPartitions[topic] = slices.DeleteFunc(partitions, func(partition int32) bool {
+			return !slices.Contains(existing, partition)
+		})
+	}
+	highWatermarks, err := k.getTopicOffsets(ctx, topicPartitions, sarama.OffsetNewest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get high watermarks: %w", err)
+	}
+
+	offsets := make(map[string]map[string]map[int32]model.GroupPartitionOffsets, len(committedOffsets))
+	for group, groupOffsets := range committedOffsets {
+		offsets[group] = make(map[string]map[int32]model.GroupPartitionOffsets, len(groupOffsets))
+		for topic, partitions := range groupOffsets {
+			for partition, committed := range partitions {
+				highWatermark, ok := highWatermarks[topic][partition]
+				if !ok {
+					continue
+				}
+				if offsets[group][topic] == nil {
+					offsets[group][topic] = make(map[int32]model.GroupPartitionOffsets, len(partitions))
+				}
+				offsets[group][topic][partition] = model.GroupPartitionOffsets{
+					Committed:     committed,
+					HighWatermark: highWatermark,
+				}
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// getCommittedOffsets returns the offsets group committed for every partition of every topic.
+func (k *KafkaService) getCommittedOffsets(ctx context.Context, group string) (map[string]map[int32]int64, error) {
+	response, err := runWithContext(ctx, func() (*sarama.OffsetFetchResponse, error) {
+		// Without partitions, the offsets of every partition the group committed for are returned
+		return k.admin.ListConsumerGroupOffsets(group, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+	if !errors.Is(response.Err, sarama.ErrNoError) {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", response.Err)
+	}
+	offsets := make(map[string]map[int32]int64, len(response.Blocks))
+	for topic, blocks := range response.Blocks {
+		for partition, block := range blocks {
+			// Partitions without a committed offset report -1
+			if !errors.Is(block.Err, sarama.ErrNoError) || block.Offset < 0 {
+				continue
+			}
+			if offsets[topic] == nil {
+				offsets[topic] = make(map[int32]int64, len(blocks))
+			}
+			offsets[topic][partition] = block.Offset
+		}
+	}
+	return offsets, nil
+}
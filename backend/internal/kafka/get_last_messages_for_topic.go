@@ -1,109 +1,104 @@
 package kafka
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
 	"github.com/IBM/sarama"
 	"go.uber.org/zap"
+	"slices"
 	"sync"
-	"time"
 )
 
-const consumerTimeout = 5 * time.Second
-const partitionWorkerCount = 10
-
+// GetLastMessagesForTopic collects the messages in the requested ranges of every partition. When ctx is done the
+// messages fetched so far are returned with ctx's error, and no fetch outlives the call.
 func (k *KafkaService) GetLastMessagesForTopic(
 	ctx context.Context,
 	topic string,
 	partitionData model.PartitionInput,
 ) ([]*model.Message, error) {
-	topicMetaData, err := k.admin.DescribeTopics([]string{topic})
+	topicMessages, err := k.GetMessagesForTopic(ctx, topic, partitionData, model.FetchOptions{})
+	if topicMessages == nil {
+		return nil, err
+	}
+	return topicMessages.Messages, err
+}
+
+// GetMessagesForTopic is GetLastMessagesForTopic, along with how much of the range of each partition was read.
+// Messages carry the state of the transaction they were produced in, and are listed in the order of options. It
+// returns nil when none of the requested partitions exist.
+func (k *KafkaService) GetMessagesForTopic(
+	ctx context.Context,
+	topic string,
+	partitionData model.PartitionInput,
+	options model.FetchOptions,
+) (*model.TopicMessages, error) {
+	err := k.authorizer.Authorize(ctx, auth.OperationViewMessages, auth.Resource{Topic: topic})
 	if err != nil {
-		k.logger.Error(
-			"failed to get topic metadata",
-			zap.String("topic", topic),
-			zap.Error(err),
-		)
 		return nil, err
 	}
-	if len(topicMetaData) == 0 {
-		k.logger.Warn(
-			"topic not found",
-			zap.String("topic", topic),
-		)
-		return nil, nil
+	partitionArgs, err := k.getRequestedPartitions(ctx, topic, partitionData)
+	if err != nil {
+		return nil, err
 	}
-	topicDetail := topicMetaData[0]
-	if len(topicDetail.Partitions) == 0 {
-		k.logger.Warn(
-			"topic has no partitions",
-			zap.String("topic", topic),
-		)
+	numValidPartitions := len(partitionArgs)
+	if numValidPartitions == 0 {
 		return nil, nil
 	}
-	numValidPartitions := 0
-	for _, partition := range topicDetail.Partitions {
-		_, ok := partitionData.PartitionDetailsMap[partition.ID]
-		if !ok {
-			continue
-		}
-		numValidPartitions++
-	}
 
-	lastMessages := make([]*model.Message, 0)
-	minOfPartitionWorkerCountAndPartitions := min(partitionWorkerCount, numValidPartitions)
-	partitionJobs := make(chan getMessagesForPartitionArgs, minOfPartitionWorkerCountAndPartitions)
-	resultsChannel := make(chan []*model.Message, numValidPartitions)
+	type partitionResult struct {
+		messages []*model.Message
+		coverage model.PartitionCoverage
+	}
+	// Buffered for every partition, so that fetches never block on a caller that stopped reading
+	resultsChannel := make(chan partitionResult, numValidPartitions)
 
+	// Fetches are limited per broker by the fetcher rather than by the number of partitions fetched at once
 	var wg sync.WaitGroup
-	for i := 0; i < minOfPartitionWorkerCountAndPartitions; i++ {
+	for _, args := range partitionArgs {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for args := range partitionJobs {
-				messages, err := k.getMessagesForPartition(ctx, args)
-				if err != nil {
+			messages, coverage, err := k.getMessagesForPartition(ctx, args, options)
+			if err != nil {
+				if ctx.Err() == nil {
 					k.logger.Error(
 						"failed to fetch messages for partition",
 						zap.String("topic", args.topic),
 						zap.Int32("partition", args.partition),
 						zap.Error(err),
 					)
-					resultsChannel <- nil
-					continue
 				}
-				resultsChannel <- messages
+				coverage.Error = err.Error()
 			}
+			resultsChannel <- partitionResult{messages: messages, coverage: coverage}
 		}()
 	}
+	// The fetches stop promptly once ctx is done
+	defer wg.Wait()
 
-	for _, partition := range topicDetail.Partitions {
-		partitionDetails, ok := partitionData.PartitionDetailsMap[partition.ID]
-		if !ok {
-			continue
-		}
-
-		partitionJobs <- getMessagesForPartitionArgs{
-			topic:       topic,
-			partition:   partition.ID,
-			startOffset: partitionDetails.StartOffset,
-			endOffset:   partitionDetails.EndOffset,
-		}
+	partitionMessages := make([][]*model.Message, 0, numValidPartitions)
+	topicMessages := &model.TopicMessages{
+		Partitions: make([]model.PartitionCoverage, 0, numValidPartitions),
 	}
-	close(partitionJobs)
-
 	for i := 0; i < numValidPartitions; i++ {
 		select {
-		case messages := <-resultsChannel:
-			if messages != nil && len(messages) > 0 {
-				lastMessages = append(lastMessages, messages...)
-			}
+		case result := <-resultsChannel:
+			partitionMessages = append(partitionMessages, result.messages)
+			topicMessages.Partitions = append(topicMessages.Partitions, result.coverage)
 		case <-ctx.Done():
-			return lastMessages, ctx.Err()
+			topicMessages.Messages = orderMessages(partitionMessages, options.Order)
+			return topicMessages, ctx.Err()
 		}
 	}
-	return lastMessages, nil
+	topicMessages.Messages = orderMessages(partitionMessages, options.Order)
+	slices.SortFunc(topicMessages.Partitions, func(a, b model.PartitionCoverage) int {
+		return cmp.Compare(a.Partition, b.Partition)
+	})
+	return topicMessages, nil
 }
 
 type getMessagesForPartitionArgs struct {
@@ -113,16 +108,202 @@ type getMessagesForPartitionArgs struct {
 	endOffset   int64
 }
 
+// getRequestedPartitions matches the requested partitions against the partitions the topic actually has.
+func (k *KafkaService) getRequestedPartitions(
+	ctx context.Context,
+	topic string,
+	partitionData model.PartitionInput,
+) ([]getMessagesForPartitionArgs, error) {
+	partitions, err := k.getTopicPartitions(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	partitionArgs := make([]getMessagesForPartitionArgs, 0, len(partitions))
+	for _, partition := range partitions {
+		partitionDetails, ok := partitionData.PartitionDetailsMap[partition]
+		if !ok {
+			continue
+		}
+		partitionArgs = append(partitionArgs, getMessagesForPartitionArgs{
+			topic:       topic,
+			partition:   partition,
+			startOffset: partitionDetails.StartOffset,
+			endOffset:   partitionDetails.EndOffset,
+		})
+	}
+	return partitionArgs, nil
+}
+
+// getTopicPartitions returns the IDs of the partitions of topic, or none when the topic doesn't exist.
+func (k *KafkaService) getTopicPartitions(ctx context.Context, topic string) ([]int32, error) {
+	topicMetaData, err := runWithContext(ctx, func() ([]*sarama.TopicMetadata, error) {
+		return k.admin.DescribeTopics([]string{topic})
+	})
+	if err != nil {
+		k.logger.Error(
+			"failed to get topic metadata",
+			zap.String("topic", topic),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	if len(topicMetaData) == 0 {
+		k.logger.Warn(
+			"topic not found",
+			zap.String("topic", topic),
+		)
+		return nil, nil
+	}
+	topicDetail := topicMetaData[0]
+	if len(topicDetail.Partitions) == 0 {
+		k.logger.Warn(
+			"topic has no partitions",
+			zap.String("topic", topic),
+		)
+		return nil, nil
+	}
+	partitions := make([]int32, 0, len(topicDetail.Partitions))
+	for _, partition := range topicDetail.Partitions {
+		partitions = append(partitions, partition.ID)
+	}
+	return partitions, nil
+}
+
+// getMessagesForPartition returns the messages read so far and how far reading got, also when it fails.
 func (k *KafkaService) getMessagesForPartition(
 	ctx context.Context,
 	input getMessagesForPartitionArgs,
-) ([]*model.Message, error) {
+	options model.FetchOptions,
+) ([]*model.Message, model.PartitionCoverage, error) {
 	topic := input.topic
 	partition := input.partition
-	startOffset := input.startOffset
-	endOffset := input.endOffset
+	coverage := model.PartitionCoverage{
+		Partition:   partition,
+		StartOffset: -1,
+		EndOffset:   -1,
+		NextOffset:  -1,
+	}
+	isolationLevel := options.IsolationLevel
+	if isolationLevel == "" {
+		isolationLevel = model.ReadUncommitted
+	}
 
-	newestOffset, err := k.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	startOffset, endOffset, hasMessages, err := k.resolvePartitionRange(
+		ctx,
+		topic,
+		partition,
+		input.startOffset,
+		input.endOffset,
+	)
+	if err != nil {
+		return nil, coverage, err
+	}
+	if !hasMessages {
+		coverage.Complete = true
+		return nil, coverage, nil
+	}
+
+	messages := make([]*model.Message, 0)
+	// Messages of transactions that were open when read, by producer, until the marker that ends them is read
+	openTransactions := make(map[int64][]*model.Message)
+	endTransaction := func(record *partitionRecord) {
+		for _, message := range openTransactions[record.producerID] {
+			message.TransactionState = record.transactionState
+		}
+		delete(openTransactions, record.producerID)
+	}
+	coverage, err = k.consumePartition(
+		ctx,
+		topic,
+		partition,
+		startOffset,
+		endOffset,
+		isolationLevel,
+		func(record *partitionRecord) bool {
+			if record.controlType != "" {
+				endTransaction(record)
+				if options.IncludeControlRecords {
+					messages = append(messages, toControlMessage(record))
+				}
+				return true
+			}
+			decodedMessage, err := k.decodeKeyAndValue(record.message)
+			if err != nil {
+				k.logger.Error(
+					"failed to decode message",
+					zap.String("topic", topic),
+					zap.Int32("partition", partition),
+					zap.Error(err),
+				)
+				return true
+			}
+			decodedMessage.TransactionState = record.transactionState
+			if record.transactionState == model.TransactionOpen {
+				openTransactions[record.producerID] = append(openTransactions[record.producerID], decodedMessage)
+			}
+			messages = append(messages, decodedMessage)
+			return true
+		},
+	)
+	if err != nil || len(openTransactions) == 0 || coverage.NextOffset >= coverage.HighWatermark {
+		return messages, coverage, err
+	}
+
+	// The markers of transactions that were open within the range may follow it
+	_, err = k.consumePartition(
+		ctx,
+		topic,
+		partition,
+		coverage.NextOffset,
+		coverage.HighWatermark-1,
+		model.ReadUncommitted,
+		func(record *partitionRecord) bool {
+			if record.controlType != "" {
+				endTransaction(record)
+			}
+			return len(openTransactions) > 0
+		},
+	)
+	if err != nil && ctx.Err() == nil {
+		// The messages keep their open state, which is all that could be found out
+		k.logger.Warn(
+			"failed to read the end of open transactions",
+			zap.String("topic", topic),
+			zap.Int32("partition", partition),
+			zap.Error(err),
+		)
+		err = nil
+	}
+	return messages, coverage, err
+}
+
+// toControlMessage shows a transaction marker as a message of its own.
+func toControlMessage(record *partitionRecord) *model.Message {
+	return &model.Message{
+		Topic:            record.message.Topic,
+		Partition:        record.message.Partition,
+		Offset:           record.message.Offset,
+		Timestamp:        record.message.Timestamp,
+		KeyPayloadType:   model.StringPayload,
+		ValuePayloadType: model.StringPayload,
+		TransactionState: record.transactionState,
+		ControlType:      record.controlType,
+	}
+}
+
+// resolvePartitionRange turns the requested, possibly negative, offsets into absolute inclusive offsets.
+// hasMessages is false when the partition is empty.
+func (k *KafkaService) resolvePartitionRange(
+	ctx context.Context,
+	topic string,
+	partition int32,
+	startOffset int64,
+	endOffset int64,
+) (resolvedStart int64, resolvedEnd int64, hasMessages bool, err error) {
+	newestOffset, err := k.getOffset(ctx, topic, partition, sarama.OffsetNewest)
+	if ctx.Err() != nil {
+		return 0, 0, false, ctx.Err()
+	}
 	if err != nil {
 		k.logger.Error(
 			"failed to get newest offset",
@@ -130,10 +311,10 @@ func (k *KafkaService) getMessagesForPartition(
 			zap.Int32("partition", partition),
 			zap.Error(err),
 		)
-		return nil, fmt.Errorf("failed to get newest offset: %w", err)
+		return 0, 0, false, fmt.Errorf("failed to get newest offset: %w", err)
 	}
 	if newestOffset == 0 {
-		return nil, nil
+		return 0, 0, false, nil
 	}
 
 	if startOffset > 0 {
@@ -159,75 +340,103 @@ func (k *KafkaService) getMessagesForPartition(
 				zap.Int64("startOffset", startOffset),
 				zap.Int64("endOffset", endOffset),
 			)
-			return nil, fmt.Errorf(
+			return 0, 0, false, fmt.Errorf(
 				"calculated start offset %d is greater than end offset %d",
 				startOffset,
 				endOffset,
 			)
 		}
 	}
+	return startOffset, endOffset, true, nil
+}
 
-	consumer, err := sarama.NewConsumerFromClient(k.client)
-	if err != nil {
-		k.logger.Error(
-			"failed to create consumer",
-			zap.Error(err),
-		)
-		return nil, fmt.Errorf("failed to create consumer: %w", err)
-	}
-	defer consumer.Close()
-
-	partitionConsumer, err := consumer.ConsumePartition(topic, partition, startOffset)
-	if err != nil {
-		k.logger.Error(
-			"failed to create partition consumer",
-			zap.String("topic", topic),
-			zap.Int32("partition", partition),
-			zap.Error(err),
-		)
-		return nil, fmt.Errorf("failed to create partition consumer: %w", err)
+// consumePartition passes the records between startOffset and endOffset to handle, until handle returns false or
+// reading reaches endOffset or the high watermark. Offsets are followed rather than messages counted, since
+// compaction and transaction markers leave offsets without messages. Records removed by retention since the range
+// was resolved are skipped. Under read_committed, records of aborted transactions are left out and reading stops
+// at the last stable offset. Transaction markers are always passed to handle. The returned coverage tells how far
+// reading got, also when it fails.
+func (k *KafkaService) consumePartition(
+	ctx context.Context,
+	topic string,
+	partition int32,
+	startOffset int64,
+	endOffset int64,
+	isolationLevel model.IsolationLevel,
+	handle func(record *partitionRecord) bool,
+) (model.PartitionCoverage, error) {
+	coverage := model.PartitionCoverage{
+		Partition:   partition,
+		StartOffset: startOffset,
+		EndOffset:   endOffset,
+		NextOffset:  startOffset,
 	}
-	defer partitionConsumer.Close()
+	tracker := newTransactionTracker()
+	// Records below the last stable offset are fetched read_committed whatever the isolation level, since only
+	// those fetches report which transactions were aborted
+	isolation := sarama.ReadCommitted
+	for coverage.NextOffset <= endOffset {
+		offset := coverage.NextOffset
+		block, err := k.fetcher.fetch(ctx, topic, partition, offset, isolation)
+		if errors.Is(err, sarama.ErrOffsetOutOfRange) {
+			oldestOffset, oldestErr := k.getOffset(ctx, topic, partition, sarama.OffsetOldest)
+			if oldestErr == nil && oldestOffset > offset {
+				coverage.NextOffset = oldestOffset
+				continue
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return coverage, ctx.Err()
+			}
+			k.logger.Error(
+				"failed to fetch messages",
+				zap.String("topic", topic),
+				zap.Int32("partition", partition),
+				zap.Int64("offset", offset),
+				zap.Error(err),
+			)
+			return coverage, fmt.Errorf("failed to fetch messages: %w", err)
+		}
+		coverage.HighWatermark = block.HighWaterMarkOffset
+		coverage.LastStableOffset = block.LastStableOffset
+		readableOffset := block.HighWaterMarkOffset
+		if isolationLevel == model.ReadCommitted {
+			readableOffset = block.LastStableOffset
+		} else if isolation == sarama.ReadCommitted && offset >= block.LastStableOffset && offset < readableOffset {
+			isolation = sarama.ReadUncommitted
+			continue
+		}
+		tracker.add(block.AbortedTransactions, offset)
 
-	i := 0
-	numberMessages := int(endOffset - startOffset + 1)
-	messages := make([]*model.Message, 0)
-	messageCtx, cancel := context.WithTimeout(ctx, consumerTimeout)
-	defer cancel()
-loop:
-	for {
-		select {
-		case message, ok := <-partitionConsumer.Messages():
-			if !ok {
-				k.logger.Warn(
-					"partition consumer messages channel closed",
-					zap.String("topic", topic),
-					zap.Int32("partition", partition),
-				)
-				break loop
-			} else {
-				decodedMessage, err := k.decodeKeyAndValue(message)
-				if err != nil {
-					k.logger.Error(
-						"failed to decode message",
-						zap.String("topic", topic),
-						zap.Int32("partition", partition),
-						zap.Error(err),
-					)
-				} else {
-					messages = append(messages, decodedMessage)
-				}
-				i++
-				if i >= numberMessages {
-					break loop
-				}
+		records, next := fetchedRecords(topic, partition, offset, block, tracker, isolation == sarama.ReadCommitted)
+		for _, record := range records {
+			if record.message.Offset > endOffset {
+				break
+			}
+			if isolationLevel == model.ReadCommitted && record.transactionState == model.TransactionAborted &&
+				record.controlType == "" {
+				continue
+			}
+			if !handle(record) {
+				coverage.NextOffset = record.message.Offset + 1
+				coverage.Complete = coverage.NextOffset > endOffset
+				return coverage, nil
 			}
-		case <-messageCtx.Done():
-			k.logger.Info("timeout reached while fetching messages", zap.Error(ctx.Err()))
-			break loop
+		}
+		coverage.NextOffset = next
+		if len(block.RecordsSet) == 0 {
+			// The broker answers with no records only when none are left below what it lets us read, which happens
+			// when the last records were compacted away
+			coverage.NextOffset = max(next, readableOffset)
+		}
+		if coverage.NextOffset >= readableOffset {
+			break
 		}
 	}
-	return messages, nil
+	coverage.NextOffset = min(coverage.NextOffset, endOffset+1)
+	coverage.Complete = true
+	return coverage, nil
 }
 
 func (k *KafkaService) decodeKeyAndValue(
@@ -245,12 +454,11 @@ func (k *KafkaService) decodeKeyAndValue(
 		return nil, fmt.Errorf("failed to decode key and value: %w", err)
 	}
 	var decodedKeyJSONPayload *model.JSONValue = nil
-	if decodedKeyAndValue.Key.Type == model.JSONPayload || decodedKeyAndValue.Key.Type == model.ConsumerOffsetPayload {
+	if hasJSONPayload(decodedKeyAndValue.Key.Type) {
 		decodedKeyJSONPayload = &decodedKeyAndValue.Key.JSONPayload
 	}
 	var decodedValueJSONPayload *model.JSONValue = nil
-	if decodedKeyAndValue.Value.Type == model.JSONPayload ||
-		decodedKeyAndValue.Value.Type == model.ConsumerOffsetPayload {
+	if hasJSONPayload(decodedKeyAndValue.Value.Type) {
 		decodedValueJSONPayload = &decodedKeyAndValue.Value.JSONPayload
 	}
 	return &model.Message{
@@ -263,6 +471,17 @@ func (k *KafkaService) decodeKeyAndValue(
 		Value:            decodedKeyAndValue.Value.Payload,
 		ValuePayloadType: decodedKeyAndValue.Value.Type,
 		ValueJsonPayload: decodedValueJSONPayload,
+		KeyCompression:   decodedKeyAndValue.Key.Compression,
+		ValueCompression: decodedKeyAndValue.Value.Compression,
 		Timestamp:        message.Timestamp,
 	}, nil
 }
+
+func hasJSONPayload(payloadType model.PayloadType) bool {
+	switch payloadType {
+	case model.JSONPayload, model.ConsumerOffsetPayload, model.TransactionStatePayload:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,199 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"time"
+)
+
+const (
+	// How far back from now the search for the start of the latest messages first looks
+	initialLatestWindow = time.Second
+	// The most lookups the search makes to narrow down a window that spans far more offsets than needed
+	maxLatestNarrowingSteps = 20
+	// The most times the search is widened when the offsets it found hold too few messages
+	maxLatestAttempts = 4
+)
+
+// GetLatestMessagesForTopic returns the count newest messages of the whole topic by timestamp, rather than the same
+// number of messages from every partition. It searches for the timestamp after which the topic holds count offsets,
+// and reads each partition from its first offset at that timestamp on, so that busy partitions contribute more
+// messages than idle ones. Messages are listed in the order of options, and coverage is only reported for the
+// partitions that were read. It returns nil when the topic doesn't exist.
+func (k *KafkaService) GetLatestMessagesForTopic(
+	ctx context.Context,
+	topic string,
+	count int,
+	options model.FetchOptions,
+) (*model.TopicMessages, error) {
+	err := k.authorizer.Authorize(ctx, auth.OperationViewMessages, auth.Resource{Topic: topic})
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := k.getTopicPartitions(ctx, topic)
+	if err != nil || len(partitions) == 0 {
+		return nil, err
+	}
+	// Messages produced while reading are left out, so that the partitions are read up to the same moment
+	newestOffsets, err := k.getOffsets(ctx, topic, partitions, sarama.OffsetNewest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newest offsets: %w", err)
+	}
+	oldestOffsets, err := k.getOffsets(ctx, topic, partitions, sarama.OffsetOldest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oldest offsets: %w", err)
+	}
+	offsets := latestOffsets{partitions: partitions, oldest: oldestOffsets, newest: newestOffsets}
+
+	fetchOptions := options
+	fetchOptions.Order = model.OrderTimestamp
+	needed := int64(count)
+	for attempt := 1; ; attempt++ {
+		start, err := k.findLatestStart(ctx, topic, offsets, needed)
+		if err != nil {
+			return nil, err
+		}
+		partitionData := model.PartitionInput{PartitionDetailsMap: make(map[int32]model.PartitionDetails)}
+		for _, partition := range partitions {
+			if start.offsets[partition] < newestOffsets[partition] {
+				partitionData.PartitionDetailsMap[partition] = model.PartitionDetails{
+					StartOffset: start.offsets[partition],
+					EndOffset:   newestOffsets[partition] - 1,
+				}
+			}
+		}
+		if len(partitionData.PartitionDetailsMap) == 0 {
+			return &model.TopicMessages{
+				Messages:   make([]*model.Message, 0),
+				Partitions: make([]model.PartitionCoverage, 0),
+			}, nil
+		}
+		topicMessages, err := k.GetMessagesForTopic(ctx, topic, partitionData, fetchOptions)
+		if err != nil || topicMessages == nil {
+			return topicMessages, err
+		}
+
+		// Every message at or after the start timestamp was read, but the messages before the first offset at it may
+		// have later timestamps than some that were read. Only those at or after it are sure to be among the newest
+		recent := int64(0)
+		for _, message := range topicMessages.Messages {
+			if message.Timestamp.UnixMilli() >= start.timestamp {
+				recent++
+			}
+		}
+		if recent >= int64(count) || start.fromOldest || attempt == maxLatestAttempts {
+			topicMessages.Messages = newestMessages(topicMessages.Messages, count, options.Order)
+			return topicMessages, nil
+		}
+		// Transaction markers, aborted transactions and compaction leave offsets without messages
+		needed *= 2
+	}
+}
+
+type latestOffsets struct {
+	partitions []int32
+	oldest     map[int32]int64
+	newest     map[int32]int64
+}
+
+// span is the number of offsets from start up to the newest offset of every partition.
+func (o latestOffsets) span(start map[int32]int64) int64 {
+	span := int64(0)
+	for _, partition := range o.partitions {
+		span += o.newest[partition] - start[partition]
+	}
+	return span
+}
+
+type latestStart struct {
+	// The first offset of every partition at or after timestamp
+	offsets map[int32]int64
+	// In milliseconds
+	timestamp int64
+	// Whether the whole topic is read, since it holds no more than the offsets needed
+	fromOldest bool
+}
+
+// findLatestStart searches for the latest timestamp after which the topic holds at least needed offsets. A window
+// back from now is doubled until it spans enough offsets, and then narrowed down for as long as it spans more than
+// twice as many as needed.
+func (k *KafkaService) findLatestStart(
+	ctx context.Context,
+	topic string,
+	offsets latestOffsets,
+	needed int64,
+) (latestStart, error) {
+	if offsets.span(offsets.oldest) <= needed {
+		return latestStart{offsets: offsets.oldest, fromOldest: true}, nil
+	}
+	now := time.Now().UnixMilli()
+	lookup := func(window int64) (map[int32]int64, error) {
+		start, err := k.getOffsets(ctx, topic, offsets.partitions, now-window)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get offsets for time: %w", err)
+		}
+		for partition, offset := range start {
+			if offset < 0 {
+				start[partition] = offsets.newest[partition]
+			}
+		}
+		return start, nil
+	}
+
+	// A window known to span too few offsets, and one known to span enough
+	narrow := int64(0)
+	wide := initialLatestWindow.Milliseconds()
+	var wideStart map[int32]int64
+	for {
+		if wide >= now {
+			return latestStart{offsets: offsets.oldest, fromOldest: true}, nil
+		}
+		start, err := lookup(wide)
+		if err != nil {
+			return latestStart{}, err
+		}
+		if offsets.span(start) >= needed {
+			wideStart = start
+			break
+		}
+		narrow = wide
+		wide *= 2
+	}
+	for step := 0; step < maxLatestNarrowingSteps && wide-narrow > 1 && offsets.span(wideStart) > 2*needed; step++ {
+		middle := narrow + (wide-narrow)/2
+		start, err := lookup(middle)
+		if err != nil {
+			return latestStart{}, err
+		}
+		if offsets.span(start) >= needed {
+			wide, wideStart = middle, start
+		} else {
+			narrow = middle
+		}
+	}
+	return latestStart{offsets: wideStart, timestamp: now - wide}, nil
+}
+
+// newestMessages keeps the count newest of messages, which are in timestamp order, and lists them in order.
+func newestMessages(messages []*model.Message, count int, order model.MessageOrder) []*model.Message {
+	messages = messages[max(0, len(messages)-count):]
+	if order == model.OrderTimestamp {
+		return messages
+	}
+	// The merge left the messages of every partition in offset order
+	var partitionMessages [][]*model.Message
+	indexes := make(map[int32]int)
+	for _, message := range messages {
+		index, ok := indexes[message.Partition]
+		if !ok {
+			index = len(partitionMessages)
+			indexes[message.Partition] = index
+			partitionMessages = append(partitionMessages, nil)
+		}
+		partitionMessages[index] = append(partitionMessages[index], message)
+	}
+	return orderMessages(partitionMessages, order)
+}
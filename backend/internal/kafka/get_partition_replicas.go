@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+)
+
+// GetPartitionReplicas returns the leader and replicas of every partition of every topic, as the controller reports
+// them rather than as the cached metadata holds them.
+func (k *KafkaService) GetPartitionReplicas(ctx context.Context) (map[string]map[int32]model.PartitionReplicas, error) {
+	metadata, err := runWithContext(ctx, func() ([]*sarama.TopicMetadata, error) {
+		if err := k.client.RefreshMetadata(); err != nil {
+			return nil, fmt.Errorf("failed to refresh metadata: %w", err)
+		}
+		topics, err := k.client.Topics()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get topics: %w", err)
+		}
+		return k.admin.DescribeTopics(topics)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+	replicas := make(map[string]map[int32]model.PartitionReplicas, len(metadata))
+	for _, topic := range metadata {
+		// Topics deleted since they were listed
+		if errors.Is(topic.Err, sarama.ErrUnknownTopicOrPartition) {
+			continue
+		}
+		replicas[topic.Name] = make(map[int32]model.PartitionReplicas, len(topic.Partitions))
+		for _, partition := range topic.Partitions {
+			leader := partition.Leader
+			if errors.Is(partition.Err, sarama.ErrLeaderNotAvailable) {
+				leader = -1
+			}
+			replicas[topic.Name][partition.ID] = model.PartitionReplicas{
+				Leader:   leader,
+				Replicas: partition.Replicas,
+				InSync:   partition.Isr,
+				Offline:  partition.OfflineReplicas,
+			}
+		}
+	}
+	return replicas, nil
+}
@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GetPartitionOffsets returns the offsets of every partition of every topic, as of the latest metadata.
+func (k *KafkaService) GetPartitionOffsets(ctx context.Context) (map[string]map[int32]model.PartitionOffsets, error) {
+	topicPartitions, err := runWithContext(ctx, func() (map[string][]int32, error) {
+		if err := k.client.RefreshMetadata(); err != nil {
+			return nil, fmt.Errorf("failed to refresh metadata: %w", err)
+		}
+		topics, err := k.client.Topics()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get topics: %w", err)
+		}
+		topicPartitions := make(map[string][]int32, len(topics))
+		for _, topic := range topics {
+			partitions, err := k.client.Partitions(topic)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get partitions of topic %s: %w", topic, err)
+			}
+			topicPartitions[topic] = partitions
+		}
+		return topicPartitions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	newestOffsets, err := k.getTopicOffsets(ctx, topicPartitions, sarama.OffsetNewest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newest offsets: %w", err)
+	}
+	oldestOffsets, err := k.getTopicOffsets(ctx, topicPartitions, sarama.OffsetOldest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oldest offsets: %w", err)
+	}
+	offsets := make(map[string]map[int32]model.PartitionOffsets, len(topicPartitions))
+	for topic, partitions := range topicPartitions {
+		offsets[topic] = make(map[int32]model.PartitionOffsets, len(partitions))
+		for _, partition := range partitions {
+			offsets[topic][partition] = model.PartitionOffsets{
+				Oldest: oldestOffsets[topic][partition],
+				Newest: newestOffsets[topic][partition],
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// GetPartitionSizes returns the size on disk of every partition, from the log directories of every broker. Log
+// directories that are offline are left out.
+func (k *KafkaService) GetPartitionSizes(ctx context.Context) (map[string]map[int32]model.PartitionSize, error) {
+	brokers := k.client.Brokers()
+	brokerIDs := make([]int32, 0, len(brokers))
+	for _, broker := range brokers {
+		brokerIDs = append(brokerIDs, broker.ID())
+	}
+	logDirs, err := runWithContext(ctx, func() (map[int32][]sarama.DescribeLogDirsResponseDirMetadata, error) {
+		return k.admin.DescribeLogDirs(brokerIDs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe log dirs: %w", err)
+	}
+	sizes := make(map[string]map[int32]model.PartitionSize)
+	for brokerID, dirs := range logDirs {
+		for _, dir := range dirs {
+			if !errors.Is(dir.ErrorCode, sarama.ErrNoError) {
+				k.logger.Warn(
+					"skipping log dir that can't be described",
+					zap.Int32("broker", brokerID),
+					zap.String("path", dir.Path),
+					zap.Error(dir.ErrorCode),
+				)
+				continue
+			}
+			for _, topic := range dir.Topics {
+				if sizes[topic.Topic] == nil {
+					sizes[topic.Topic] = make(map[int32]model.PartitionSize, len(topic.Partitions))
+				}
+				for _, partition := range topic.Partitions {
+					if partition.IsTemporary {
+						// A replica being moved to this directory, which the current one is still counted as
+						continue
+					}
+					size := sizes[topic.Topic][partition.PartitionID]
+					size.Bytes = max(size.Bytes, partition.Size)
+					size.ReplicatedBytes += partition.Size
+					sizes[topic.Topic][partition.PartitionID] = size
+				}
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// GetTopicRetention returns how much of every topic log cleanup keeps. Topics that don't override the retention
+// settings get the defaults of the brokers.
+func (k *KafkaService) GetTopicRetention(ctx context.Context) (map[string]model.TopicRetention, error) {
+	topics, err := k.GetTopics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+	defaults, err := k.getDefaultRetention(ctx)
+	if err != nil {
+		return nil, err
+	}
+	retention := make(map[string]model.TopicRetention, len(topics))
+	for _, topic := range topics {
+		topicRetention := defaults
+		if topic.CleanupPolicy != model.CleanupPolicyUnknown {
+			topicRetention.CleanupPolicy = topic.CleanupPolicy
+		}
+		if topic.RetentionMs != nil {
+			topicRetention.RetentionMs = nil
+			if !topic.RetentionMs.Indefinite {
+				topicRetention.RetentionMs = &topic.RetentionMs.Value
+			}
+		}
+		if topic.RetentionBytes != nil {
+			topicRetention.RetentionBytes = nil
+			if *topic.RetentionBytes >= 0 {
+				topicRetention.RetentionBytes = topic.RetentionBytes
+			}
+		}
+		retention[topic.Name] = topicRetention
+	}
+	return retention, nil
+}
+
+// getDefaultRetention reads the retention settings of a broker, which topics get unless they override them.
+func (k *KafkaService) getDefaultRetention(ctx context.Context) (model.TopicRetention, error) {
+	values, err := k.getBrokerConfig(ctx)
+	if err != nil {
+		return model.TopicRetention{}, err
+	}
+	defaultPolicy := values["log.cleanup.policy"]
+	retention := model.TopicRetention{CleanupPolicy: getCleanupPolicy(&defaultPolicy)}
+	// The broker keeps records for log.retention.ms, or minutes, or hours, whichever is set first
+	for _, setting := range []struct {
+		name string
+		unit time.Duration
+	}{
+		{name: "log.retention.ms", unit: time.Millisecond},
+		{name: "log.retention.minutes", unit: time.Minute},
+		{name: "log.retention.hours", unit: time.Hour},
+	} {
+		value, err := strconv.ParseInt(values[setting.name], 10, 64)
+		if err != nil {
+			continue
+		}
+		if value >= 0 {
+			retentionMs := value * setting.unit.Milliseconds()
+			retention.RetentionMs = &retentionMs
+		}
+		break
+	}
+	retentionBytes, err := strconv.ParseInt(values["log.retention.bytes"], 10, 64)
+	if err == nil && retentionBytes >= 0 {
+		retention.RetentionBytes = &retentionBytes
+	}
+	return retention, nil
+}
+
+// getBrokerConfig reads the configuration of one of the brokers, assuming every broker of the cluster shares it.
+func (k *KafkaService) getBrokerConfig(ctx context.Context) (map[string]string, error) {
+	brokers := k.client.Brokers()
+	if len(brokers) == 0 {
+		return nil, errors.New("failed to get broker defaults: no brokers are known")
+	}
+	entries, err := runWithContext(ctx, func() ([]sarama.ConfigEntry, error) {
+		return k.admin.DescribeConfig(sarama.ConfigResource{
+			Type: sarama.BrokerResource,
+			Name: strconv.Itoa(int(brokers[0].ID())),
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broker defaults: %w", err)
+	}
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.Name] = entry.Value
+	}
+	return values, nil
+}
+
+// GetFirstTimestamps returns the timestamp of the record at the given offset of each partition of topic. Partitions
+// without a record at or after their offset are left out. Failed fetches are reported along with the timestamps of
+// the other partitions.
+func (k *KafkaService) GetFirstTimestamps(
+	ctx context.Context,
+	topic string,
+	offsets map[int32]int64,
+) (map[int32]time.Time, error) {
+	timestamps := make(map[int32]time.Time, len(offsets))
+	var mutex sync.Mutex
+	var errs error
+	// Fetches are limited per broker by the fetcher
+	var wg sync.WaitGroup
+	for partition, offset := range offsets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			block, err := k.fetcher.fetchFirst(ctx, topic, partition, offset)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("failed to fetch partition %d: %w", partition, err))
+				return
+			}
+			records, _ := fetchedRecords(topic, partition, offset, block, newTransactionTracker(), false)
+			if len(records) > 0 {
+				timestamps[partition] = records[0].message.Timestamp
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return timestamps, errs
+}
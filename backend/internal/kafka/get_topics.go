@@ -1,20 +1,29 @@
 package kafka
 
 import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
 	"github.com/IBM/sarama"
 	"go.uber.org/zap"
+	"slices"
 	"strconv"
+	"strings"
 )
 
-func (k *KafkaService) GetTopics() ([]model.TopicDetails, error) {
-	topicMap, err := k.admin.ListTopics()
+func (k *KafkaService) GetTopics(ctx context.Context) ([]model.TopicDetails, error) {
+	topicMap, err := runWithContext(ctx, k.admin.ListTopics)
 	if err != nil {
 		k.logger.Error("KafkaService can't get topics: failed to get topics", zap.Error(err))
 		return nil, err
 	}
 
 	topicDetails := k.getTopicDetailsFromTopicMap(topicMap)
+	// Topics the caller may not view are left out rather than refused, as if they didn't exist
+	topicDetails = slices.DeleteFunc(topicDetails, func(details model.TopicDetails) bool {
+		return !k.authorizer.Allows(ctx, auth.OperationViewTopics, auth.Resource{Topic: details.Name})
+	})
 	return topicDetails, nil
 }
 
@@ -72,7 +81,8 @@ func (k *KafkaService) getTopicDetailsFromTopicMap(
 				additionalConfigs[configKey] = *config
 			}
 		}
-		isInternal := isInternalTopic(topic)
+		isInternal := IsInternalTopic(topic)
+		kind := getTopicKind(topic, cleanupPolicy)
 		var retentionMsModel *model.RetentionMs = nil
 		if retentionMs != nil {
 			retentionMsModel = &model.RetentionMs{
@@ -85,6 +95,7 @@ func (k *KafkaService) getTopicDetailsFromTopicMap(
 			NumPartitions:     topicDetail.NumPartitions,
 			ReplicationFactor: topicDetail.ReplicationFactor,
 			IsInternal:        isInternal,
+			Kind:              kind,
 			CleanupPolicy:     cleanupPolicy,
 			RetentionMs:       retentionMsModel,
 			RetentionBytes:    retentionBytes,
@@ -115,6 +126,23 @@ func getCleanupPolicy(config *string) model.CleanupPolicy {
 	return cleanupPolicy
 }
 
-func isInternalTopic(topic string) bool {
+// IsInternalTopic tells whether topic is one that Kafka itself keeps, such as __consumer_offsets.
+func IsInternalTopic(topic string) bool {
 	return len(topic) > 2 && topic[:2] == "__"
 }
+
+// getTopicKind combines the naming conventions of Kafka Connect and Kafka Streams with the cleanup policy they
+// create their internal topics with, so that an application topic that happens to share a suffix is not tagged.
+func getTopicKind(topic string, cleanupPolicy model.CleanupPolicy) model.TopicKind {
+	isCompacted := cleanupPolicy == model.CleanupPolicyCompact || cleanupPolicy == model.CleanupPolicyBoth
+	switch {
+	case decoder.GetConnectTopicType(topic) != decoder.ConnectTopicNone && cleanupPolicy == model.CleanupPolicyCompact:
+		return model.TopicKindConnectInternal
+	case strings.HasSuffix(topic, "-changelog") && isCompacted:
+		return model.TopicKindStreamsChangelog
+	case strings.HasSuffix(topic, "-repartition") && !isCompacted:
+		return model.TopicKindStreamsRepartition
+	default:
+		return model.TopicKindStandard
+	}
+}
@@ -0,0 +1,286 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	// ImportIDHeader and ImportSequenceHeader are added to every imported record. They let a repeated import find
+	// the records an earlier, failed run already produced.
+	ImportIDHeader       = "kafka-window-import-id"
+	ImportSequenceHeader = "kafka-window-import-seq"
+
+	importBatchSize = 100
+)
+
+// ImportMessages produces the records of reader into topic. Records are numbered in the order they are read, so
+// repeating an import with the same ImportID and input skips everything that is already in the topic.
+// onProgress is called after every batch that reaches the topic. The result is returned even when the import fails,
+// to report how far it got.
+func (k *KafkaService) ImportMessages(
+	ctx context.Context,
+	topic string,
+	reader model.RecordReader,
+	options model.ImportOptions,
+	onProgress func(progress model.ImportResult),
+) (*model.ImportResult, error) {
+	err := k.authorizer.Authorize(ctx, auth.OperationProduce, auth.Resource{Topic: topic})
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := k.client.Partitions(topic)
+	if err != nil {
+		k.logger.Error(
+			"failed to get partitions of import topic",
+			zap.String("topic", topic),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to get partitions of topic %s: %w", topic, err)
+	}
+
+	result := &model.ImportResult{ImportID: options.ImportID}
+	alreadyImported := make(map[int64]struct{})
+	if result.ImportID == "" {
+		result.ImportID = uuid.NewString()
+	} else {
+		alreadyImported, err = k.getImportedSequences(ctx, topic, partitions, result.ImportID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	producer, err := sarama.NewSyncProducer(
+		k.brokers,
+		newIdempotentProducerConfig(k.config, options.Partitioning == model.ImportPartitioningKeep),
+	)
+	if err != nil {
+		k.logger.Error("failed to create import producer", zap.Error(err))
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+	defer producer.Close()
+
+	batchSize := importBatchSize
+	if options.MessagesPerSecond > 0 {
+		// Keep batches small enough that a throttled import still produces several times a second
+		batchSize = max(1, min(importBatchSize, options.MessagesPerSecond/10))
+	}
+	limiter := newRateLimiter(options.MessagesPerSecond)
+	batch := make([]*sarama.ProducerMessage, 0, batchSize)
+	flush := func() error {
+		produced, err := sendBatch(producer, batch)
+		result.Produced += int64(produced)
+		failed := len(batch) - produced
+		batch = batch[:0]
+		if onProgress != nil {
+			onProgress(*result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to produce %d records: %w", failed, err)
+		}
+		return nil
+	}
+
+	importStart := time.Now()
+	var timestampShift time.Duration
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read record %d: %w", result.Read, err)
+		}
+		sequence := result.Read
+		result.Read++
+		if sequence == 0 {
+			timestampShift = importStart.Sub(record.Timestamp)
+		}
+		if _, ok := alreadyImported[sequence]; ok {
+			result.Skipped++
+			continue
+		}
+
+		message := toImportMessage(topic, record, result.ImportID, sequence)
+		if options.Partitioning == model.ImportPartitioningKeep {
+			if record.Partition < 0 || int(record.Partition) >= len(partitions) {
+				return result, fmt.Errorf(
+					"record %d is from partition %d, but topic %s has %d partitions",
+					sequence,
+					record.Partition,
+					topic,
+					len(partitions),
+				)
+			}
+			message.Partition = record.Partition
+		}
+		switch options.Timestamps {
+		case model.ImportTimestampsNow:
+			message.Timestamp = time.Now()
+		case model.ImportTimestampsShift:
+			message.Timestamp = record.Timestamp.Add(timestampShift)
+		}
+
+		if err := limiter.wait(ctx); err != nil {
+			return result, err
+		}
+		batch = append(batch, message)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func toImportMessage(
+	topic string,
+	record *model.Record,
+	importID string,
+	sequence int64,
+) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(record.Headers)+2)
+	for _, header := range record.Headers {
+		// Drop the markers of an earlier import, the record is now part of this one
+		if header.Key == ImportIDHeader || header.Key == ImportSequenceHeader {
+			continue
+		}
+		headers = append(headers, sarama.RecordHeader{Key: []byte(header.Key), Value: header.Value})
+	}
+	headers = append(
+		headers,
+		sarama.RecordHeader{Key: []byte(ImportIDHeader), Value: []byte(importID)},
+		sarama.RecordHeader{Key: []byte(ImportSequenceHeader), Value: []byte(strconv.FormatInt(sequence, 10))},
+	)
+
+	message := &sarama.ProducerMessage{
+		Topic:     topic,
+		Headers:   headers,
+		Timestamp: record.Timestamp,
+	}
+	// Leave absent keys and values unset, so that they are produced as null rather than empty
+	if record.Key != nil {
+		message.Key = sarama.ByteEncoder(record.Key)
+	}
+	if record.Value != nil {
+		message.Value = sarama.ByteEncoder(record.Value)
+	}
+	return message
+}
+
+// getImportedSequences scans topic for the records an import with importID has already produced.
+func (k *KafkaService) getImportedSequences(
+	ctx context.Context,
+	topic string,
+	partitions []int32,
+	importID string,
+) (map[int64]struct{}, error) {
+	sequences := make(map[int64]struct{})
+	for _, partition := range partitions {
+		oldestOffset, err := k.getOffset(ctx, topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get oldest offset: %w", err)
+		}
+		newestOffset, err := k.getOffset(ctx, topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get newest offset: %w", err)
+		}
+		if newestOffset <= oldestOffset {
+			continue
+		}
+		_, err = k.consumePartition(
+			ctx,
+			topic,
+			partition,
+			oldestOffset,
+			newestOffset-1,
+			model.ReadUncommitted,
+			func(record *partitionRecord) bool {
+				if sequence, ok := getImportSequence(record.message, importID); ok {
+					sequences[sequence] = struct{}{}
+				}
+				return true
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan partition %d for imported records: %w", partition, err)
+		}
+	}
+	k.logger.Info(
+		"resuming import",
+		zap.String("topic", topic),
+		zap.String("importId", importID),
+		zap.Int("alreadyImported", len(sequences)),
+	)
+	return sequences, nil
+}
+
+func getImportSequence(message *sarama.ConsumerMessage, importID string) (int64, bool) {
+	matchesImport := false
+	var sequence int64
+	hasSequence := false
+	for _, header := range message.Headers {
+		if header == nil {
+			continue
+		}
+		switch string(header.Key) {
+		case ImportIDHeader:
+			matchesImport = string(header.Value) == importID
+		case ImportSequenceHeader:
+			parsed, err := strconv.ParseInt(string(header.Value), 10, 64)
+			if err == nil {
+				sequence = parsed
+				hasSequence = true
+			}
+		}
+	}
+	return sequence, matchesImport && hasSequence
+}
+
+// rateLimiter spaces out events evenly to stay below a rate per second. A zero rate never waits.
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	if perSecond <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Second / time.Duration(perSecond)}
+}
+
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r.interval == 0 {
+		return nil
+	}
+	now := time.Now()
+	if r.next.After(now) {
+		timer := time.NewTimer(r.next.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		now = r.next
+	}
+	r.next = now.Add(r.interval)
+	return nil
+}
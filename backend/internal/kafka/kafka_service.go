@@ -1,7 +1,9 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
 	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
 	"github.com/IBM/sarama"
 	"go.uber.org/zap"
@@ -10,8 +12,22 @@ import (
 type KafkaService struct {
 	client  sarama.Client
 	admin   sarama.ClusterAdmin
-	decoder *decoder.MessageDecoder
-	logger  *zap.Logger
+	brokers []string
+	config  *sarama.Config
+	// Other clusters by name, which records can be copied to and compared with
+	clusters    map[string]clusterConnection
+	fetchConfig FetchConfig
+	fetcher     *fetcher
+	// Restricts what is done on behalf of authenticated callers. Services of other clusters have none, since they
+	// are only used once the caller is authorized
+	authorizer *auth.Authorizer
+	decoder    *decoder.MessageDecoder
+	logger     *zap.Logger
+}
+
+type clusterConnection struct {
+	brokers []string
+	config  *sarama.Config
 }
 
 func NewKafkaService(
@@ -19,30 +35,107 @@ func NewKafkaService(
 	logger *zap.Logger,
 ) *KafkaService {
 	return &KafkaService{
-		decoder: decoder,
-		logger:  logger,
+		clusters:    make(map[string]clusterConnection),
+		fetchConfig: DefaultFetchConfig(),
+		decoder:     decoder,
+		logger:      logger,
 	}
 }
 
-func (k *KafkaService) ConnectToCluster(brokers []string, config *sarama.Config) error {
+// ConnectToCluster connects to brokers, giving up when ctx is done before the cluster answers.
+func (k *KafkaService) ConnectToCluster(ctx context.Context, brokers []string, config *sarama.Config) error {
 	if config == nil {
 		config = sarama.NewConfig()
 		config.ClientID = "kafka-ui"
 		config.Version = sarama.V3_6_0_0
 	}
-	client, err := sarama.NewClient(brokers, config)
-	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+	type connection struct {
+		client sarama.Client
+		err    error
+	}
+	connected := make(chan connection, 1)
+	go func() {
+		client, err := sarama.NewClient(brokers, config)
+		connected <- connection{client: client, err: err}
+	}()
+	var client sarama.Client
+	select {
+	case result := <-connected:
+		if result.err != nil {
+			return fmt.Errorf("failed to create client: %w", result.err)
+		}
+		client = result.client
+	case <-ctx.Done():
+		// Nobody else would close a client that connects after the caller gave up
+		go func() {
+			if result := <-connected; result.err == nil {
+				result.client.Close()
+			}
+		}()
+		return fmt.Errorf("failed to create client: %w", ctx.Err())
 	}
 	admin, err := sarama.NewClusterAdminFromClient(client)
 	if err != nil {
+		client.Close()
 		return fmt.Errorf("failed to create cluster admin: %w", err)
 	}
 	k.client = client
 	k.admin = admin
+	k.fetcher = newFetcher(client, config, k.fetchConfig)
+	k.brokers = brokers
+	k.config = config
 	return nil
 }
 
+// SetFetchConfig changes the limits of message fetches. It takes effect on the next ConnectToCluster.
+func (k *KafkaService) SetFetchConfig(config FetchConfig) {
+	k.fetchConfig = config
+}
+
+// SetAuthorizer restricts the calls made with the identity of an authenticated caller in their context to what the
+// roles of the caller allow.
+func (k *KafkaService) SetAuthorizer(authorizer *auth.Authorizer) {
+	k.authorizer = authorizer
+}
+
+// RegisterCluster makes another cluster available by name. No connection is made until the cluster is used.
+func (k *KafkaService) RegisterCluster(name string, brokers []string, config *sarama.Config) {
+	k.clusters[name] = clusterConnection{
+		brokers: brokers,
+		config:  config,
+	}
+}
+
+// getCluster returns the connection details of a registered cluster, or of the connected cluster for an empty name.
+func (k *KafkaService) getCluster(name string) (clusterConnection, error) {
+	if name == "" {
+		return clusterConnection{brokers: k.brokers, config: k.config}, nil
+	}
+	cluster, ok := k.clusters[name]
+	if !ok {
+		return clusterConnection{}, fmt.Errorf("unknown cluster: %s", name)
+	}
+	return cluster, nil
+}
+
+// connectToCluster returns a service reading from a registered cluster, or k itself for an empty name. The returned
+// service must be closed unless it is k.
+func (k *KafkaService) connectToCluster(ctx context.Context, name string) (*KafkaService, error) {
+	if name == "" {
+		return k, nil
+	}
+	cluster, err := k.getCluster(name)
+	if err != nil {
+		return nil, err
+	}
+	service := NewKafkaService(k.decoder, k.logger)
+	service.SetFetchConfig(k.fetchConfig)
+	if err := service.ConnectToCluster(ctx, cluster.brokers, cluster.config); err != nil {
+		return nil, fmt.Errorf("failed to connect to cluster %s: %w", name, err)
+	}
+	return service, nil
+}
+
 func (k *KafkaService) Close() error {
 	err := k.admin.Close()
 	if err != nil {
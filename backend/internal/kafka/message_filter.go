@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"strings"
+)
+
+type compiledMessageFilter struct {
+	filter    model.MessageFilter
+	valuePath jsonvalue.Path
+}
+
+// compileMessageFilter returns nil for a nil filter, which matches every record.
+func compileMessageFilter(filter *model.MessageFilter) (*compiledMessageFilter, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	compiled := &compiledMessageFilter{filter: *filter}
+	if filter.ValuePath != "" {
+		path, err := jsonvalue.ParsePath(filter.ValuePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value path: %w", err)
+		}
+		compiled.valuePath = path
+	}
+	return compiled, nil
+}
+
+func (f *compiledMessageFilter) matches(record *model.Record) bool {
+	if f == nil {
+		return true
+	}
+	if f.filter.StartTime != nil && record.Timestamp.Before(*f.filter.StartTime) {
+		return false
+	}
+	if f.filter.EndTime != nil && record.Timestamp.After(*f.filter.EndTime) {
+		return false
+	}
+
+	needsDecodedMessage := f.filter.KeyContains != "" || f.filter.ValueContains != "" || f.valuePath != nil
+	if !needsDecodedMessage {
+		return true
+	}
+	message := record.Message
+	if message == nil {
+		return false
+	}
+	if f.filter.KeyContains != "" && !strings.Contains(message.Key, f.filter.KeyContains) {
+		return false
+	}
+	if f.filter.ValueContains != "" && !strings.Contains(message.Value, f.filter.ValueContains) {
+		return false
+	}
+	if f.valuePath != nil {
+		value, ok := f.valuePath.Lookup(message.ValueJsonPayload)
+		if !ok {
+			return false
+		}
+		text, err := jsonvalue.ToText(*value)
+		if err != nil || text != f.filter.ValueEquals {
+			return false
+		}
+	}
+	return true
+}
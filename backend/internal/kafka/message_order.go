@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"cmp"
+	"container/heap"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"slices"
+)
+
+// orderMessages combines the messages of every partition, each in offset order, into a single list in order.
+func orderMessages(partitionMessages [][]*model.Message, order model.MessageOrder) []*model.Message {
+	switch order {
+	case model.OrderTimestamp:
+		return mergeByTimestamp(partitionMessages)
+	case model.OrderNewestFirst:
+		messages := mergeByTimestamp(partitionMessages)
+		slices.Reverse(messages)
+		return messages
+	default:
+		return concatByPartition(partitionMessages)
+	}
+}
+
+func concatByPartition(partitionMessages [][]*model.Message) []*model.Message {
+	partitionMessages = slices.DeleteFunc(slices.Clone(partitionMessages), func(messages []*model.Message) bool {
+		return len(messages) == 0
+	})
+	slices.SortFunc(partitionMessages, func(a, b []*model.Message) int {
+		return cmp.Compare(a[0].Partition, b[0].Partition)
+	})
+	messages := make([]*model.Message, 0)
+	for _, partition := range partitionMessages {
+		messages = append(messages, partition...)
+	}
+	return messages
+}
+
+// mergeByTimestamp is a k-way merge of the partitions. The messages of a partition keep their offset order, so a
+// message whose producer set an older timestamp than the messages before it is not moved ahead of them.
+func mergeByTimestamp(partitionMessages [][]*model.Message) []*model.Message {
+	cursors := make(messageCursors, 0, len(partitionMessages))
+	total := 0
+	for _, messages := range partitionMessages {
+		if len(messages) > 0 {
+			cursors = append(cursors, messages)
+			total += len(messages)
+		}
+	}
+	heap.Init(&cursors)
+	merged := make([]*model.Message, 0, total)
+	for len(cursors) > 0 {
+		merged = append(merged, cursors[0][0])
+		cursors[0] = cursors[0][1:]
+		if len(cursors[0]) == 0 {
+			heap.Pop(&cursors)
+		} else {
+			heap.Fix(&cursors, 0)
+		}
+	}
+	return merged
+}
+
+// messageCursors is a heap of the messages left in each partition, ordered by the timestamp of their first message.
+// Ties go to the lower partition, so that the order is stable.
+type messageCursors [][]*model.Message
+
+func (c messageCursors) Len() int { return len(c) }
+
+func (c messageCursors) Less(i, j int) bool {
+	a, b := c[i][0], c[j][0]
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.Before(b.Timestamp)
+	}
+	return a.Partition < b.Partition
+}
+
+func (c messageCursors) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+func (c *messageCursors) Push(x any) { *c = append(*c, x.([]*model.Message)) }
+
+func (c *messageCursors) Pop() any {
+	old := *c
+	last := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return last
+}
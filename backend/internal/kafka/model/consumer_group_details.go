@@ -10,3 +10,10 @@ type ConsumerDetails struct {
 	LastCommittedOffset int64
 	HighWaterMark       int64
 }
+
+// GroupPartitionOffsets are how far a consumer group got in a partition.
+type GroupPartitionOffsets struct {
+	// The offset the group committed, which is the next one it reads
+	Committed     int64
+	HighWatermark int64
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+type CopySource struct {
+	Topic string
+	// The offset ranges to copy. All partitions are copied in full when empty
+	Partitions PartitionInput
+	// Only copy records at or after this timestamp
+	StartTime *time.Time
+	// Only copy records at or before this timestamp
+	EndTime *time.Time
+	Filter  *MessageFilter
+}
+
+type CopyDestination struct {
+	// The name of a configured cluster, or empty for the cluster the source is on
+	Cluster string
+	Topic   string
+}
+
+type CopyOptions struct {
+	KeepKey     bool
+	KeepHeaders bool
+	// Produce every record to the partition it came from, instead of partitioning by key
+	KeepPartition bool
+	// Applied in order to values that are JSON documents. Other values are copied unchanged
+	ValueTransform []TransformStep
+}
+
+type TransformOp string
+
+const (
+	TransformSet    TransformOp = "set"
+	TransformRemove TransformOp = "remove"
+)
+
+// TransformStep is a single operation on a JSON document. Value is the JSON a set operation stores at Path.
+type TransformStep struct {
+	Op    TransformOp
+	Path  string
+	Value string
+}
+
+type CopyProgress struct {
+	// The number of records produced to the destination
+	Copied int64 `json:"copied" validate:"required"`
+	// The number of records copied without the value transformation, because their value is not JSON
+	Untransformed int64 `json:"untransformed" validate:"required"`
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+type DiffSource struct {
+	// The name of a configured cluster, or empty for the connected cluster
+	Cluster string
+	Topic   string
+	// The offset ranges to compare. All partitions are compared in full when empty
+	Partitions PartitionInput
+}
+
+type DiffOptions struct {
+	// JSON paths left out when comparing JSON values, such as $.updatedAt
+	IgnorePaths []string
+	// The most missing, extra and differing records listed each
+	MaxReported int
+}
+
+// TopicDiff is how the records of a target compare with those of a source. Records are matched by key and by a hash
+// of their value. Records of a key that find no identical counterpart are paired in the order they were read, and
+// the pairs are differing records. The records left over are missing from or extra in the target.
+type TopicDiff struct {
+	SourceMessages int64 `json:"sourceMessages" validate:"required"`
+	TargetMessages int64 `json:"targetMessages" validate:"required"`
+	// Records with the same key and value on both sides
+	Matched int64 `json:"matched" validate:"required"`
+	// Records of the source left without a target record of their key to match or pair with
+	Missing int64 `json:"missing" validate:"required"`
+	// Records of the target left without a source record of their key to match or pair with
+	Extra int64 `json:"extra" validate:"required"`
+	// Pairs of records with the same key whose values differ
+	Differing int64 `json:"differing" validate:"required"`
+	// The first missing records by key, up to the report limit
+	MissingRecords []DiffRecord `json:"missingRecords" validate:"required"`
+	// The first extra records by key, up to the report limit
+	ExtraRecords []DiffRecord `json:"extraRecords" validate:"required"`
+	// The first differing records by key, up to the report limit
+	DifferingRecords []RecordDiff `json:"differingRecords" validate:"required"`
+}
+
+type DiffRecord struct {
+	// The key as text, or base64 encoded when it isn't valid UTF-8. Missing for records without a key
+	Key       *string   `json:"key,omitempty"`
+	Base64    bool      `json:"base64,omitempty"`
+	Partition int32     `json:"partition" validate:"required"`
+	Offset    int64     `json:"offset" validate:"required"`
+	Timestamp time.Time `json:"timestamp" validate:"required"`
+}
+
+type RecordDiff struct {
+	Source DiffRecord `json:"source" validate:"required"`
+	Target DiffRecord `json:"target" validate:"required"`
+	// How the value of the target differs from that of the source. Missing when either record couldn't be read again
+	Changes []ValueChange `json:"changes,omitempty"`
+}
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// ValueChange is a difference at a single path of two JSON values. Values that aren't JSON differ at $ as strings.
+type ValueChange struct {
+	Path string     `json:"path" validate:"required"`
+	Type ChangeType `json:"type" validate:"required"`
+	// The value of the source. Missing when the target added the path
+	Source *JSONValue `json:"source,omitempty"`
+	// The value of the target. Missing when the target removed the path
+	Target *JSONValue `json:"target,omitempty"`
+}
@@ -0,0 +1,30 @@
+package model
+
+type IsolationLevel string
+
+const (
+	// ReadUncommitted returns every record, whatever the state of its transaction
+	ReadUncommitted IsolationLevel = "read_uncommitted"
+	// ReadCommitted leaves out the records of aborted and open transactions, like a read_committed consumer
+	ReadCommitted IsolationLevel = "read_committed"
+)
+
+type MessageOrder string
+
+const (
+	// OrderPartitionOffset lists the messages partition by partition, each in offset order
+	OrderPartitionOffset MessageOrder = "partition_offset"
+	// OrderTimestamp merges the partitions by timestamp, oldest first
+	OrderTimestamp MessageOrder = "timestamp"
+	// OrderNewestFirst merges the partitions by timestamp, newest first
+	OrderNewestFirst MessageOrder = "newest_first"
+)
+
+type FetchOptions struct {
+	// ReadUncommitted when empty
+	IsolationLevel IsolationLevel
+	// Whether the commit and abort markers of transactions are returned as messages of their own
+	IncludeControlRecords bool
+	// OrderPartitionOffset when empty
+	Order MessageOrder
+}
@@ -0,0 +1,47 @@
+package model
+
+// RecordReader reads records one at a time, returning io.EOF once there are no more records.
+type RecordReader interface {
+	Read() (*Record, error)
+}
+
+type ImportPartitioning string
+
+const (
+	// ImportPartitioningKeep produces every record to the partition it was exported from
+	ImportPartitioningKeep ImportPartitioning = "keep"
+	// ImportPartitioningKey hashes the key with murmur2, as the default partitioner of the Java producer does
+	ImportPartitioningKey ImportPartitioning = "key"
+)
+
+type ImportTimestamps string
+
+const (
+	// ImportTimestampsKeep keeps the original record timestamps
+	ImportTimestampsKeep ImportTimestamps = "keep"
+	// ImportTimestampsNow stamps every record with the time it is produced
+	ImportTimestampsNow ImportTimestamps = "now"
+	// ImportTimestampsShift moves all timestamps by the same amount, so that the first record is stamped with the
+	// start of the import and the spacing between records is preserved
+	ImportTimestampsShift ImportTimestamps = "shift"
+)
+
+type ImportOptions struct {
+	// Identifies the import. Repeating an import with the same ID skips the records it already produced
+	ImportID     string
+	Partitioning ImportPartitioning
+	Timestamps   ImportTimestamps
+	// The maximum number of records produced per second, or 0 for no limit
+	MessagesPerSecond int
+}
+
+type ImportResult struct {
+	// The ID to repeat the import with in order to resume it
+	ImportID string `json:"importId" validate:"required"`
+	// The number of records read from the input
+	Read int64 `json:"read" validate:"required"`
+	// The number of records produced by this run
+	Produced int64 `json:"produced" validate:"required"`
+	// The number of records skipped because a previous run with the same import ID already produced them
+	Skipped int64 `json:"skipped" validate:"required"`
+}
@@ -0,0 +1,60 @@
+package model
+
+type KeyDistributionOptions struct {
+	// The most messages sampled, spread over the partitions by how many offsets they hold and taken from their ends
+	SampleSize int64
+	// The number of most frequent keys reported
+	TopK int
+	// How many times the mean a partition holds for the topic to be imbalanced
+	ImbalanceThreshold float64
+}
+
+// KeyDistribution is how the messages and keys of a topic are spread over its partitions, from a sample of its
+// newest messages. Key counts are estimated, and never below the true count within the sample.
+type KeyDistribution struct {
+	Topic           string `json:"topic" validate:"required"`
+	SampledMessages int64  `json:"sampledMessages" validate:"required"`
+	// The estimated number of distinct keys in the sample
+	DistinctKeys int64 `json:"distinctKeys" validate:"required"`
+	// Messages without a key, which the producer spreads over the partitions regardless of keys
+	NullKeys int64 `json:"nullKeys" validate:"required"`
+	// How many times the mean number of messages the largest partition holds
+	MessageSkew float64 `json:"messageSkew" validate:"required"`
+	// How many times the mean size the largest partition is. Missing when the sizes couldn't be read
+	SizeSkew *float64 `json:"sizeSkew,omitempty"`
+	// Whether a partition holds at least the imbalance threshold times the mean messages or size
+	Imbalanced bool                       `json:"imbalanced" validate:"required"`
+	Partitions []PartitionKeyDistribution `json:"partitions" validate:"required"`
+	// The most frequent keys of the sample, most frequent first
+	TopKeys []KeyFrequency `json:"topKeys" validate:"required"`
+	// The top keys that make up a large part of the sample of an imbalanced partition
+	HotKeys []KeyFrequency `json:"hotKeys" validate:"required"`
+}
+
+type PartitionKeyDistribution struct {
+	Partition int32 `json:"partition" validate:"required"`
+	// The number of offsets the partition holds
+	Messages int64 `json:"messages" validate:"required"`
+	// The size of the largest replica. -1 when it couldn't be read
+	SizeBytes       int64 `json:"sizeBytes" validate:"required"`
+	SampledMessages int64 `json:"sampledMessages" validate:"required"`
+	// The size of the keys and values of the sampled messages
+	SampledBytes int64 `json:"sampledBytes" validate:"required"`
+	// The estimated number of distinct keys in the sample of the partition
+	DistinctKeys int64 `json:"distinctKeys" validate:"required"`
+	NullKeys     int64 `json:"nullKeys" validate:"required"`
+	// Whether the partition holds at least the imbalance threshold times the mean messages or size
+	Hot bool `json:"hot" validate:"required"`
+}
+
+type KeyFrequency struct {
+	// The key as text, or base64 encoded when it isn't valid UTF-8
+	Key    string `json:"key" validate:"required"`
+	Base64 bool   `json:"base64,omitempty"`
+	// The estimated number of sampled messages with the key
+	Count int64 `json:"count" validate:"required"`
+	// Count as a share of every sampled message
+	Share float64 `json:"share" validate:"required"`
+	// The partition most of the messages with the key were sampled from
+	Partition int32 `json:"partition" validate:"required"`
+}
@@ -15,12 +15,48 @@ type Message struct {
 	Value            string      `json:"value" validate:"required"`
 	ValueJsonPayload *JSONValue  `json:"valueJsonPayload"`
 	ValuePayloadType PayloadType `json:"valuePayloadType" validate:"required"`
+	// Application-level compression stripped from the key before decoding, outermost first
+	KeyCompression []CompressionType `json:"keyCompression,omitempty"`
+	// Application-level compression stripped from the value before decoding, outermost first
+	ValueCompression []CompressionType `json:"valueCompression,omitempty"`
+	// The state of the transaction the message was produced in, empty when it wasn't produced in one
+	TransactionState TransactionState `json:"transactionState,omitempty"`
+	// Set when the message is the commit or abort marker of a transaction, which has no key or value
+	ControlType ControlType `json:"controlType,omitempty"`
 }
 
 type PayloadType string
 
 const (
-	JSONPayload           PayloadType = "json"
-	StringPayload         PayloadType = "string"
-	ConsumerOffsetPayload PayloadType = "consumerOffset"
+	JSONPayload             PayloadType = "json"
+	StringPayload           PayloadType = "string"
+	ConsumerOffsetPayload   PayloadType = "consumerOffset"
+	TransactionStatePayload PayloadType = "transactionState"
+	// The key or value is null or empty, as the value of a tombstone is
+	NullPayload PayloadType = "null"
+)
+
+type TransactionState string
+
+const (
+	TransactionCommitted TransactionState = "committed"
+	TransactionAborted   TransactionState = "aborted"
+	// The transaction was neither committed nor aborted yet when the message was read
+	TransactionOpen TransactionState = "open"
+)
+
+type ControlType string
+
+const (
+	ControlCommit ControlType = "commit"
+	ControlAbort  ControlType = "abort"
+)
+
+type CompressionType string
+
+const (
+	CompressionGzip   CompressionType = "gzip"
+	CompressionZstd   CompressionType = "zstd"
+	CompressionSnappy CompressionType = "snappy"
+	CompressionLZ4    CompressionType = "lz4"
 )
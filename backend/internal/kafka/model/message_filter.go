@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// MessageFilter narrows down the messages of a fetch. All set conditions must match.
+type MessageFilter struct {
+	// Only messages whose decoded key contains this substring
+	KeyContains string
+	// Only messages whose decoded value contains this substring
+	ValueContains string
+	// Only messages at or after this timestamp
+	StartTime *time.Time
+	// Only messages at or before this timestamp
+	EndTime *time.Time
+	// Only messages whose JSON value has ValueEquals at this JSON path, e.g. $.order.status
+	ValuePath string
+	// The text the value at ValuePath must equal. Containers are compared as JSON
+	ValueEquals string
+}
@@ -0,0 +1,43 @@
+package model
+
+type MessageSizeOptions struct {
+	// The most messages sampled from every topic, spread over the partitions by how many offsets they hold and taken
+	// from their ends
+	SampleSize int64
+	// The share of the size limit the largest sampled record may reach before the topic is at risk
+	Threshold float64
+}
+
+// TopicMessageSizes is how large the records of a topic are, from a sample of its newest messages, next to the
+// largest record the topic accepts. Sizes are those of the uncompressed keys, values and headers, leaving out the
+// overhead of record batches.
+type TopicMessageSizes struct {
+	Topic           string           `json:"topic" validate:"required"`
+	SampledMessages int64            `json:"sampledMessages" validate:"required"`
+	Key             SizeDistribution `json:"key" validate:"required"`
+	Value           SizeDistribution `json:"value" validate:"required"`
+	// The keys and values of every header of a record together
+	Headers SizeDistribution `json:"headers" validate:"required"`
+	// The key, value and headers of a record together
+	Record SizeDistribution `json:"record" validate:"required"`
+	// The largest record batch the topic accepts, from its max.message.bytes or else the limit of the brokers.
+	// Missing when neither could be read
+	MaxMessageBytes *int64 `json:"maxMessageBytes,omitempty"`
+	// Whether the topic overrides the limit of the brokers
+	TopicOverride bool `json:"topicOverride" validate:"required"`
+	// The message.max.bytes of the brokers. Missing when it couldn't be read
+	BrokerMaxMessageBytes *int64 `json:"brokerMaxMessageBytes,omitempty"`
+	// The largest sampled record as a share of MaxMessageBytes. Missing when the limit is
+	Utilization *float64 `json:"utilization,omitempty"`
+	// Whether the largest sampled record reaches the threshold share of the limit
+	AtRisk bool `json:"atRisk" validate:"required"`
+}
+
+// SizeDistribution is the spread of sizes in bytes, zero when nothing was sampled.
+type SizeDistribution struct {
+	Mean float64 `json:"mean" validate:"required"`
+	P50  int64   `json:"p50" validate:"required"`
+	P95  int64   `json:"p95" validate:"required"`
+	P99  int64   `json:"p99" validate:"required"`
+	Max  int64   `json:"max" validate:"required"`
+}
@@ -0,0 +1,35 @@
+package model
+
+// PartitionOffsets are the offsets a partition currently holds records between.
+type PartitionOffsets struct {
+	// The log start offset, below which records were deleted
+	Oldest int64
+	// The high watermark
+	Newest int64
+}
+
+// PartitionSize is the size of the log segments of a partition on the brokers' disks.
+type PartitionSize struct {
+	// The size of the largest replica
+	Bytes int64
+	// The size of all replicas together
+	ReplicatedBytes int64
+}
+
+// TopicRetention is how much of a topic log cleanup keeps, taking the broker's defaults into account.
+type TopicRetention struct {
+	CleanupPolicy CleanupPolicy
+	// How long records are kept. Nil when they are kept indefinitely
+	RetentionMs *int64
+	// How many bytes each partition keeps. Nil when there is no limit
+	RetentionBytes *int64
+}
+
+// PartitionReplicas are the brokers that hold a partition and which of them can serve it.
+type PartitionReplicas struct {
+	// -1 when the partition has no leader, which leaves it offline
+	Leader   int32
+	Replicas []int32
+	InSync   []int32
+	Offline  []int32
+}
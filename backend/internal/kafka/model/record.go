@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Record is a consumed Kafka record with its raw bytes and headers, alongside its decoded Message.
+// Message is nil when the key or value could not be decoded.
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Timestamp time.Time
+	Key       []byte
+	Value     []byte
+	Headers   []RecordHeader
+	Message   *Message
+}
+
+type RecordHeader struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
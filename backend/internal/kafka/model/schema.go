@@ -0,0 +1,40 @@
+package model
+
+type SchemaType string
+
+const (
+	SchemaNull    SchemaType = "null"
+	SchemaBoolean SchemaType = "boolean"
+	SchemaInteger SchemaType = "integer"
+	SchemaNumber  SchemaType = "number"
+	SchemaString  SchemaType = "string"
+	SchemaObject  SchemaType = "object"
+	SchemaArray   SchemaType = "array"
+)
+
+// FieldSchema is what was observed of the values at a path of the sampled documents.
+type FieldSchema struct {
+	// The path of the field, where [*] stands for every element of an array
+	Path string `json:"path" validate:"required"`
+	// How many values of each type were observed
+	Types map[SchemaType]int64 `json:"types" validate:"required"`
+	// Whether values of more than one type other than null were observed. Integers and other numbers don't conflict
+	Conflict bool `json:"conflict" validate:"required"`
+	// The share of the objects holding the field that it was present in
+	Presence float64 `json:"presence" validate:"required"`
+	// The share of the present values that were null
+	Nullability float64 `json:"nullability" validate:"required"`
+	// Distinct values that were observed, rendered as JSON. Objects, arrays and nulls aren't given as examples
+	Examples []string `json:"examples" validate:"required"`
+}
+
+// InferredSchema is the schema that the JSON payloads of a sample of messages conform to.
+type InferredSchema struct {
+	Topic string `json:"topic" validate:"required"`
+	// Whether the schema is of the keys or the values
+	Part            string `json:"part" validate:"required" enums:"key,value"`
+	SampledMessages int64  `json:"sampledMessages" validate:"required"`
+	// The sampled messages whose payload was JSON, which the schema was inferred from
+	JSONMessages int64         `json:"jsonMessages" validate:"required"`
+	Fields       []FieldSchema `json:"fields" validate:"required"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// TopicTable is a compacted topic read as a table: the newest message of every key, as compaction will leave it.
+type TopicTable struct {
+	Summary TableSummary
+	// The newest message of every key, ordered by key
+	Rows []TableRow
+}
+
+type TableSummary struct {
+	Topic           string `json:"topic" validate:"required"`
+	ScannedMessages int64  `json:"scannedMessages" validate:"required"`
+	// The keys whose newest message has a value
+	Keys int64 `json:"keys" validate:"required"`
+	// The keys whose newest message is a tombstone, which compaction removes along with the key
+	Tombstones int64 `json:"tombstones" validate:"required"`
+	// Messages without a key, which compaction doesn't keep track of
+	NullKeys int64 `json:"nullKeys" validate:"required"`
+	// Messages superseded by a newer message with the same key that compaction hasn't removed yet
+	DuplicateMessages int64 `json:"duplicateMessages" validate:"required"`
+	// DuplicateMessages as a share of the scanned messages
+	DirtyRatio float64 `json:"dirtyRatio" validate:"required"`
+	// The dirty ratio from which the log cleaner compacts the topic. Missing when it couldn't be read
+	MinCleanableDirtyRatio *float64 `json:"minCleanableDirtyRatio,omitempty"`
+}
+
+type TableRow struct {
+	// The key as text, or base64 encoded when it isn't valid UTF-8
+	Key       string    `json:"key" validate:"required"`
+	Base64    bool      `json:"base64,omitempty"`
+	Partition int32     `json:"partition" validate:"required"`
+	Offset    int64     `json:"offset" validate:"required"`
+	Timestamp time.Time `json:"timestamp" validate:"required"`
+	// Whether the newest message of the key has no value, deleting the key
+	Tombstone bool `json:"tombstone" validate:"required"`
+	// The newest message of the key. Missing when it is a tombstone or couldn't be decoded
+	Message *Message `json:"message,omitempty"`
+}
+
+// TablePage is a page of the rows of a scanned table, ordered by key.
+type TablePage struct {
+	Summary TableSummary `json:"summary" validate:"required"`
+	// The number of rows there are, tombstones included only when they were asked for
+	Total  int        `json:"total" validate:"required"`
+	Offset int        `json:"offset" validate:"required"`
+	Rows   []TableRow `json:"rows" validate:"required"`
+}
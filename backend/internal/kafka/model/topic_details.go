@@ -5,12 +5,23 @@ type TopicDetails struct {
 	NumPartitions     int32             `json:"numPartitions" validate:"required"`
 	ReplicationFactor int16             `json:"replicationFactor" validate:"required"`
 	IsInternal        bool              `json:"isInternal" validate:"required"`
+	Kind              TopicKind         `json:"kind" validate:"required"`
 	CleanupPolicy     CleanupPolicy     `json:"cleanupPolicy" validate:"required"`
 	RetentionMs       *RetentionMs      `json:"retentionMs" omitEmpty:"true"`
 	RetentionBytes    *int64            `json:"retentionBytes" omitEmpty:"true"`
 	AdditionalConfigs map[string]string `json:"additionalConfigs" validate:"required"`
 }
 
+// TopicKind classifies topics that are managed by Kafka Connect or Kafka Streams rather than by applications
+type TopicKind string
+
+const (
+	TopicKindStandard           TopicKind = "standard"
+	TopicKindConnectInternal    TopicKind = "connect-internal"
+	TopicKindStreamsChangelog   TopicKind = "streams-changelog"
+	TopicKindStreamsRepartition TopicKind = "streams-repartition"
+)
+
 type CleanupPolicy string
 
 const (
@@ -0,0 +1,27 @@
+package model
+
+// TopicMessages are the messages read from the requested ranges of a topic, with how much of each range was read.
+type TopicMessages struct {
+	Messages   []*Message          `json:"messages" validate:"required"`
+	Partitions []PartitionCoverage `json:"partitions" validate:"required"`
+}
+
+// PartitionCoverage tells how much of the requested range of a partition was read.
+type PartitionCoverage struct {
+	Partition int32 `json:"partition" validate:"required"`
+	// The requested range as absolute, inclusive offsets. Both are -1 when the partition is empty
+	StartOffset int64 `json:"startOffset" validate:"required"`
+	EndOffset   int64 `json:"endOffset" validate:"required"`
+	// The offset reading stopped at. Every offset from StartOffset up to it was read, including the gaps that
+	// compaction and transaction markers leave, which hold no messages
+	NextOffset int64 `json:"nextOffset" validate:"required"`
+	// The high watermark of the partition when reading stopped
+	HighWatermark int64 `json:"highWatermark" validate:"required"`
+	// The offset below which every transaction is either committed or aborted
+	LastStableOffset int64 `json:"lastStableOffset" validate:"required"`
+	// Whether reading reached the end of the range, or the high watermark when the range reaches past it. Under
+	// read_committed, reading stops at the last stable offset instead
+	Complete bool `json:"complete" validate:"required"`
+	// Why reading stopped before the end of the range
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	murmur2Seed       = 0x9747b28c
+	murmur2Multiplier = 0x5bd1e995
+)
+
+// murmur2 is the hash the Java producer's default partitioner picks the partition of a key with. Combined with
+// sarama.WithAbsFirst, which drops the sign bit as the Java producer does, records land on the same partitions as
+// records produced by Java clients.
+type murmur2 struct {
+	data []byte
+}
+
+func newMurmur2() hash.Hash32 {
+	return &murmur2{}
+}
+
+func (m *murmur2) Write(data []byte) (int, error) {
+	m.data = append(m.data, data...)
+	return len(data), nil
+}
+
+func (m *murmur2) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint32(b, m.Sum32())
+}
+
+func (m *murmur2) Reset() {
+	m.data = m.data[:0]
+}
+
+func (m *murmur2) Size() int {
+	return 4
+}
+
+func (m *murmur2) BlockSize() int {
+	return 4
+}
+
+// Sum32 follows org.apache.kafka.common.utils.Utils.murmur2.
+func (m *murmur2) Sum32() uint32 {
+	length := len(m.data)
+	h := uint32(murmur2Seed) ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(m.data[i:])
+		k *= murmur2Multiplier
+		k ^= k >> 24
+		k *= murmur2Multiplier
+		h *= murmur2Multiplier
+		h ^= k
+	}
+	tail := m.data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= murmur2Multiplier
+	}
+	h ^= h >> 13
+	h *= murmur2Multiplier
+	h ^= h >> 15
+	return h
+}
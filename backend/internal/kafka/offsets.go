@@ -0,0 +1,136 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+)
+
+// getOffsets looks up the offset at time of every partition, with a single ListOffsets request per leader rather
+// than one per partition. time is a timestamp in milliseconds, for the first offset whose timestamp is at or after
+// it, or sarama.OffsetNewest or sarama.OffsetOldest. Partitions with no message at or after a timestamp get -1.
+func (k *KafkaService) getOffsets(
+	ctx context.Context,
+	topic string,
+	partitions []int32,
+	time int64,
+) (map[int32]int64, error) {
+	offsets, err := k.getTopicOffsets(ctx, map[string][]int32{topic: partitions}, time)
+	if err != nil {
+		return nil, err
+	}
+	return offsets[topic], nil
+}
+
+// getTopicOffsets is getOffsets for the partitions of several topics at once.
+func (k *KafkaService) getTopicOffsets(
+	ctx context.Context,
+	topicPartitions map[string][]int32,
+	time int64,
+) (map[string]map[int32]int64, error) {
+	requests := make(map[int32]*leaderOffsetRequest)
+	for topic, partitions := range topicPartitions {
+		for _, partition := range partitions {
+			leader, err := k.client.Leader(topic, partition)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the leader of partition %d of topic %s: %w", partition, topic, err)
+			}
+			request, ok := requests[leader.ID()]
+			if !ok {
+				request = &leaderOffsetRequest{
+					leader:          leader,
+					request:         &sarama.OffsetRequest{Version: offsetRequestVersion(k.config.Version)},
+					topicPartitions: make(map[string][]int32),
+				}
+				requests[leader.ID()] = request
+			}
+			request.request.AddBlock(topic, partition, time, 1)
+			request.topicPartitions[topic] = append(request.topicPartitions[topic], partition)
+		}
+	}
+
+	type outcome struct {
+		offsets map[string]map[int32]int64
+		err     error
+	}
+	// Buffered for every leader, so that lookups finish even when the caller gave up waiting
+	results := make(chan outcome, len(requests))
+	for _, request := range requests {
+		go func() {
+			offsets, err := request.send()
+			results <- outcome{offsets: offsets, err: err}
+		}()
+	}
+	offsets := make(map[string]map[int32]int64, len(topicPartitions))
+	var errs error
+	for range requests {
+		select {
+		case result := <-results:
+			errs = errors.Join(errs, result.err)
+			for topic, partitionOffsets := range result.offsets {
+				if offsets[topic] == nil {
+					offsets[topic] = make(map[int32]int64, len(topicPartitions[topic]))
+				}
+				for partition, offset := range partitionOffsets {
+					offsets[topic][partition] = offset
+				}
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return offsets, nil
+}
+
+type leaderOffsetRequest struct {
+	leader          *sarama.Broker
+	request         *sarama.OffsetRequest
+	topicPartitions map[string][]int32
+}
+
+func (r *leaderOffsetRequest) send() (map[string]map[int32]int64, error) {
+	response, err := r.leader.GetAvailableOffsets(r.request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets on broker %d: %w", r.leader.ID(), err)
+	}
+	offsets := make(map[string]map[int32]int64, len(r.topicPartitions))
+	for topic, partitions := range r.topicPartitions {
+		offsets[topic] = make(map[int32]int64, len(partitions))
+		for _, partition := range partitions {
+			block := response.GetBlock(topic, partition)
+			if block == nil {
+				err = sarama.ErrIncompleteResponse
+			} else if !errors.Is(block.Err, sarama.ErrNoError) {
+				err = block.Err
+			} else if len(block.Offsets) != 1 {
+				err = sarama.ErrOffsetOutOfRange
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list offsets of partition %d of topic %s: %w", partition, topic, err)
+			}
+			offsets[topic][partition] = block.Offsets[0]
+		}
+	}
+	return offsets, nil
+}
+
+// offsetRequestVersion picks the newest ListOffsets request version the cluster understands, the same way sarama's
+// client does. Lookups by timestamp need at least version 1.
+func offsetRequestVersion(version sarama.KafkaVersion) int16 {
+	switch {
+	case version.IsAtLeast(sarama.V2_1_0_0):
+		return 4
+	case version.IsAtLeast(sarama.V2_0_0_0):
+		return 3
+	case version.IsAtLeast(sarama.V0_11_0_0):
+		return 2
+	case version.IsAtLeast(sarama.V0_10_1_0):
+		return 1
+	default:
+		return 0
+	}
+}
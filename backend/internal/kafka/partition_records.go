@@ -0,0 +1,187 @@
+package kafka
+
+import (
+	"cmp"
+	"encoding/binary"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"slices"
+)
+
+// partitionRecord is a record read from a partition, along with what is known of the transaction it belongs to.
+type partitionRecord struct {
+	message    *sarama.ConsumerMessage
+	producerID int64
+	// Empty for records produced outside of transactions
+	transactionState model.TransactionState
+	// Set for the commit and abort markers of transactions, whose message has neither key nor value
+	controlType model.ControlType
+}
+
+// transactionTracker tells committed from aborted transactional records by the aborted transactions that
+// read_committed fetches report, the way consumers do.
+type transactionTracker struct {
+	// Ordered by first offset, and not reached yet
+	abortedTransactions []*sarama.AbortedTransaction
+	// Producers whose current transaction was aborted
+	abortedProducers map[int64]struct{}
+}
+
+func newTransactionTracker() *transactionTracker {
+	return &transactionTracker{abortedProducers: make(map[int64]struct{})}
+}
+
+// add takes in the aborted transactions of a fetch response. Transactions already reached are reported again by
+// fetches that start within them, so they are only added once.
+func (t *transactionTracker) add(abortedTransactions []*sarama.AbortedTransaction, offset int64) {
+	for _, transaction := range abortedTransactions {
+		if transaction.FirstOffset < offset {
+			if _, ok := t.abortedProducers[transaction.ProducerID]; ok {
+				continue
+			}
+		}
+		alreadyAdded := slices.ContainsFunc(t.abortedTransactions, func(added *sarama.AbortedTransaction) bool {
+			return added.ProducerID == transaction.ProducerID && added.FirstOffset == transaction.FirstOffset
+		})
+		if !alreadyAdded {
+			t.abortedTransactions = append(t.abortedTransactions, transaction)
+		}
+	}
+	slices.SortFunc(t.abortedTransactions, func(a, b *sarama.AbortedTransaction) int {
+		return cmp.Compare(a.FirstOffset, b.FirstOffset)
+	})
+}
+
+// reach marks the producers whose aborted transactions have started by the end of batch.
+func (t *transactionTracker) reach(batch *sarama.RecordBatch) {
+	for len(t.abortedTransactions) > 0 && t.abortedTransactions[0].FirstOffset <= batch.LastOffset() {
+		t.abortedProducers[t.abortedTransactions[0].ProducerID] = struct{}{}
+		t.abortedTransactions = t.abortedTransactions[1:]
+	}
+}
+
+// state is the state of the transaction of a data batch. Batches read past the last stable offset may belong to
+// transactions that are still open.
+func (t *transactionTracker) state(batch *sarama.RecordBatch, stable bool) model.TransactionState {
+	if !batch.IsTransactional {
+		return ""
+	}
+	if !stable {
+		return model.TransactionOpen
+	}
+	if _, ok := t.abortedProducers[batch.ProducerID]; ok {
+		return model.TransactionAborted
+	}
+	return model.TransactionCommitted
+}
+
+// end closes the transaction of the producer of a control batch.
+func (t *transactionTracker) end(batch *sarama.RecordBatch) {
+	delete(t.abortedProducers, batch.ProducerID)
+}
+
+// fetchedRecords turns the records of a fetch response into partition records, leaving out those before offset.
+// stable tells whether the fetch was read_committed, so that every record in it is below the last stable offset.
+// next is the offset to fetch from after these records, past any gaps in the offsets.
+func fetchedRecords(
+	topic string,
+	partition int32,
+	offset int64,
+	block *sarama.FetchResponseBlock,
+	tracker *transactionTracker,
+	stable bool,
+) (records []*partitionRecord, next int64) {
+	next = offset
+	for _, set := range block.RecordsSet {
+		switch {
+		case set.RecordBatch != nil:
+			batch := set.RecordBatch
+			next = max(next, batch.LastOffset()+1)
+			tracker.reach(batch)
+			if batch.Control {
+				if batch.FirstOffset >= offset && len(batch.Records) > 0 {
+					records = append(records, controlRecord(topic, partition, batch))
+				}
+				tracker.end(batch)
+				continue
+			}
+			state := tracker.state(batch, stable)
+			for _, record := range batch.Records {
+				recordOffset := batch.FirstOffset + record.OffsetDelta
+				if recordOffset < offset {
+					continue
+				}
+				timestamp := batch.FirstTimestamp.Add(record.TimestampDelta)
+				if batch.LogAppendTime {
+					timestamp = batch.MaxTimestamp
+				}
+				records = append(records, &partitionRecord{
+					message: &sarama.ConsumerMessage{
+						Topic:     topic,
+						Partition: partition,
+						Key:       record.Key,
+						Value:     record.Value,
+						Offset:    recordOffset,
+						Timestamp: timestamp,
+						Headers:   record.Headers,
+					},
+					producerID:       batch.ProducerID,
+					transactionState: state,
+				})
+			}
+		case set.MsgSet != nil:
+			for _, messageBlock := range set.MsgSet.Messages {
+				inner := messageBlock.Messages()
+				for _, message := range inner {
+					messageOffset := message.Offset
+					timestamp := message.Msg.Timestamp
+					if message.Msg.Version >= 1 {
+						// Messages within a compressed wrapper have offsets relative to it
+						messageOffset += messageBlock.Offset - inner[len(inner)-1].Offset
+						if message.Msg.LogAppendTime {
+							timestamp = messageBlock.Msg.Timestamp
+						}
+					}
+					next = max(next, messageOffset+1)
+					if messageOffset < offset {
+						continue
+					}
+					records = append(records, &partitionRecord{
+						message: &sarama.ConsumerMessage{
+							Topic:     topic,
+							Partition: partition,
+							Key:       message.Msg.Key,
+							Value:     message.Msg.Value,
+							Offset:    messageOffset,
+							Timestamp: timestamp,
+						},
+						producerID: -1,
+					})
+				}
+			}
+		}
+	}
+	return records, next
+}
+
+// controlRecord reads the commit or abort marker in a control batch, whose key holds a version and the marker type.
+func controlRecord(topic string, partition int32, batch *sarama.RecordBatch) *partitionRecord {
+	controlType := model.ControlAbort
+	transactionState := model.TransactionAborted
+	key := batch.Records[0].Key
+	if len(key) >= 4 && binary.BigEndian.Uint16(key[2:4]) == uint16(sarama.ControlRecordCommit) {
+		controlType = model.ControlCommit
+		transactionState = model.TransactionCommitted
+	}
+	return &partitionRecord{
+		message: &sarama.ConsumerMessage{
+			Topic:     topic,
+			Partition: partition,
+			Offset:    batch.FirstOffset,
+			Timestamp: batch.FirstTimestamp,
+		},
+		producerID:       batch.ProducerID,
+		transactionState: transactionState,
+		controlType:      controlType,
+	}
+}
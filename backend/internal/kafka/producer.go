@@ -0,0 +1,43 @@
+package kafka
+
+import (
+	"errors"
+	"github.com/IBM/sarama"
+)
+
+// newIdempotentProducerConfig derives an idempotent producer from a cluster configuration, so that retries
+// cannot duplicate records. Records are partitioned by key the way the Java producer partitions them unless
+// keepPartition is set, in which case every message must carry its partition.
+func newIdempotentProducerConfig(base *sarama.Config, keepPartition bool) *sarama.Config {
+	config := *base
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	config.Producer.Idempotent = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Net.MaxOpenRequests = 1
+	if keepPartition {
+		config.Producer.Partitioner = sarama.NewManualPartitioner
+	} else {
+		config.Producer.Partitioner = sarama.NewCustomPartitioner(
+			sarama.WithAbsFirst(),
+			sarama.WithCustomHashFunction(newMurmur2),
+		)
+	}
+	return &config
+}
+
+// sendBatch produces batch and returns how many of its messages were written.
+func sendBatch(producer sarama.SyncProducer, batch []*sarama.ProducerMessage) (int, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	err := producer.SendMessages(batch)
+	var producerErrors sarama.ProducerErrors
+	if errors.As(err, &producerErrors) {
+		return len(batch) - len(producerErrors), err
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
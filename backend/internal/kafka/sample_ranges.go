@@ -0,0 +1,33 @@
+package kafka
+
+import (
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"math"
+)
+
+// getSampleRanges spreads a sample of at most sampleSize messages over the newest offsets of the partitions, in
+// proportion to the offsets each holds, so that busy partitions weigh in the sample as much as they do in the topic.
+func getSampleRanges(
+	partitions []int32,
+	oldestOffsets map[int32]int64,
+	newestOffsets map[int32]int64,
+	sampleSize int64,
+) model.PartitionInput {
+	totalOffsets := int64(0)
+	for _, partition := range partitions {
+		totalOffsets += newestOffsets[partition] - oldestOffsets[partition]
+	}
+	ranges := model.PartitionInput{PartitionDetailsMap: make(map[int32]model.PartitionDetails)}
+	for _, partition := range partitions {
+		offsets := newestOffsets[partition] - oldestOffsets[partition]
+		if offsets == 0 {
+			continue
+		}
+		sample := min(offsets, int64(math.Ceil(float64(sampleSize)*float64(offsets)/float64(totalOffsets))))
+		ranges.PartitionDetailsMap[partition] = model.PartitionDetails{
+			StartOffset: newestOffsets[partition] - sample,
+			EndOffset:   newestOffsets[partition] - 1,
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,145 @@
+package kafka
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+const tableProgressInterval = 1000
+
+// ScanTable reads every message of a compacted topic up to its high watermarks and keeps the newest message of every
+// key. A key that was written to more than one partition keeps its newest message by timestamp. Returns nil when the
+// topic doesn't exist.
+func (k *KafkaService) ScanTable(
+	ctx context.Context,
+	topic string,
+	onProgress func(scanned int64),
+) (*model.TopicTable, error) {
+	err := k.authorizer.Authorize(ctx, auth.OperationViewMessages, auth.Resource{Topic: topic})
+	if err != nil {
+		return nil, err
+	}
+	topics, err := k.GetTopics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+	index := slices.IndexFunc(topics, func(details model.TopicDetails) bool {
+		return details.Name == topic
+	})
+	if index < 0 {
+		return nil, nil
+	}
+	details := topics[index]
+	if details.CleanupPolicy != model.CleanupPolicyCompact && details.CleanupPolicy != model.CleanupPolicyBoth {
+		return nil, fmt.Errorf("topic %s is not compacted", topic)
+	}
+
+	partitions, err := k.getTopicPartitions(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	newestOffsets, err := k.getOffsets(ctx, topic, partitions, sarama.OffsetNewest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newest offsets: %w", err)
+	}
+	scanInput := model.PartitionInput{PartitionDetailsMap: make(map[int32]model.PartitionDetails, len(partitions))}
+	for _, partition := range partitions {
+		if newestOffsets[partition] > 0 {
+			scanInput.PartitionDetailsMap[partition] = model.PartitionDetails{
+				StartOffset: 0,
+				EndOffset:   newestOffsets[partition] - 1,
+			}
+		}
+	}
+
+	table := &model.TopicTable{Summary: model.TableSummary{Topic: topic}}
+	summary := &table.Summary
+	rows := make(map[string]*model.TableRow)
+	err = k.streamMessagesForTopic(ctx, topic, scanInput, nil, func(record *model.Record) error {
+		if summary.ScannedMessages++; summary.ScannedMessages%tableProgressInterval == 0 {
+			onProgress(summary.ScannedMessages)
+		}
+		if record.Key == nil {
+			summary.NullKeys++
+			return nil
+		}
+		previous, ok := rows[string(record.Key)]
+		if ok {
+			summary.DuplicateMessages++
+			// Messages of a partition are handed over in order, so only keys spread over partitions are compared
+			if previous.Partition != record.Partition && record.Timestamp.Before(previous.Timestamp) {
+				return nil
+			}
+		}
+		rows[string(record.Key)] = toTableRow(record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	onProgress(summary.ScannedMessages)
+
+	table.Rows = make([]model.TableRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Tombstone {
+			summary.Tombstones++
+		} else {
+			summary.Keys++
+		}
+		table.Rows = append(table.Rows, *row)
+	}
+	slices.SortFunc(table.Rows, func(a, b model.TableRow) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	if summary.ScannedMessages > 0 {
+		summary.DirtyRatio = float64(summary.DuplicateMessages) / float64(summary.ScannedMessages)
+	}
+	summary.MinCleanableDirtyRatio = k.getMinCleanableDirtyRatio(ctx, details)
+	return table, nil
+}
+
+func toTableRow(record *model.Record) *model.TableRow {
+	row := &model.TableRow{
+		Key:       string(record.Key),
+		Partition: record.Partition,
+		Offset:    record.Offset,
+		Timestamp: record.Timestamp,
+		Tombstone: record.Value == nil,
+	}
+	if !utf8.Valid(record.Key) {
+		row.Key = base64.StdEncoding.EncodeToString(record.Key)
+		row.Base64 = true
+	}
+	if !row.Tombstone {
+		row.Message = record.Message
+	}
+	return row
+}
+
+// getMinCleanableDirtyRatio reads the min.cleanable.dirty.ratio of a topic, which falls back to the
+// log.cleaner.min.cleanable.ratio of the brokers.
+func (k *KafkaService) getMinCleanableDirtyRatio(ctx context.Context, details model.TopicDetails) *float64 {
+	value, ok := details.AdditionalConfigs["min.cleanable.dirty.ratio"]
+	if !ok {
+		brokerConfig, err := k.getBrokerConfig(ctx)
+		if err != nil {
+			k.logger.Warn("failed to get the broker min cleanable ratio", zap.Error(err))
+			return nil
+		}
+		value = brokerConfig["log.cleaner.min.cleanable.ratio"]
+	}
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &ratio
+}
@@ -0,0 +1,166 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"sync"
+)
+
+const streamBufferSize = 256
+
+// StreamMessagesForTopic fetches the same ranges as GetLastMessagesForTopic, but hands every record matching the
+// filter to handle as soon as it is consumed instead of collecting them. handle is never called concurrently.
+// Streaming stops at the first error returned by handle. Partitions that fail are reported once the others are done.
+func (k *KafkaService) StreamMessagesForTopic(
+	ctx context.Context,
+	topic string,
+	partitionData model.PartitionInput,
+	filter *model.MessageFilter,
+	handle func(record *model.Record) error,
+) error {
+	err := k.authorizer.Authorize(ctx, auth.OperationViewMessages, auth.Resource{Topic: topic})
+	if err != nil {
+		return err
+	}
+	return k.streamMessagesForTopic(ctx, topic, partitionData, filter, handle)
+}
+
+// streamMessagesForTopic is StreamMessagesForTopic for callers that were authorized to read topic, or that only
+// report what the records of topic don't reveal.
+func (k *KafkaService) streamMessagesForTopic(
+	ctx context.Context,
+	topic string,
+	partitionData model.PartitionInput,
+	filter *model.MessageFilter,
+	handle func(record *model.Record) error,
+) error {
+	compiledFilter, err := compileMessageFilter(filter)
+	if err != nil {
+		return fmt.Errorf("invalid message filter: %w", err)
+	}
+
+	partitionArgs, err := k.getRequestedPartitions(ctx, topic, partitionData)
+	if err != nil {
+		return err
+	}
+	if len(partitionArgs) == 0 {
+		return nil
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := make(chan *model.Record, streamBufferSize)
+	var partitionErrors []error
+	var partitionErrorsMutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, args := range partitionArgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := k.streamPartition(streamCtx, args, compiledFilter, records)
+			// Partitions interrupted by cancellation aren't failures of their own
+			if err != nil && streamCtx.Err() == nil {
+				k.logger.Error(
+					"failed to stream messages for partition",
+					zap.String("topic", args.topic),
+					zap.Int32("partition", args.partition),
+					zap.Error(err),
+				)
+				partitionErrorsMutex.Lock()
+				partitionErrors = append(partitionErrors, fmt.Errorf("partition %d: %w", args.partition, err))
+				partitionErrorsMutex.Unlock()
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(records)
+	}()
+
+	for record := range records {
+		if err := handle(record); err != nil {
+			cancel()
+			for range records {
+			}
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(partitionErrors...)
+}
+
+func (k *KafkaService) streamPartition(
+	ctx context.Context,
+	args getMessagesForPartitionArgs,
+	filter *compiledMessageFilter,
+	records chan<- *model.Record,
+) error {
+	startOffset, endOffset, hasMessages, err := k.resolvePartitionRange(
+		ctx,
+		args.topic,
+		args.partition,
+		args.startOffset,
+		args.endOffset,
+	)
+	if err != nil || !hasMessages {
+		return err
+	}
+
+	_, err = k.consumePartition(
+		ctx,
+		args.topic,
+		args.partition,
+		startOffset,
+		endOffset,
+		model.ReadUncommitted,
+		func(partitionRecord *partitionRecord) bool {
+			if partitionRecord.controlType != "" {
+				return true
+			}
+			record := k.toRecord(partitionRecord.message)
+			if !filter.matches(record) {
+				return true
+			}
+			select {
+			case records <- record:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		},
+	)
+	return err
+}
+
+// toRecord keeps the raw record even when it cannot be decoded, so that raw exports remain complete.
+func (k *KafkaService) toRecord(message *sarama.ConsumerMessage) *model.Record {
+	headers := make([]model.RecordHeader, 0, len(message.Headers))
+	for _, header := range message.Headers {
+		if header == nil {
+			continue
+		}
+		headers = append(headers, model.RecordHeader{Key: string(header.Key), Value: header.Value})
+	}
+	decodedMessage, err := k.decodeKeyAndValue(message)
+	if err != nil {
+		decodedMessage = nil
+	}
+	return &model.Record{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Timestamp: message.Timestamp,
+		Key:       message.Key,
+		Value:     message.Value,
+		Headers:   headers,
+		Message:   decodedMessage,
+	}
+}
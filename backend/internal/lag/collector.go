@@ -0,0 +1,100 @@
+package lag
+
+import (
+	"cmp"
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+)
+
+type Config struct {
+	// How often the committed offsets of every group and the high watermarks are polled
+	Interval time.Duration
+	// How long every sample is kept
+	RawRetention time.Duration
+	// The coarser resolutions that samples are merged into, so that they can be kept longer
+	Downsampling []Tier
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Interval:     30 * time.Second,
+		RawRetention: 24 * time.Hour,
+		Downsampling: []Tier{
+			{Resolution: 5 * time.Minute, Retention: 7 * 24 * time.Hour},
+			{Resolution: time.Hour, Retention: 90 * 24 * time.Hour},
+		},
+	}
+}
+
+// Collector polls the lag of every consumer group in the background and keeps its history in a store.
+type Collector struct {
+	kafkaService *kafka.KafkaService
+	store        *Store
+	config       Config
+	// The raw samples first, followed by the downsampled tiers from the finest to the coarsest
+	tiers  []Tier
+	logger *zap.Logger
+}
+
+// NewCollector creates a collector that polls nothing until it is run.
+func NewCollector(kafkaService *kafka.KafkaService, store *Store, config Config, logger *zap.Logger) *Collector {
+	tiers := []Tier{{Retention: config.RawRetention}}
+	downsampling := slices.Clone(config.Downsampling)
+	slices.SortFunc(downsampling, func(a, b Tier) int {
+		return cmp.Compare(a.Resolution, b.Resolution)
+	})
+	tiers = append(tiers, downsampling...)
+	return &Collector{
+		kafkaService: kafkaService,
+		store:        store,
+		config:       config,
+		tiers:        tiers,
+		logger:       logger,
+	}
+}
+
+// Run polls the lag every interval and deletes the points past retention until ctx is done.
+func (c *Collector) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := c.collect(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Warn("failed to collect consumer lag", zap.Error(err))
+		}
+		if err := c.store.Prune(c.tiers, time.Now()); err != nil {
+			c.logger.Warn("failed to prune consumer lag history", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Collector) collect(ctx context.Context) error {
+	offsets, err := c.kafkaService.GetGroupOffsets(ctx)
+	if err != nil {
+		return err
+	}
+	sampledAt := time.Now()
+	points := make(map[string]map[string]Point, len(offsets))
+	for group, topicOffsets := range offsets {
+		points[group] = make(map[string]Point, len(topicOffsets))
+		for topic, partitionOffsets := range topicOffsets {
+			point := Point{Time: sampledAt, Partitions: len(partitionOffsets)}
+			for _, partitionOffset := range partitionOffsets {
+				point.Committed += partitionOffset.Committed
+				point.HighWatermark += partitionOffset.HighWatermark
+				// Retention can delete records past the committed offset, which the group then skips
+				point.Lag += max(0, partitionOffset.HighWatermark-partitionOffset.Committed)
+			}
+			point.MaxLag = point.Lag
+			points[group][topic] = point
+		}
+	}
+	return c.store.Add(c.tiers, points)
+}
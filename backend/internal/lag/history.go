@@ -0,0 +1,111 @@
+package lag
+
+import (
+	"time"
+)
+
+// The most points a history is meant to hold. Longer ranges are read from a coarser tier
+const maxHistoryPoints = 1000
+
+// History is the lag of a consumer group on a topic over a range of time.
+type History struct {
+	Group string `json:"group" validate:"required"`
+	Topic string `json:"topic" validate:"required"`
+	// The span of time that each point covers. 0 when every sample is returned as it was taken
+	ResolutionSeconds int64          `json:"resolutionSeconds" validate:"required"`
+	Points            []HistoryPoint `json:"points" validate:"required"`
+}
+
+type HistoryPoint struct {
+	// When the offsets were sampled. For downsampled points, when the latest sample they hold was taken
+	Timestamp time.Time `json:"timestamp" validate:"required"`
+	// The committed offsets of every partition the group committed for, summed
+	CommittedOffset int64 `json:"committedOffset" validate:"required"`
+	// The high watermarks of the same partitions, summed
+	HighWatermark int64 `json:"highWatermark" validate:"required"`
+	Lag           int64 `json:"lag" validate:"required"`
+	// The highest lag of the samples the point holds
+	MaxLag int64 `json:"maxLag" validate:"required"`
+	// The messages per second the group consumed since the previous point. Missing for the first point, and when
+	// the group committed for other partitions or its offsets were reset since
+	ConsumeRate *float64 `json:"consumeRate,omitempty"`
+	// The messages per second produced to the partitions since the previous point, missing likewise
+	ProduceRate *float64 `json:"produceRate,omitempty"`
+	// How long the group takes to consume its lag when both rates hold. Missing when it doesn't catch up at them
+	TimeToCatchUpSeconds *float64 `json:"timeToCatchUpSeconds,omitempty"`
+}
+
+// GetHistory returns the lag of group on topic from from up to to. The points are read from the finest tier that
+// still holds from and has no more than about maxHistoryPoints in the range.
+func (c *Collector) GetHistory(group string, topic string, from time.Time, to time.Time) (*History, error) {
+	tier := c.getTier(from, to, time.Now())
+	points, err := c.store.Get(tier, group, topic, from, to)
+	if err != nil {
+		return nil, err
+	}
+	history := &History{
+		Group:             group,
+		Topic:             topic,
+		ResolutionSeconds: int64(tier.Resolution.Seconds()),
+		Points:            make([]HistoryPoint, 0, len(points)),
+	}
+	for i, point := range points {
+		historyPoint := HistoryPoint{
+			Timestamp:       point.Time,
+			CommittedOffset: point.Committed,
+			HighWatermark:   point.HighWatermark,
+			Lag:             point.Lag,
+			MaxLag:          point.MaxLag,
+		}
+		if i > 0 {
+			addRates(&historyPoint, points[i-1], point)
+		}
+		history.Points = append(history.Points, historyPoint)
+	}
+	return history, nil
+}
+
+func (c *Collector) getTier(from time.Time, to time.Time, now time.Time) Tier {
+	for _, tier := range c.tiers {
+		resolution := max(tier.Resolution, c.config.Interval)
+		if !from.Before(now.Add(-tier.Retention)) && to.Sub(from)/resolution <= maxHistoryPoints {
+			return tier
+		}
+	}
+	return c.tiers[len(c.tiers)-1]
+}
+
+// addRates sets the rates of point from the growth of the offsets since previous, and how long catching up takes
+// at them.
+func addRates(point *HistoryPoint, previous Point, current Point) {
+	if point.Lag == 0 {
+		timeToCatchUp := 0.0
+		point.TimeToCatchUpSeconds = &timeToCatchUp
+	}
+	elapsed := current.Time.Sub(previous.Time).Seconds()
+	consumed := current.Committed - previous.Committed
+	produced := current.HighWatermark - previous.HighWatermark
+	// A different set of partitions, reset offsets or a recreated topic make the sums incomparable
+	if elapsed <= 0 || current.Partitions != previous.Partitions || consumed < 0 || produced < 0 {
+		return
+	}
+	consumeRate := float64(consumed) / elapsed
+	produceRate := float64(produced) / elapsed
+	point.ConsumeRate = &consumeRate
+	point.ProduceRate = &produceRate
+	if point.Lag > 0 && consumeRate > produceRate {
+		timeToCatchUp := float64(point.Lag) / (consumeRate - produceRate)
+		point.TimeToCatchUpSeconds = &timeToCatchUp
+	}
+}
+
+// GetSamples returns every sample of every group and topic taken since from, oldest first. Samples older than the
+// raw retention are gone.
+func (c *Collector) GetSamples(from time.Time) (map[string]map[string][]Point, error) {
+	return c.store.GetAll(c.tiers[0], from, time.Now())
+}
+
+// Interval is how often the collector takes samples.
+func (c *Collector) Interval() time.Duration {
+	return c.config.Interval
+}
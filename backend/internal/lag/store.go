@@ -0,0 +1,243 @@
+package lag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"go.etcd.io/bbolt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Point is the lag of a consumer group on a topic, summed over the partitions it committed offsets for.
+type Point struct {
+	// When the offsets were sampled. For downsampled points, when the latest sample they hold was taken
+	Time          time.Time `json:"time"`
+	Committed     int64     `json:"committed"`
+	HighWatermark int64     `json:"highWatermark"`
+	Lag           int64     `json:"lag"`
+	// The highest lag of the samples a downsampled point holds
+	MaxLag     int64 `json:"maxLag"`
+	Partitions int   `json:"partitions"`
+}
+
+// Tier is a resolution that points are kept at, and for how long.
+type Tier struct {
+	// Samples within the same span of Resolution are merged into one point. Zero keeps every sample
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+func (t Tier) bucket() []byte {
+	if t.Resolution == 0 {
+		return []byte("raw")
+	}
+	return []byte(t.Resolution.String())
+}
+
+func (t Tier) key(sampledAt time.Time) []byte {
+	if t.Resolution > 0 {
+		sampledAt = sampledAt.Truncate(t.Resolution)
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(sampledAt.UnixMilli()))
+	return key
+}
+
+// Store keeps the lag history in a BoltDB file, in a bucket per tier holding a bucket per group and topic, whose
+// points are keyed by time so that they are iterated in order.
+type Store struct {
+	db *bbolt.DB
+}
+
+// OpenStore opens the store at path, creating it when it doesn't exist.
+func OpenStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create lag history directory: %w", err)
+	}
+	db, err := bbolt.Open(path, 0o644, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lag history: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add records the points of every group and topic sampled at the same time in every tier. Points that fall into the
+// span of an existing downsampled point replace its values, keeping the highest lag.
+func (s *Store) Add(tiers []Tier, points map[string]map[string]Point) error {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		for _, tier := range tiers {
+			tierBucket, err := tx.CreateBucketIfNotExists(tier.bucket())
+			if err != nil {
+				return err
+			}
+			for group, topicPoints := range points {
+				groupBucket, err := tierBucket.CreateBucketIfNotExists([]byte(group))
+				if err != nil {
+					return err
+				}
+				for topic, point := range topicPoints {
+					topicBucket, err := groupBucket.CreateBucketIfNotExists([]byte(topic))
+					if err != nil {
+						return err
+					}
+					key := tier.key(point.Time)
+					if existing := topicBucket.Get(key); existing != nil {
+						var previous Point
+						if err := json.Unmarshal(existing, &previous); err != nil {
+							return fmt.Errorf("failed to parse point: %w", err)
+						}
+						point.MaxLag = max(point.MaxLag, previous.MaxLag)
+					}
+					value, err := json.Marshal(point)
+					if err != nil {
+						return fmt.Errorf("failed to encode point: %w", err)
+					}
+					if err := topicBucket.Put(key, value); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add lag points: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes the points of every tier that are older than its retention, along with groups and topics that have
+// no points left.
+func (s *Store) Prune(tiers []Tier, now time.Time) error {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		for _, tier := range tiers {
+			tierBucket := tx.Bucket(tier.bucket())
+			if tierBucket == nil {
+				continue
+			}
+			cutoff := Tier{}.key(now.Add(-tier.Retention))
+			var emptyGroups [][]byte
+			err := tierBucket.ForEachBucket(func(group []byte) error {
+				groupBucket := tierBucket.Bucket(group)
+				var emptyTopics [][]byte
+				err := groupBucket.ForEachBucket(func(topic []byte) error {
+					cursor := groupBucket.Bucket(topic).Cursor()
+					key, _ := cursor.First()
+					for key != nil && bytes.Compare(key, cutoff) < 0 {
+						if err := cursor.Delete(); err != nil {
+							return err
+						}
+						key, _ = cursor.First()
+					}
+					if key == nil {
+						emptyTopics = append(emptyTopics, topic)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				for _, topic := range emptyTopics {
+					if err := groupBucket.DeleteBucket(topic); err != nil {
+						return err
+					}
+				}
+				if key, _ := groupBucket.Cursor().First(); key == nil {
+					emptyGroups = append(emptyGroups, group)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, group := range emptyGroups {
+				if err := tierBucket.DeleteBucket(group); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune lag points: %w", err)
+	}
+	return nil
+}
+
+// Get returns the points of group on topic in tier from from up to to, oldest first.
+func (s *Store) Get(tier Tier, group string, topic string, from time.Time, to time.Time) ([]Point, error) {
+	points := make([]Point, 0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		topicBucket := tx.Bucket(tier.bucket())
+		for _, name := range []string{group, topic} {
+			if topicBucket == nil {
+				return nil
+			}
+			topicBucket = topicBucket.Bucket([]byte(name))
+		}
+		if topicBucket == nil {
+			return nil
+		}
+		var err error
+		points, err = getPoints(topicBucket, tier, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lag points: %w", err)
+	}
+	return points, nil
+}
+
+// GetAll returns the points of every group and topic in tier from from up to to, oldest first.
+func (s *Store) GetAll(tier Tier, from time.Time, to time.Time) (map[string]map[string][]Point, error) {
+	points := make(map[string]map[string][]Point)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		tierBucket := tx.Bucket(tier.bucket())
+		if tierBucket == nil {
+			return nil
+		}
+		return tierBucket.ForEachBucket(func(group []byte) error {
+			groupBucket := tierBucket.Bucket(group)
+			return groupBucket.ForEachBucket(func(topic []byte) error {
+				topicPoints, err := getPoints(groupBucket.Bucket(topic), tier, from, to)
+				if err != nil || len(topicPoints) == 0 {
+					return err
+				}
+				if points[string(group)] == nil {
+					points[string(group)] = make(map[string][]Point)
+				}
+				points[string(group)][string(topic)] = topicPoints
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lag points: %w", err)
+	}
+	return points, nil
+}
+
+// getPoints reads the points of a topic bucket from from up to to. The point whose span holds from is included.
+func getPoints(topicBucket *bbolt.Bucket, tier Tier, from time.Time, to time.Time) ([]Point, error) {
+	points := make([]Point, 0)
+	cursor := topicBucket.Cursor()
+	end := Tier{}.key(to)
+	for key, value := cursor.Seek(tier.key(from)); key != nil; key, value = cursor.Next() {
+		if bytes.Compare(key, end) > 0 {
+			break
+		}
+		var point Point
+		if err := json.Unmarshal(value, &point); err != nil {
+			return nil, fmt.Errorf("failed to parse point: %w", err)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// SilenceInputDTO represents the alerts to silence and for how long
+// @swagger:model SilenceInputDTO
+type SilenceInputDTO struct {
+	// A glob the rule of silenced alerts matches. Every rule when omitted
+	Rule string `json:"rule,omitempty"`
+	// A glob the consumer group of silenced alerts matches. Every group when omitted
+	Group string `json:"group,omitempty"`
+	// A glob the topic of silenced alerts matches. Every topic when omitted
+	Topic string `json:"topic,omitempty"`
+	// Why the alerts are silenced
+	Comment string `json:"comment,omitempty"`
+	// When the silence ends. Either this or durationSeconds is required
+	EndsAt *time.Time `json:"endsAt,omitempty"`
+	// How long the silence lasts from now
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+}
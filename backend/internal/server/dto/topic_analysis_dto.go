@@ -0,0 +1,14 @@
+package dto
+
+// KeyDistributionInputDTO represents the input data structure for analyzing how the keys of a topic are distributed
+// @swagger:model KeyDistributionInputDTO
+type KeyDistributionInputDTO struct {
+	// The name of the topic to analyze
+	TopicName string `json:"topicName" validate:"required"`
+	// The most messages sampled from the ends of the partitions. Defaults to 100000
+	SampleSize int64 `json:"sampleSize,omitempty"`
+	// The number of most frequent keys reported. Defaults to 20
+	TopK int `json:"topK,omitempty"`
+	// How many times the mean messages or size a partition holds for the topic to be imbalanced. Defaults to 1.5
+	ImbalanceThreshold float64 `json:"imbalanceThreshold,omitempty"`
+}
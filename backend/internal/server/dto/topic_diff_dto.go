@@ -0,0 +1,25 @@
+package dto
+
+// TopicDiffInputDTO represents the input data structure for comparing the records of two topics or offset ranges
+// @swagger:model TopicDiffInputDTO
+type TopicDiffInputDTO struct {
+	// The records that are expected
+	Source DiffSourceDTO `json:"source" validate:"required"`
+	// The records that are compared with the source
+	Target DiffSourceDTO `json:"target" validate:"required"`
+	// JSON paths left out when comparing JSON values, such as $.updatedAt
+	IgnorePaths []string `json:"ignorePaths,omitempty"`
+	// The most missing, extra and differing records listed each. Defaults to 100
+	MaxReported int `json:"maxReported,omitempty"`
+}
+
+// DiffSourceDTO represents one side of a comparison
+// @swagger:model DiffSourceDTO
+type DiffSourceDTO struct {
+	// The name of a configured cluster. Defaults to the connected cluster
+	Cluster string `json:"cluster,omitempty"`
+	// The name of the topic to read
+	TopicName string `json:"topicName" validate:"required"`
+	// The offset ranges to compare. All partitions are compared in full when omitted
+	Partitions []TopicPartitionInputDTO `json:"partitions,omitempty"`
+}
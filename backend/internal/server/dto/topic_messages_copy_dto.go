@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TopicMessagesCopyInputDTO represents the input data structure for copying messages between topics
+// @swagger:model TopicMessagesCopyInputDTO
+type TopicMessagesCopyInputDTO struct {
+	// Where to copy the messages from
+	Source CopySourceDTO `json:"source" validate:"required"`
+	// Where to copy the messages to
+	Destination CopyDestinationDTO `json:"destination" validate:"required"`
+	// Whether the copies keep the key of the original messages. Defaults to true
+	KeepKey *bool `json:"keepKey,omitempty"`
+	// Whether the copies keep the headers of the original messages. Defaults to true
+	KeepHeaders *bool `json:"keepHeaders,omitempty"`
+	// Whether the copies are produced to the partition of the original messages instead of being partitioned by key
+	KeepPartition bool `json:"keepPartition,omitempty"`
+	// Operations applied in order to JSON values. Values that are not JSON are copied unchanged
+	ValueTransform []TransformStepDTO `json:"valueTransform,omitempty"`
+}
+
+// CopySourceDTO represents the messages to copy
+// @swagger:model CopySourceDTO
+type CopySourceDTO struct {
+	// The name of the topic to copy messages from
+	TopicName string `json:"topicName" validate:"required"`
+	// The offset ranges to copy. All partitions are copied in full when omitted
+	Partitions []TopicPartitionInputDTO `json:"partitions,omitempty"`
+	// Only copy messages produced at or after this time
+	StartTime *time.Time `json:"startTime,omitempty"`
+	// Only copy messages produced at or before this time
+	EndTime *time.Time `json:"endTime,omitempty"`
+	// Only copy messages matching this filter
+	Filter *MessageFilterDTO `json:"filter,omitempty"`
+}
+
+// CopyDestinationDTO represents the topic to copy messages to
+// @swagger:model CopyDestinationDTO
+type CopyDestinationDTO struct {
+	// The name of a configured cluster. Defaults to the cluster of the source
+	Cluster string `json:"cluster,omitempty"`
+	// The name of the topic to copy messages to
+	TopicName string `json:"topicName" validate:"required"`
+}
+
+// TransformStepDTO represents an operation on a JSON value
+// @swagger:model TransformStepDTO
+type TransformStepDTO struct {
+	// The operation, either set or remove
+	Op string `json:"op" validate:"required" enums:"set,remove"`
+	// The JSON path the operation applies to, such as $.customer.email
+	Path string `json:"path" validate:"required"`
+	// The JSON value a set operation stores at the path
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
@@ -7,6 +7,27 @@ type TopicMessagesInputDTO struct {
 	TopicName string `json:"topicName" validate:"required"`
 	// The Partition request data of the topic to fetch messages from
 	Partitions []TopicPartitionInputDTO `json:"partitions" validate:"required"`
+	// Whether the messages of aborted and open transactions are left out, read_uncommitted by default
+	IsolationLevel string `json:"isolationLevel,omitempty" enums:"read_uncommitted,read_committed"`
+	// Whether the commit and abort markers of transactions are returned as messages of their own
+	IncludeControlRecords bool `json:"includeControlRecords,omitempty"`
+	// The order of the messages, partition_offset by default. timestamp merges the partitions by timestamp
+	Order string `json:"order,omitempty" enums:"partition_offset,timestamp,newest_first"`
+}
+
+// LatestTopicMessagesInputDTO represents the input data structure for the newest messages across a whole topic
+// @swagger:model LatestTopicMessagesInputDTO
+type LatestTopicMessagesInputDTO struct {
+	// The name of the topic to fetch messages from
+	TopicName string `json:"topicName" validate:"required"`
+	// The number of messages to fetch, which are the newest by timestamp across all partitions
+	Count int `json:"count" validate:"required"`
+	// Whether the messages of aborted and open transactions are left out, read_uncommitted by default
+	IsolationLevel string `json:"isolationLevel,omitempty" enums:"read_uncommitted,read_committed"`
+	// Whether the commit and abort markers of transactions are returned as messages of their own
+	IncludeControlRecords bool `json:"includeControlRecords,omitempty"`
+	// The order of the messages, partition_offset by default. timestamp merges the partitions by timestamp
+	Order string `json:"order,omitempty" enums:"partition_offset,timestamp,newest_first"`
 }
 
 // TopicPartitionInputDTO represents the partition request data of the topic to fetch messages from
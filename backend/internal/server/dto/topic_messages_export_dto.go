@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// TopicMessagesExportInputDTO represents the input data structure for exporting the messages of a topic to a file
+// @swagger:model TopicMessagesExportInputDTO
+type TopicMessagesExportInputDTO struct {
+	// The name of the topic to export messages from
+	TopicName string `json:"topicName" validate:"required"`
+	// The Partition request data of the topic to export messages from
+	Partitions []TopicPartitionInputDTO `json:"partitions" validate:"required"`
+	// The file format of the export, one of jsonl, csv or avro
+	Format string `json:"format" validate:"required" enums:"jsonl,csv,avro"`
+	// The columns of a CSV export. Defaults to partition, offset, timestamp, key and value
+	Columns []ExportColumnDTO `json:"columns,omitempty"`
+	// Whether the export is gzip compressed
+	Gzip bool `json:"gzip,omitempty"`
+	// Whether a JSONL export also carries the raw key and value bytes as base64, which makes it replayable
+	IncludeRawBytes bool `json:"includeRawBytes,omitempty"`
+	// Only export messages matching this filter
+	Filter *MessageFilterDTO `json:"filter,omitempty"`
+}
+
+// ExportColumnDTO represents a column of a CSV export
+// @swagger:model ExportColumnDTO
+type ExportColumnDTO struct {
+	// The header of the column. Defaults to the field
+	Header string `json:"header,omitempty"`
+	// The field of the column, either one of topic, partition, offset, timestamp, key, value and headers,
+	// or a JSON path into the decoded value such as $.order.id
+	Field string `json:"field" validate:"required"`
+}
+
+// MessageFilterDTO represents a filter on the messages of a topic
+// @swagger:model MessageFilterDTO
+type MessageFilterDTO struct {
+	// Only match messages whose decoded key contains this string
+	KeyContains string `json:"keyContains,omitempty"`
+	// Only match messages whose decoded value contains this string
+	ValueContains string `json:"valueContains,omitempty"`
+	// Only match messages produced at or after this time
+	StartTime *time.Time `json:"startTime,omitempty"`
+	// Only match messages produced at or before this time
+	EndTime *time.Time `json:"endTime,omitempty"`
+	// A JSON path into the decoded value, such as $.order.status, which must equal ValueEquals
+	ValuePath string `json:"valuePath,omitempty"`
+	// The value the element at ValuePath must equal, compared as text
+	ValueEquals string `json:"valueEquals,omitempty"`
+}
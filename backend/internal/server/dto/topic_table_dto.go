@@ -0,0 +1,8 @@
+package dto
+
+// TableScanInputDTO represents the input data structure for scanning a compacted topic as a table
+// @swagger:model TableScanInputDTO
+type TableScanInputDTO struct {
+	// The name of the compacted topic to scan
+	TopicName string `json:"topicName" validate:"required"`
+}
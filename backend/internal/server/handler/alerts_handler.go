@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Avi18971911/kafka-window/backend/internal/alert"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/dto"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AlertsHandler creates a handler for listing alerts.
+// @Summary List the alerts that fire and those that resolved in the last day, most recently fired first.
+// @Tags alerts
+// @Produce json
+// @Param state query string false "Only return alerts in this state" Enums(firing, resolved)
+// @Success 200 {array} alert.Alert "List of alerts"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Router /alerts [get]
+func AlertsHandler(
+	engine *alert.Engine,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(w, r, authorizer, auth.OperationViewAlerts, auth.Resource{}, logger) {
+			return
+		}
+		state := alert.State(r.URL.Query().Get("state"))
+		if state != "" && state != alert.StateFiring && state != alert.StateResolved {
+			HttpError(w, "unsupported alert state: "+string(state), http.StatusBadRequest, logger)
+			return
+		}
+		err := json.NewEncoder(w).Encode(engine.Alerts(state))
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// SilencesHandler creates a handler for listing alert silences.
+// @Summary List the silences that haven't ended, ending soonest first.
+// @Tags alerts
+// @Produce json
+// @Success 200 {array} alert.Silence "List of silences"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Router /alerts/silences [get]
+func SilencesHandler(
+	engine *alert.Engine,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(w, r, authorizer, auth.OperationViewAlerts, auth.Resource{}, logger) {
+			return
+		}
+		err := json.NewEncoder(w).Encode(engine.Silences())
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// CreateSilenceHandler creates a handler for silencing alerts.
+// @Summary Silence the alerts matching a rule, group and topic for a while.
+// @Description Silenced alerts still fire and resolve, but no notifications are sent for them.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param silenceInput body dto.SilenceInputDTO true "Silence input"
+// @Success 201 {object} alert.Silence "The created silence"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /alerts/silences [post]
+func CreateSilenceHandler(
+	engine *alert.Engine,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(w, r, authorizer, auth.OperationManageAlerts, auth.Resource{}, logger) {
+			return
+		}
+		var req dto.SilenceInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		endsAt, err := getSilenceEnd(&req)
+		if err != nil {
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+		silence, err := engine.AddSilence(alert.Silence{
+			Rule:    req.Rule,
+			Group:   req.Group,
+			Topic:   req.Topic,
+			Comment: req.Comment,
+			EndsAt:  endsAt,
+		})
+		if errors.Is(err, alert.ErrInvalidSilence) {
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+		if err != nil {
+			logger.Error("Error encountered when adding silence", zap.Error(err))
+			HttpError(w, "Couldn't add silence.", http.StatusInternalServerError, logger)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(silence)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+		}
+	}
+}
+
+func getSilenceEnd(req *dto.SilenceInputDTO) (time.Time, error) {
+	switch {
+	case req.EndsAt != nil && req.DurationSeconds != 0:
+		return time.Time{}, errors.New("only one of endsAt and durationSeconds can be given")
+	case req.EndsAt != nil:
+		return *req.EndsAt, nil
+	case req.DurationSeconds > 0:
+		return time.Now().Add(time.Duration(req.DurationSeconds) * time.Second), nil
+	default:
+		return time.Time{}, errors.New("either endsAt or a positive durationSeconds is required")
+	}
+}
+
+// DeleteSilenceHandler creates a handler for ending an alert silence.
+// @Summary End a silence, so that notifications are sent for the alerts it matched again.
+// @Tags alerts
+// @Param id path string true "The ID of the silence"
+// @Success 204 "The silence ended"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 404 {object} ErrorMessage "Silence not found"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /alerts/silences/{id} [delete]
+func DeleteSilenceHandler(
+	engine *alert.Engine,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(w, r, authorizer, auth.OperationManageAlerts, auth.Resource{}, logger) {
+			return
+		}
+		ok, err := engine.RemoveSilence(mux.Vars(r)["id"])
+		if err != nil {
+			logger.Error("Error encountered when removing silence", zap.Error(err))
+			HttpError(w, "Couldn't remove silence.", http.StatusInternalServerError, logger)
+			return
+		}
+		if !ok {
+			HttpError(w, "Silence not found.", http.StatusNotFound, logger)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/audit"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 10_000
+)
+
+// AuditHandler creates a handler for querying the recent entries of the audit log.
+// @Summary Query the recent entries of the audit log, newest first.
+// @Description Only the newest entries are kept in memory to be queried. The audit file holds every entry.
+// @Tags audit
+// @Produce json
+// @Param user query string false "Who made the requests"
+// @Param operation query string false "Part of the method and route of the requests, such as /topics/messages"
+// @Param topic query string false "A topic the requests acted on"
+// @Param outcome query string false "The outcome of the requests" Enums(succeeded, denied, rejected, failed)
+// @Param since query string false "The earliest time of the requests in RFC 3339"
+// @Param until query string false "The latest time of the requests in RFC 3339"
+// @Param limit query int false "The most entries returned, 100 by default"
+// @Success 200 {array} audit.Entry "The matching entries"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Router /audit [get]
+func AuditHandler(
+	auditLog *audit.Log,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(w, r, authorizer, auth.OperationViewAudit, auth.Resource{}, logger) {
+			return
+		}
+		query := r.URL.Query()
+		filter := audit.Filter{
+			User:      query.Get("user"),
+			Operation: query.Get("operation"),
+			Topic:     query.Get("topic"),
+			Outcome:   audit.Outcome(query.Get("outcome")),
+			Limit:     defaultAuditPageSize,
+		}
+		if filter.Outcome != "" && !isValidAuditOutcome(filter.Outcome) {
+			HttpError(w, "unsupported outcome: "+string(filter.Outcome), http.StatusBadRequest, logger)
+			return
+		}
+		if value := query.Get("since"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				HttpError(w, "Invalid since time, expected RFC 3339.", http.StatusBadRequest, logger)
+				return
+			}
+			filter.Since = parsed
+		}
+		if value := query.Get("until"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				HttpError(w, "Invalid until time, expected RFC 3339.", http.StatusBadRequest, logger)
+				return
+			}
+			filter.Until = parsed
+		}
+		if value := query.Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > maxAuditPageSize {
+				message := fmt.Sprintf("Limit must be between 1 and %d.", maxAuditPageSize)
+				HttpError(w, message, http.StatusBadRequest, logger)
+				return
+			}
+			filter.Limit = parsed
+		}
+
+		err := json.NewEncoder(w).Encode(auditLog.Query(filter))
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+func isValidAuditOutcome(outcome audit.Outcome) bool {
+	switch outcome {
+	case audit.OutcomeSucceeded, audit.OutcomeDenied, audit.OutcomeRejected, audit.OutcomeFailed:
+		return true
+	default:
+		return false
+	}
+}
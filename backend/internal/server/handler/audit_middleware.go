@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/audit"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// The largest JSON request body that is recorded among the parameters of an audit entry
+const maxAuditBodyBytes = 64 * 1024
+
+// AuditMiddleware records every request that is routed to a handler in auditLog, once it has been served. It must
+// run before AuthMiddleware, so that the requests AuthMiddleware refuses are recorded too. AuthMiddleware tells it who
+// made the request.
+func AuditMiddleware(auditLog *audit.Log, logger *zap.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if auditLog == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entry := audit.Entry{Time: time.Now(), Operation: r.Method + " " + r.URL.Path}
+			if template, err := mux.CurrentRoute(r).GetPathTemplate(); err == nil {
+				entry.Operation = r.Method + " " + template
+			}
+			parameters := getAuditParameters(r, logger)
+			entry.Parameters = audit.Redact(parameters).(map[string]interface{})
+			r = r.WithContext(audit.Track(r.Context()))
+
+			recorder := &statusRecorder{ResponseWriter: w}
+			defer func() {
+				entry.Status = recorder.status
+				if entry.Status == 0 {
+					entry.Status = http.StatusOK
+				}
+				entry.Outcome = audit.GetOutcome(entry.Status)
+				if identity := audit.TrackedIdentity(r.Context()); identity != nil {
+					entry.User = identity.Name
+					entry.AuthMethod = identity.Method
+				}
+				recovered := recover()
+				if recovered != nil {
+					// Aborted handlers, such as streamed exports that failed midway, may have answered with 200
+					entry.Outcome = audit.OutcomeFailed
+				}
+				entry.Resources = audit.MergeResources(
+					audit.FindResources(parameters),
+					audit.TrackedResources(r.Context())...,
+				)
+				auditLog.Record(entry)
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// getAuditParameters collects the path and query parameters of r, and its body when it is small enough JSON. The
+// body is restored for the handler to read.
+func getAuditParameters(r *http.Request, logger *zap.Logger) map[string]interface{} {
+	parameters := make(map[string]interface{})
+	for name, values := range r.URL.Query() {
+		if len(values) == 1 {
+			parameters[name] = values[0]
+		} else {
+			parameters[name] = values
+		}
+	}
+	for name, value := range mux.Vars(r) {
+		parameters[name] = value
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Body == nil || mediaType != "application/json" {
+		return parameters
+	}
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodyBytes+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), r.Body), r.Body}
+	if err != nil {
+		logger.Debug("Failed to read request body for the audit log", zap.Error(err))
+		return parameters
+	}
+	if len(prefix) > maxAuditBodyBytes {
+		parameters["body"] = "[TOO LARGE]"
+		return parameters
+	}
+	var body interface{}
+	if err := json.Unmarshal(prefix, &body); err == nil {
+		parameters["body"] = body
+	}
+	return parameters
+}
+
+// statusRecorder remembers the status code a handler answered with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(data)
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the features of the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Avi18971911/kafka-window/backend/internal/audit"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"slices"
+)
+
+// AuthMiddleware creates a middleware that only lets requests through that one of authenticators accepts, answering
+// the others with 401. Every request is let through when there are no authenticators.
+func AuthMiddleware(authenticators []auth.Authenticator, logger *zap.Logger) mux.MiddlewareFunc {
+	var challenges []string
+	for _, authenticator := range authenticators {
+		if challenge := authenticator.Challenge(); !slices.Contains(challenges, challenge) {
+			challenges = append(challenges, challenge)
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		if len(authenticators) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Credentials another authenticator rejects, such as a JWT that isn't a static token, may still hold
+			rejected := false
+			for _, authenticator := range authenticators {
+				identity, err := authenticator.Authenticate(r)
+				if err == nil {
+					audit.SetIdentity(r.Context(), identity)
+					next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+					return
+				}
+				if errors.Is(err, auth.ErrNoCredentials) {
+					continue
+				}
+				rejected = true
+				if errors.Is(err, auth.ErrInvalidCredentials) {
+					logger.Debug("Rejected credentials", zap.String("path", r.URL.Path), zap.Error(err))
+				} else {
+					logger.Error("Error encountered when checking credentials", zap.Error(err))
+				}
+			}
+			for _, challenge := range challenges {
+				w.Header().Add("WWW-Authenticate", challenge)
+			}
+			message := "Authentication required."
+			if rejected {
+				message = "Invalid credentials."
+			}
+			HttpError(w, message, http.StatusUnauthorized, logger)
+		})
+	}
+}
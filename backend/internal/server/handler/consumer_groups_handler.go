@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/lag"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// The range of lag history returned when none is given
+const defaultLagHistoryRange = time.Hour
+
+// ConsumerGroupLagHandler creates a handler for getting the lag history of a consumer group on a topic.
+// @Summary Get the lag history of a consumer group on a topic.
+// @Tags consumer-groups
+// @Produce json
+// @Param group path string true "The ID of the consumer group"
+// @Param topic query string true "The topic the group consumes"
+// @Param from query string false "The start of the range in RFC 3339, an hour before to by default"
+// @Param to query string false "The end of the range in RFC 3339, now by default"
+// @Description The lag is polled in the background and kept at coarser resolutions as it ages, so long ranges
+// @Description return fewer points. Rates and the time to catch up are computed between consecutive points.
+// @Success 200 {object} lag.History "The lag history, oldest first"
+// @Failure 400 {object} ErrorMessage "Invalid request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /consumer-groups/{group}/lag [get]
+func ConsumerGroupLagHandler(
+	collector *lag.Collector,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		group := mux.Vars(r)["group"]
+		query := r.URL.Query()
+		topic := query.Get("topic")
+		if topic == "" {
+			HttpError(w, "A topic is required.", http.StatusBadRequest, logger)
+			return
+		}
+		resource := auth.Resource{Topic: topic, Group: group}
+		if !authorize(w, r, authorizer, auth.OperationViewGroups, resource, logger) {
+			return
+		}
+		to := time.Now()
+		if value := query.Get("to"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				HttpError(w, "Invalid to time, expected RFC 3339.", http.StatusBadRequest, logger)
+				return
+			}
+			to = parsed
+		}
+		from := to.Add(-defaultLagHistoryRange)
+		if value := query.Get("from"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				HttpError(w, "Invalid from time, expected RFC 3339.", http.StatusBadRequest, logger)
+				return
+			}
+			from = parsed
+		}
+		if from.After(to) {
+			HttpError(w, "From must not be after to.", http.StatusBadRequest, logger)
+			return
+		}
+
+		history, err := collector.GetHistory(group, topic, from, to)
+		if err != nil {
+			logger.Error("Error encountered when getting lag history", zap.Error(err))
+			HttpError(w, "Couldn't get lag history.", http.StatusInternalServerError, logger)
+			return
+		}
+		err = json.NewEncoder(w).Encode(history)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
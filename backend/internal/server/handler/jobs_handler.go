@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"slices"
+)
+
+// JobsHandler creates a handler for listing background jobs.
+// @Summary List background jobs, newest first.
+// @Description Callers only see the jobs they submitted, unless they are allowed to admin-jobs.
+// @Tags jobs
+// @Produce json
+// @Param kind query string false "Job kind" Enums(copy, diff, export, import, key-distribution, table-scan)
+// @Param status query string false "Only return jobs in this state" Enums(queued, running, succeeded, failed, canceled)
+// @Success 200 {array} job.Job "List of jobs"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Router /jobs [get]
+func JobsHandler(
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := job.Status(r.URL.Query().Get("status"))
+		if status != "" && !isValidJobStatus(status) {
+			HttpError(w, "unsupported job status: "+string(status), http.StatusBadRequest, logger)
+			return
+		}
+		jobs := slices.DeleteFunc(jobManager.List(r.URL.Query().Get("kind"), status), func(listedJob job.Job) bool {
+			return !canAccessJob(r, authorizer, listedJob)
+		})
+		err := json.NewEncoder(w).Encode(jobs)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// JobHandler creates a handler for getting the status of a background job.
+// @Summary Get the status and progress of a background job.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "The ID of the job"
+// @Success 200 {object} job.Job "The job"
+// @Failure 404 {object} ErrorMessage "Job not found"
+// @Router /jobs/{id} [get]
+func JobHandler(
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		foundJob, ok := jobManager.Get(mux.Vars(r)["id"])
+		if !ok || !canAccessJob(r, authorizer, foundJob) {
+			HttpError(w, "Job not found.", http.StatusNotFound, logger)
+			return
+		}
+		writeJob(w, foundJob, logger)
+	}
+}
+
+// CancelJobHandler creates a handler for canceling a background job.
+// @Summary Cancel a background job.
+// @Description Queued jobs are canceled right away. Running jobs are reported as canceled once they have stopped.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "The ID of the job"
+// @Success 200 {object} job.Job "The job"
+// @Failure 404 {object} ErrorMessage "Job not found"
+// @Router /jobs/{id} [delete]
+func CancelJobHandler(
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		if foundJob, ok := jobManager.Get(id); !ok || !canAccessJob(r, authorizer, foundJob) {
+			HttpError(w, "Job not found.", http.StatusNotFound, logger)
+			return
+		}
+		canceledJob, ok := jobManager.Cancel(id)
+		if !ok {
+			HttpError(w, "Job not found.", http.StatusNotFound, logger)
+			return
+		}
+		writeJob(w, canceledJob, logger)
+	}
+}
+
+// JobFileHandler creates a handler for downloading the file a background job produced.
+// @Summary Download the file a background job produced, such as an export.
+// @Tags jobs
+// @Produce application/octet-stream
+// @Param id path string true "The ID of the job"
+// @Success 200 {file} file "The file"
+// @Failure 404 {object} ErrorMessage "Job or file not found"
+// @Failure 409 {object} ErrorMessage "Job has not succeeded"
+// @Router /jobs/{id}/file [get]
+func JobFileHandler(
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fileJob, path, ok := jobManager.File(mux.Vars(r)["id"])
+		if !ok || !canAccessJob(r, authorizer, fileJob) {
+			HttpError(w, "Job file not found.", http.StatusNotFound, logger)
+			return
+		}
+		if fileJob.Status != job.StatusSucceeded {
+			HttpError(w, "Job has not succeeded, its file is incomplete.", http.StatusConflict, logger)
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			logger.Error("Error encountered when opening job file", zap.String("id", fileJob.ID), zap.Error(err))
+			HttpError(w, "Job file not found.", http.StatusNotFound, logger)
+			return
+		}
+		defer file.Close()
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileJob.FileName))
+		http.ServeContent(w, r, fileJob.FileName, *fileJob.FinishedAt, file)
+	}
+}
+
+func isValidJobStatus(status job.Status) bool {
+	switch status {
+	case job.StatusQueued, job.StatusRunning, job.StatusSucceeded, job.StatusFailed, job.StatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+func writeJob(w http.ResponseWriter, jobToWrite job.Job, logger *zap.Logger) {
+	err := json.NewEncoder(w).Encode(jobToWrite)
+	if err != nil {
+		logger.Error("Error encountered when encoding response", zap.Error(err))
+		HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+	}
+}
+
+func writeAcceptedJob(w http.ResponseWriter, acceptedJob job.Job, logger *zap.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+acceptedJob.ID)
+	w.WriteHeader(http.StatusAccepted)
+	err := json.NewEncoder(w).Encode(acceptedJob)
+	if err != nil {
+		logger.Error("Error encountered when encoding response", zap.Error(err))
+	}
+}
+
+// submitJob submits run on behalf of the caller of r, who owns the job. The job runs with the identity of the caller,
+// so that it is restricted to what the caller is allowed to do.
+func submitJob(r *http.Request, jobManager *job.Manager, kind string, description string, run job.Func) job.Job {
+	identity := auth.IdentityFrom(r.Context())
+	owner := ""
+	if identity != nil {
+		owner = identity.Name
+	}
+	return jobManager.SubmitAs(owner, kind, description, func(ctx context.Context, jobRun *job.Run) error {
+		return run(auth.WithIdentity(ctx, identity), jobRun)
+	})
+}
+
+// canAccessJob tells whether the caller of r may see and cancel a job. Jobs of others are treated as missing, unless
+// the caller is allowed to admin-jobs.
+func canAccessJob(r *http.Request, authorizer *auth.Authorizer, accessedJob job.Job) bool {
+	identity := auth.IdentityFrom(r.Context())
+	if identity == nil || accessedJob.Owner == identity.Name {
+		return true
+	}
+	return authorizer.Allows(r.Context(), auth.OperationAdminJobs, auth.Resource{})
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/audit"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type PermissionCheck struct {
+	Operation auth.Operation `json:"operation" validate:"required"`
+	Resource  auth.Resource  `json:"resource" validate:"required"`
+	Allowed   bool           `json:"allowed" validate:"required"`
+}
+
+// PermissionsHandler creates a handler for getting what the caller is allowed to do.
+// @Summary Get the roles of the caller and the permissions they grant.
+// @Description Callers that aren't restricted, since access control or authentication is disabled, may do anything.
+// @Tags permissions
+// @Produce json
+// @Success 200 {object} auth.Grants "The roles and permissions of the caller"
+// @Router /permissions [get]
+func PermissionsHandler(
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(authorizer.Grants(r.Context()))
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// PermissionCheckHandler creates a handler for checking whether the caller is allowed to perform an operation.
+// @Summary Check whether the caller is allowed to perform an operation on a resource.
+// @Tags permissions
+// @Produce json
+// @Param operation query string true "The operation, such as view-messages or produce"
+// @Param cluster query string false "The cluster of the resource, the cluster that is browsed by default"
+// @Param topic query string false "The topic of the resource"
+// @Param group query string false "The consumer group of the resource"
+// @Success 200 {object} PermissionCheck "Whether the operation is allowed"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Router /permissions/check [get]
+func PermissionCheckHandler(
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		operation := auth.Operation(query.Get("operation"))
+		if !auth.IsOperation(operation) {
+			HttpError(w, "unsupported operation: "+string(operation), http.StatusBadRequest, logger)
+			return
+		}
+		resource := auth.Resource{Cluster: query.Get("cluster"), Topic: query.Get("topic"), Group: query.Get("group")}
+		check := PermissionCheck{
+			Operation: operation,
+			Resource:  resource,
+			Allowed:   authorizer.Allows(r.Context(), operation, resource),
+		}
+		err := json.NewEncoder(w).Encode(check)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// authorize answers with 403 and returns false when the caller may not perform operation on resource. The resource
+// is noted in the audit entry of the request either way.
+func authorize(
+	w http.ResponseWriter,
+	r *http.Request,
+	authorizer *auth.Authorizer,
+	operation auth.Operation,
+	resource auth.Resource,
+	logger *zap.Logger,
+) bool {
+	audit.AddResource(r.Context(), resource)
+	err := authorizer.Authorize(r.Context(), operation, resource)
+	return err == nil || !httpForbidden(w, err, logger)
+}
+
+// httpForbidden answers with 403 and returns true when err tells that the caller wasn't allowed to do something.
+func httpForbidden(w http.ResponseWriter, err error, logger *zap.Logger) bool {
+	var forbidden *auth.ForbiddenError
+	if !errors.As(err, &forbidden) {
+		return false
+	}
+	message := fmt.Sprintf("Not allowed to %s on %s.", forbidden.Operation, forbidden.Resource)
+	HttpError(w, message, http.StatusForbidden, logger)
+	return true
+}
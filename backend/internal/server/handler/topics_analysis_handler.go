@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/dto"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+const (
+	keyDistributionJobKind           = "key-distribution"
+	defaultKeyDistributionSampleSize = 100_000
+	maxKeyDistributionSampleSize     = 10_000_000
+	defaultKeyDistributionTopK       = 20
+	maxKeyDistributionTopK           = 1000
+	defaultKeyDistributionImbalance  = 1.5
+)
+
+// KeyDistributionHandler creates a handler for starting a job that analyzes how the keys of a topic are distributed.
+// @Summary Analyze how the messages and keys of a topic are spread over its partitions.
+// @Description The analysis samples the newest messages of every partition in proportion to the offsets it holds.
+// @Description It runs as a background job, whose result is a model.KeyDistribution.
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param keyDistributionInput body dto.KeyDistributionInputDTO true "Key distribution input"
+// @Success 202 {object} job.Job "The started analysis job"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Router /topics/analysis/keys [post]
+func KeyDistributionHandler(
+	kafkaService *kafka.KafkaService,
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.KeyDistributionInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		options, err := mapKeyDistributionInputDtoToModel(&req)
+		if err != nil {
+			logger.Error("Validation failed for key distribution request", zap.Error(err))
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+
+		resource := auth.Resource{Topic: req.TopicName}
+		if !authorize(w, r, authorizer, auth.OperationViewMessages, resource, logger) {
+			return
+		}
+
+		description := fmt.Sprintf("Analyze the key distribution of %s", req.TopicName)
+		analyze := func(ctx context.Context, run *job.Run) error {
+			distribution, err := kafkaService.AnalyzeKeyDistribution(ctx, req.TopicName, options, func(sampled int64) {
+				run.Report(map[string]int64{"sampled": sampled})
+			})
+			if err != nil {
+				return err
+			}
+			if distribution == nil {
+				return fmt.Errorf("topic %s not found", req.TopicName)
+			}
+			run.SetResult(distribution)
+			return nil
+		}
+		analysisJob := submitJob(r, jobManager, keyDistributionJobKind, description, analyze)
+		writeAcceptedJob(w, analysisJob, logger)
+	}
+}
+
+func mapKeyDistributionInputDtoToModel(req *dto.KeyDistributionInputDTO) (model.KeyDistributionOptions, error) {
+	if req.TopicName == "" {
+		return model.KeyDistributionOptions{}, errors.New("topic name is required, but was not provided")
+	}
+	options := model.KeyDistributionOptions{
+		SampleSize:         req.SampleSize,
+		TopK:               req.TopK,
+		ImbalanceThreshold: req.ImbalanceThreshold,
+	}
+	if options.SampleSize == 0 {
+		options.SampleSize = defaultKeyDistributionSampleSize
+	}
+	if options.TopK == 0 {
+		options.TopK = defaultKeyDistributionTopK
+	}
+	if options.ImbalanceThreshold == 0 {
+		options.ImbalanceThreshold = defaultKeyDistributionImbalance
+	}
+	if options.SampleSize < 0 || options.SampleSize > maxKeyDistributionSampleSize {
+		return model.KeyDistributionOptions{}, fmt.Errorf(
+			"sample size must be between 1 and %d",
+			maxKeyDistributionSampleSize,
+		)
+	}
+	if options.TopK < 0 || options.TopK > maxKeyDistributionTopK {
+		return model.KeyDistributionOptions{}, fmt.Errorf("topK must be between 1 and %d", maxKeyDistributionTopK)
+	}
+	if options.ImbalanceThreshold <= 1 {
+		return model.KeyDistributionOptions{}, errors.New("imbalance threshold must be greater than 1")
+	}
+	return options, nil
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/dto"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+const copyJobKind = "copy"
+
+// TopicMessagesCopyHandler creates a handler for starting a job that copies messages between topics.
+// @Summary Copy messages to another topic, possibly on another cluster.
+// @Description The copy runs as a background job, whose progress is reported at /jobs/{id}.
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param topicMessagesCopyInput body dto.TopicMessagesCopyInputDTO true "Topic messages copy input"
+// @Success 202 {object} job.Job "The started copy job"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /topics/messages/copy [post]
+func TopicMessagesCopyHandler(
+	kafkaService *kafka.KafkaService,
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.TopicMessagesCopyInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		if err := validateCopyRequest(&req); err != nil {
+			logger.Error("Validation failed for copy request", zap.Error(err))
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+
+		source, destination, options := mapCopyInputDtoToModel(&req)
+		sourceResource := auth.Resource{Topic: source.Topic}
+		if !authorize(w, r, authorizer, auth.OperationViewMessages, sourceResource, logger) {
+			return
+		}
+		destinationResource := auth.Resource{Cluster: destination.Cluster, Topic: destination.Topic}
+		if !authorize(w, r, authorizer, auth.OperationProduce, destinationResource, logger) {
+			return
+		}
+
+		description := fmt.Sprintf("Copy %s to %s", source.Topic, destination.Topic)
+		if destination.Cluster != "" {
+			description += " on " + destination.Cluster
+		}
+		copyRecords := func(ctx context.Context, run *job.Run) error {
+			progress, err := kafkaService.CopyMessages(ctx, source, destination, options, func(progress model.CopyProgress) {
+				run.Report(copyProgressCounters(progress))
+			})
+			if progress != nil {
+				run.Report(copyProgressCounters(*progress))
+				run.SetResult(progress)
+			}
+			return err
+		}
+		copyJob := submitJob(r, jobManager, copyJobKind, description, copyRecords)
+		logger.Info(
+			"Started copy job",
+			zap.String("id", copyJob.ID),
+			zap.String("source", source.Topic),
+			zap.String("destination", destination.Topic),
+			zap.String("cluster", destination.Cluster),
+		)
+		writeAcceptedJob(w, copyJob, logger)
+	}
+}
+
+func copyProgressCounters(progress model.CopyProgress) map[string]int64 {
+	return map[string]int64{
+		"copied":        progress.Copied,
+		"untransformed": progress.Untransformed,
+	}
+}
+
+func validateCopyRequest(req *dto.TopicMessagesCopyInputDTO) error {
+	if req.Source.TopicName == "" {
+		return errors.New("source topic name is required, but was not provided")
+	}
+	if req.Destination.TopicName == "" {
+		return errors.New("destination topic name is required, but was not provided")
+	}
+	if req.Destination.Cluster == "" && req.Destination.TopicName == req.Source.TopicName {
+		return errors.New("destination topic must differ from the source topic")
+	}
+	if len(req.Source.Partitions) > 0 {
+		err := validateRequest(&dto.TopicMessagesInputDTO{
+			TopicName:  req.Source.TopicName,
+			Partitions: req.Source.Partitions,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if req.Source.StartTime != nil && req.Source.EndTime != nil && req.Source.StartTime.After(*req.Source.EndTime) {
+		return errors.New("start time must be before or equal to end time")
+	}
+	if err := validateMessageFilter(req.Source.Filter); err != nil {
+		return err
+	}
+	if _, err := jsonvalue.NewTransform(mapTransformStepDtosToModel(req.ValueTransform)); err != nil {
+		return fmt.Errorf("invalid value transform: %w", err)
+	}
+	return nil
+}
+
+func mapCopyInputDtoToModel(
+	req *dto.TopicMessagesCopyInputDTO,
+) (model.CopySource, model.CopyDestination, model.CopyOptions) {
+	source := model.CopySource{
+		Topic:     req.Source.TopicName,
+		StartTime: req.Source.StartTime,
+		EndTime:   req.Source.EndTime,
+		Filter:    mapMessageFilterDtoToModel(req.Source.Filter),
+	}
+	if len(req.Source.Partitions) > 0 {
+		source.Partitions = mapTopicPartitionInputDtoToModel(req.Source.Partitions)
+	}
+	destination := model.CopyDestination{
+		Cluster: req.Destination.Cluster,
+		Topic:   req.Destination.TopicName,
+	}
+	options := model.CopyOptions{
+		KeepKey:        req.KeepKey == nil || *req.KeepKey,
+		KeepHeaders:    req.KeepHeaders == nil || *req.KeepHeaders,
+		KeepPartition:  req.KeepPartition,
+		ValueTransform: mapTransformStepDtosToModel(req.ValueTransform),
+	}
+	return source, destination, options
+}
+
+func mapTransformStepDtosToModel(steps []dto.TransformStepDTO) []model.TransformStep {
+	mapped := make([]model.TransformStep, len(steps))
+	for i, step := range steps {
+		mapped[i] = model.TransformStep{
+			Op:    model.TransformOp(step.Op),
+			Path:  step.Path,
+			Value: string(step.Value),
+		}
+	}
+	return mapped
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/dto"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+const (
+	diffJobKind            = "diff"
+	defaultDiffMaxReported = 100
+	maxDiffMaxReported     = 10_000
+)
+
+// TopicDiffHandler creates a handler for starting a job that compares the records of two topics or offset ranges.
+// @Summary Compare the records of a target with those of a source, such as a topic and its migrated copy.
+// @Description Records are matched by key and by a hash of their value, where JSON values are compared
+// @Description structurally without the ignored paths. The comparison runs as a background job, whose result is a
+// @Description model.TopicDiff listing the missing, extra and differing records.
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param topicDiffInput body dto.TopicDiffInputDTO true "Topic diff input"
+// @Success 202 {object} job.Job "The started comparison job"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Router /topics/diff [post]
+func TopicDiffHandler(
+	kafkaService *kafka.KafkaService,
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.TopicDiffInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		if err := validateDiffRequest(&req); err != nil {
+			logger.Error("Validation failed for diff request", zap.Error(err))
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+
+		source, target, options := mapDiffInputDtoToModel(&req)
+		for _, side := range []model.DiffSource{source, target} {
+			resource := auth.Resource{Cluster: side.Cluster, Topic: side.Topic}
+			if !authorize(w, r, authorizer, auth.OperationViewMessages, resource, logger) {
+				return
+			}
+		}
+
+		description := fmt.Sprintf("Compare %s with %s", describeDiffSource(target), describeDiffSource(source))
+		compare := func(ctx context.Context, run *job.Run) error {
+			diff, err := kafkaService.DiffTopics(ctx, source, target, options, func(read int64) {
+				run.Report(map[string]int64{"read": read})
+			})
+			if err != nil {
+				return err
+			}
+			run.SetResult(diff)
+			return nil
+		}
+		diffJob := submitJob(r, jobManager, diffJobKind, description, compare)
+		writeAcceptedJob(w, diffJob, logger)
+	}
+}
+
+func validateDiffRequest(req *dto.TopicDiffInputDTO) error {
+	for _, side := range []struct {
+		name   string
+		source dto.DiffSourceDTO
+	}{
+		{name: "source", source: req.Source},
+		{name: "target", source: req.Target},
+	} {
+		if side.source.TopicName == "" {
+			return fmt.Errorf("%s topic name is required, but was not provided", side.name)
+		}
+		if len(side.source.Partitions) > 0 {
+			err := validateRequest(&dto.TopicMessagesInputDTO{
+				TopicName:  side.source.TopicName,
+				Partitions: side.source.Partitions,
+			})
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", side.name, err)
+			}
+		}
+	}
+	if req.MaxReported < 0 || req.MaxReported > maxDiffMaxReported {
+		return fmt.Errorf("max reported must be between 1 and %d", maxDiffMaxReported)
+	}
+	if _, err := jsonvalue.NewDiffer(req.IgnorePaths); err != nil {
+		return fmt.Errorf("invalid ignored path: %w", err)
+	}
+	return nil
+}
+
+func mapDiffInputDtoToModel(req *dto.TopicDiffInputDTO) (model.DiffSource, model.DiffSource, model.DiffOptions) {
+	mapSource := func(source dto.DiffSourceDTO) model.DiffSource {
+		diffSource := model.DiffSource{Cluster: source.Cluster, Topic: source.TopicName}
+		if len(source.Partitions) > 0 {
+			diffSource.Partitions = mapTopicPartitionInputDtoToModel(source.Partitions)
+		}
+		return diffSource
+	}
+	options := model.DiffOptions{IgnorePaths: req.IgnorePaths, MaxReported: req.MaxReported}
+	if options.MaxReported == 0 {
+		options.MaxReported = defaultDiffMaxReported
+	}
+	return mapSource(req.Source), mapSource(req.Target), options
+}
+
+func describeDiffSource(source model.DiffSource) string {
+	if source.Cluster == "" {
+		return source.Topic
+	}
+	return source.Topic + " on " + source.Cluster
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/export"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/dto"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	exportJobKind    = "export"
+	exportBufferSize = 64 * 1024
+	// exportFlushInterval is the number of records after which the export is flushed to the client
+	exportFlushInterval = 1000
+)
+
+// TopicMessagesExportHandler creates a handler for exporting messages from a topic as a file download.
+// @Summary Export messages from a topic as JSONL, CSV or an Avro container file.
+// @Description Messages are streamed to the client as they are consumed, so exports are not bounded by memory.
+// @Tags topics
+// @Accept json
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/avro
+// @Param topicMessagesExportInput body dto.TopicMessagesExportInputDTO true "Topic messages export input"
+// @Success 200 {file} file "The exported messages"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /topics/messages/export [post]
+func TopicMessagesExportHandler(
+	kafkaService *kafka.KafkaService,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.TopicMessagesExportInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		if err := validateExportRequest(&req); err != nil {
+			logger.Error("Validation failed for export request", zap.Error(err))
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+
+		format := export.Format(req.Format)
+		response := &countingWriter{w: w}
+		buffered := bufio.NewWriterSize(response, exportBufferSize)
+		fileName := exportFileName(req.TopicName, format, req.Gzip)
+		w.Header().Set("Content-Type", exportContentType(format, req.Gzip))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+		_, err = exportMessages(r.Context(), kafkaService, &req, buffered, func(int64) error {
+			if err := buffered.Flush(); err != nil {
+				return err
+			}
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = buffered.Flush()
+		}
+
+		if err != nil && response.written == 0 && errors.Is(err, auth.ErrForbidden) {
+			w.Header().Del("Content-Disposition")
+			httpForbidden(w, err, logger)
+			return
+		}
+		if err != nil {
+			logger.Error(
+				"Error encountered when exporting messages",
+				zap.String("topic", req.TopicName),
+				zap.Error(err),
+			)
+			if response.written == 0 {
+				w.Header().Del("Content-Disposition")
+				HttpError(w, "Couldn't export messages.", http.StatusInternalServerError, logger)
+				return
+			}
+			// Part of the file was already sent, so abort the connection rather than ending a truncated download
+			// as if it had succeeded.
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// TopicMessagesExportJobHandler creates a handler for starting a job that exports messages from a topic to a file.
+// @Summary Start a job exporting messages from a topic as JSONL, CSV or an Avro container file.
+// @Description The file can be downloaded from /jobs/{id}/file once the job has succeeded.
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param topicMessagesExportInput body dto.TopicMessagesExportInputDTO true "Topic messages export input"
+// @Success 202 {object} job.Job "The started export job"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Router /topics/messages/export/jobs [post]
+func TopicMessagesExportJobHandler(
+	kafkaService *kafka.KafkaService,
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.TopicMessagesExportInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		if err := validateExportRequest(&req); err != nil {
+			logger.Error("Validation failed for export request", zap.Error(err))
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+
+		resource := auth.Resource{Topic: req.TopicName}
+		if !authorize(w, r, authorizer, auth.OperationViewMessages, resource, logger) {
+			return
+		}
+
+		format := export.Format(req.Format)
+		description := fmt.Sprintf("Export %s as %s", req.TopicName, format)
+		writeExport := func(ctx context.Context, run *job.Run) error {
+			file, err := run.CreateFile(exportFileName(req.TopicName, format, req.Gzip))
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			buffered := bufio.NewWriterSize(file, exportBufferSize)
+			exported, err := exportMessages(ctx, kafkaService, &req, buffered, func(exported int64) error {
+				run.Report(map[string]int64{"exported": exported})
+				return nil
+			})
+			run.Report(map[string]int64{"exported": exported})
+			if err != nil {
+				return err
+			}
+			if err := buffered.Flush(); err != nil {
+				return fmt.Errorf("failed to write export file: %w", err)
+			}
+			return file.Close()
+		}
+		exportJob := submitJob(r, jobManager, exportJobKind, description, writeExport)
+		writeAcceptedJob(w, exportJob, logger)
+	}
+}
+
+// exportMessages writes the requested messages to out, compressing them if requested. onFlush is called
+// periodically once the output so far has been handed to out.
+func exportMessages(
+	ctx context.Context,
+	kafkaService *kafka.KafkaService,
+	req *dto.TopicMessagesExportInputDTO,
+	out io.Writer,
+	onFlush func(exported int64) error,
+) (int64, error) {
+	var gzipWriter *gzip.Writer
+	if req.Gzip {
+		gzipWriter = gzip.NewWriter(out)
+		out = gzipWriter
+	}
+	exportWriter, err := export.NewWriter(export.Format(req.Format), out, export.Options{
+		Columns:         mapExportColumnDtosToModel(req.Columns),
+		IncludeRawBytes: req.IncludeRawBytes,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var exported int64
+	err = kafkaService.StreamMessagesForTopic(
+		ctx,
+		req.TopicName,
+		mapTopicPartitionInputDtoToModel(req.Partitions),
+		mapMessageFilterDtoToModel(req.Filter),
+		func(record *model.Record) error {
+			if err := exportWriter.Write(record); err != nil {
+				return fmt.Errorf("failed to write record: %w", err)
+			}
+			exported++
+			if exported%exportFlushInterval != 0 {
+				return nil
+			}
+			if gzipWriter != nil {
+				if err := gzipWriter.Flush(); err != nil {
+					return err
+				}
+			}
+			return onFlush(exported)
+		},
+	)
+	if closeErr := exportWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if gzipWriter != nil {
+		if closeErr := gzipWriter.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return exported, err
+}
+
+// countingWriter records whether anything has been sent to the client yet, since errors can only be reported
+// as an ErrorMessage until then.
+type countingWriter struct {
+	w       io.Writer
+	written int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.written += int64(n)
+	return n, err
+}
+
+func validateExportRequest(req *dto.TopicMessagesExportInputDTO) error {
+	err := validateRequest(&dto.TopicMessagesInputDTO{TopicName: req.TopicName, Partitions: req.Partitions})
+	if err != nil {
+		return err
+	}
+	switch export.Format(req.Format) {
+	case export.FormatJSONL, export.FormatAvro:
+		if len(req.Columns) > 0 {
+			return errors.New("columns are only supported for csv exports")
+		}
+	case export.FormatCSV:
+		for _, column := range req.Columns {
+			if column.Field == "" {
+				return errors.New("every column requires a field")
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported export format: %s", req.Format)
+	}
+	return validateMessageFilter(req.Filter)
+}
+
+func validateMessageFilter(filter *dto.MessageFilterDTO) error {
+	if filter == nil {
+		return nil
+	}
+	if filter.ValuePath != "" {
+		if _, err := jsonvalue.ParsePath(filter.ValuePath); err != nil {
+			return fmt.Errorf("invalid filter value path: %w", err)
+		}
+	}
+	if filter.StartTime != nil && filter.EndTime != nil && filter.StartTime.After(*filter.EndTime) {
+		return errors.New("filter start time must be before or equal to end time")
+	}
+	return nil
+}
+
+func exportFileName(topic string, format export.Format, gzipped bool) string {
+	fileName := fmt.Sprintf("%s-%s%s", topic, time.Now().UTC().Format("20060102T150405Z"), export.FileExtension(format))
+	if gzipped {
+		fileName += ".gz"
+	}
+	return fileName
+}
+
+func exportContentType(format export.Format, gzipped bool) string {
+	if gzipped {
+		return "application/gzip"
+	}
+	return export.ContentType(format)
+}
+
+func mapMessageFilterDtoToModel(filter *dto.MessageFilterDTO) *model.MessageFilter {
+	if filter == nil {
+		return nil
+	}
+	return &model.MessageFilter{
+		KeyContains:   filter.KeyContains,
+		ValueContains: filter.ValueContains,
+		StartTime:     filter.StartTime,
+		EndTime:       filter.EndTime,
+		ValuePath:     filter.ValuePath,
+		ValueEquals:   filter.ValueEquals,
+	}
+}
+
+func mapExportColumnDtosToModel(columns []dto.ExportColumnDTO) []export.Column {
+	mapped := make([]export.Column, len(columns))
+	for i, column := range columns {
+		mapped[i] = export.Column{Header: column.Header, Field: column.Field}
+	}
+	return mapped
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
@@ -17,22 +16,31 @@ import (
 // @Tags topics
 // @Accept json
 // @Produce json
-// @Param - body string false "No parameters required"
+// @Param kind query string false "Only return topics of this kind" Enums(standard, connect-internal, streams-changelog, streams-repartition)
 // @Success 200 {array} model.TopicDetails "List of topic names"
+// @Failure 400 {object} ErrorMessage "Bad request"
 // @Failure 500 {object} ErrorMessage "Internal server error"
 // @Router /topics [get]
 func AllTopicsHandler(
-	ctx context.Context,
 	kafkaService *kafka.KafkaService,
 	logger *zap.Logger,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		topics, err := kafkaService.GetTopics()
+		kind := model.TopicKind(r.URL.Query().Get("kind"))
+		if kind != "" && !isValidTopicKind(kind) {
+			HttpError(w, "unsupported topic kind: "+string(kind), http.StatusBadRequest, logger)
+			return
+		}
+
+		topics, err := kafkaService.GetTopics(r.Context())
 		if err != nil {
 			logger.Error("Error encountered when getting all topics", zap.Error(err))
 			HttpError(w, "Couldn't query for topics.", http.StatusInternalServerError, logger)
 			return
 		}
+		if kind != "" {
+			topics = filterTopicsByKind(topics, kind)
+		}
 		err = json.NewEncoder(w).Encode(topics)
 		if err != nil {
 			logger.Error("Error encountered when encoding response", zap.Error(err))
@@ -47,12 +55,15 @@ func AllTopicsHandler(
 // @Accept json
 // @Produce json
 // @Param topicMessagesInput body dto.TopicMessagesInputDTO true "Topic messages input"
-// @Success 200 {array} model.Message "List of messages"
+// @Description Every partition reports whether its whole range was read, which it is unless reading it failed.
+// @Description Messages produced in transactions carry the state of their transaction.
+// @Description By default messages are listed partition by partition, and can instead be merged by timestamp.
+// @Success 200 {object} model.TopicMessages "The messages, with the part of each partition's range that was read"
 // @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
 // @Failure 500 {object} ErrorMessage "Internal server error"
 // @Router /topics/messages [post]
 func TopicMessagesHandler(
-	ctx context.Context,
 	kafkaService *kafka.KafkaService,
 	logger *zap.Logger,
 ) http.HandlerFunc {
@@ -79,12 +90,93 @@ func TopicMessagesHandler(
 
 		partitionModel := mapTopicPartitionInputDtoToModel(req.Partitions)
 
-		messages, err := kafkaService.GetLastMessagesForTopic(ctx, req.TopicName, partitionModel)
+		fetchOptions := model.FetchOptions{
+			IsolationLevel:        model.IsolationLevel(req.IsolationLevel),
+			IncludeControlRecords: req.IncludeControlRecords,
+			Order:                 model.MessageOrder(req.Order),
+		}
+		messages, err := kafkaService.GetMessagesForTopic(r.Context(), req.TopicName, partitionModel, fetchOptions)
 		if err != nil {
+			if httpForbidden(w, err, logger) {
+				return
+			}
 			logger.Error("Error encountered when getting messages", zap.Error(err))
 			HttpError(w, "Couldn't get messages.", http.StatusInternalServerError, logger)
 			return
 		}
+		if messages == nil {
+			messages = &model.TopicMessages{
+				Messages:   make([]*model.Message, 0),
+				Partitions: make([]model.PartitionCoverage, 0),
+			}
+		}
+		err = json.NewEncoder(w).Encode(messages)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+			return
+		}
+	}
+}
+
+// LatestTopicMessagesHandler creates a handler for getting the newest messages across a whole topic.
+// @Summary Get the newest messages across a topic.
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param latestTopicMessagesInput body dto.LatestTopicMessagesInputDTO true "Latest topic messages input"
+// @Description Returns the newest messages of the topic by timestamp, so that partitions that were written to
+// @Description recently contribute more messages than idle ones.
+// @Success 200 {object} model.TopicMessages "The messages, with the part of each partition's range that was read"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /topics/messages/latest [post]
+func LatestTopicMessagesHandler(
+	kafkaService *kafka.KafkaService,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.LatestTopicMessagesInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		if err := validateLatestRequest(&req); err != nil {
+			logger.Error("Validation failed for request", zap.Error(err))
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+
+		fetchOptions := model.FetchOptions{
+			IsolationLevel:        model.IsolationLevel(req.IsolationLevel),
+			IncludeControlRecords: req.IncludeControlRecords,
+			Order:                 model.MessageOrder(req.Order),
+		}
+		messages, err := kafkaService.GetLatestMessagesForTopic(r.Context(), req.TopicName, req.Count, fetchOptions)
+		if err != nil {
+			if httpForbidden(w, err, logger) {
+				return
+			}
+			logger.Error("Error encountered when getting latest messages", zap.Error(err))
+			HttpError(w, "Couldn't get messages.", http.StatusInternalServerError, logger)
+			return
+		}
+		if messages == nil {
+			messages = &model.TopicMessages{
+				Messages:   make([]*model.Message, 0),
+				Partitions: make([]model.PartitionCoverage, 0),
+			}
+		}
 		err = json.NewEncoder(w).Encode(messages)
 		if err != nil {
 			logger.Error("Error encountered when encoding response", zap.Error(err))
@@ -94,10 +186,35 @@ func TopicMessagesHandler(
 	}
 }
 
+func isValidTopicKind(kind model.TopicKind) bool {
+	switch kind {
+	case model.TopicKindStandard,
+		model.TopicKindConnectInternal,
+		model.TopicKindStreamsChangelog,
+		model.TopicKindStreamsRepartition:
+		return true
+	default:
+		return false
+	}
+}
+
+func filterTopicsByKind(topics []model.TopicDetails, kind model.TopicKind) []model.TopicDetails {
+	filtered := make([]model.TopicDetails, 0, len(topics))
+	for _, topic := range topics {
+		if topic.Kind == kind {
+			filtered = append(filtered, topic)
+		}
+	}
+	return filtered
+}
+
 func validateRequest(req *dto.TopicMessagesInputDTO) error {
 	if req.TopicName == "" {
 		return errors.New("topic name is required, but was not provided")
 	}
+	if err := validateFetchOptions(req.IsolationLevel, req.Order); err != nil {
+		return err
+	}
 	if len(req.Partitions) == 0 {
 		return errors.New("at least one partition is required, but none were provided")
 	}
@@ -112,6 +229,30 @@ func validateRequest(req *dto.TopicMessagesInputDTO) error {
 	return nil
 }
 
+func validateLatestRequest(req *dto.LatestTopicMessagesInputDTO) error {
+	if req.TopicName == "" {
+		return errors.New("topic name is required, but was not provided")
+	}
+	if req.Count <= 0 {
+		return errors.New("count must be a positive integer")
+	}
+	return validateFetchOptions(req.IsolationLevel, req.Order)
+}
+
+func validateFetchOptions(isolationLevel string, order string) error {
+	switch model.IsolationLevel(isolationLevel) {
+	case "", model.ReadUncommitted, model.ReadCommitted:
+	default:
+		return errors.New("unsupported isolation level: " + isolationLevel)
+	}
+	switch model.MessageOrder(order) {
+	case "", model.OrderPartitionOffset, model.OrderTimestamp, model.OrderNewestFirst:
+	default:
+		return errors.New("unsupported order: " + order)
+	}
+	return nil
+}
+
 func mapTopicPartitionInputDtoToModel(
 	input []dto.TopicPartitionInputDTO,
 ) model.PartitionInput {
@@ -15,8 +15,12 @@ func mapEncodingStringToEnum(encoding string) (decoder.Encoding, error) {
 		return decoder.Base64, nil
 	case "avro":
 		return decoder.Avro, nil
-	case "consumerOffset":
-		return decoder.ConsumerOffset, nil
+	case "messagepack":
+		return decoder.MessagePack, nil
+	case "cbor":
+		return decoder.CBOR, nil
+	case "bson":
+		return decoder.BSON, nil
 	default:
 		return "", fmt.Errorf("unsupported encoding: %s", encoding)
 	}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/export"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const importJobKind = "import"
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// TopicMessagesImportHandler creates a handler for starting a job that produces a JSONL export into a topic.
+// @Summary Import messages from a JSONL export into a topic.
+// @Description The body is a JSONL export, optionally gzip compressed. Exports with raw bytes are reproduced exactly.
+// @Description The import runs as a background job, whose result carries the importId to resume it with should it fail.
+// @Tags topics
+// @Accept application/x-ndjson
+// @Produce json
+// @Param topicName query string true "The topic to produce the messages to"
+// @Param partitioning query string false "How records are assigned to partitions, by key by default" Enums(keep, key)
+// @Param timestamps query string false "How record timestamps are set, kept by default" Enums(keep, now, shift)
+// @Param messagesPerSecond query int false "The maximum number of messages produced per second"
+// @Param importId query string false "The ID of an earlier import to resume"
+// @Param messages body string true "The JSONL export"
+// @Success 202 {object} job.Job "The started import job, whose result is a model.ImportResult"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /topics/messages/import [post]
+func TopicMessagesImportHandler(
+	kafkaService *kafka.KafkaService,
+	jobManager *job.Manager,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		topic, options, err := parseImportQuery(r)
+		if err != nil {
+			HttpError(w, err.Error(), http.StatusBadRequest, logger)
+			return
+		}
+		if !authorize(w, r, authorizer, auth.OperationProduce, auth.Resource{Topic: topic}, logger) {
+			return
+		}
+
+		// The job outlives the request, so the upload is kept until the job is done with it
+		spool, err := os.CreateTemp("", "kafka-window-import-*.jsonl")
+		if err != nil {
+			logger.Error("Error encountered when spooling import", zap.Error(err))
+			HttpError(w, "Couldn't store the uploaded messages.", http.StatusInternalServerError, logger)
+			return
+		}
+		if _, err := io.Copy(spool, r.Body); err != nil {
+			spool.Close()
+			os.Remove(spool.Name())
+			logger.Error("Error encountered when spooling import", zap.Error(err))
+			HttpError(w, "Couldn't read the uploaded messages.", http.StatusBadRequest, logger)
+			return
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			spool.Close()
+			os.Remove(spool.Name())
+			HttpError(w, "Couldn't store the uploaded messages.", http.StatusInternalServerError, logger)
+			return
+		}
+
+		description := fmt.Sprintf("Import into %s", topic)
+		importRecords := func(ctx context.Context, run *job.Run) error {
+			defer os.Remove(spool.Name())
+			defer spool.Close()
+			body, err := decompressImportBody(spool)
+			if err != nil {
+				return fmt.Errorf("invalid gzip payload: %w", err)
+			}
+			result, err := kafkaService.ImportMessages(
+				ctx,
+				topic,
+				export.NewJSONLReader(body),
+				options,
+				func(progress model.ImportResult) {
+					run.Report(importProgressCounters(progress))
+				},
+			)
+			if result != nil {
+				run.Report(importProgressCounters(*result))
+				run.SetResult(result)
+			}
+			return err
+		}
+		importJob := submitJob(r, jobManager, importJobKind, description, importRecords)
+		writeAcceptedJob(w, importJob, logger)
+	}
+}
+
+func importProgressCounters(progress model.ImportResult) map[string]int64 {
+	return map[string]int64{
+		"read":     progress.Read,
+		"produced": progress.Produced,
+		"skipped":  progress.Skipped,
+	}
+}
+
+func parseImportQuery(r *http.Request) (string, model.ImportOptions, error) {
+	query := r.URL.Query()
+	topic := query.Get("topicName")
+	if topic == "" {
+		return "", model.ImportOptions{}, fmt.Errorf("topic name is required, but was not provided")
+	}
+
+	options := model.ImportOptions{
+		ImportID:     query.Get("importId"),
+		Partitioning: model.ImportPartitioning(query.Get("partitioning")),
+		Timestamps:   model.ImportTimestamps(query.Get("timestamps")),
+	}
+	switch options.Partitioning {
+	case "":
+		options.Partitioning = model.ImportPartitioningKey
+	case model.ImportPartitioningKeep, model.ImportPartitioningKey:
+	default:
+		return "", options, fmt.Errorf("unsupported partitioning: %s", options.Partitioning)
+	}
+	switch options.Timestamps {
+	case "":
+		options.Timestamps = model.ImportTimestampsKeep
+	case model.ImportTimestampsKeep, model.ImportTimestampsNow, model.ImportTimestampsShift:
+	default:
+		return "", options, fmt.Errorf("unsupported timestamps: %s", options.Timestamps)
+	}
+	if rate := query.Get("messagesPerSecond"); rate != "" {
+		perSecond, err := strconv.Atoi(rate)
+		if err != nil || perSecond < 0 {
+			return "", options, fmt.Errorf("messagesPerSecond must be a non-negative integer")
+		}
+		options.MessagesPerSecond = perSecond
+	}
+	return topic, options, nil
+}
+
+// decompressImportBody accepts both plain and gzip compressed exports, regardless of Content-Encoding.
+func decompressImportBody(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+	prefix, err := buffered.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(prefix, gzipMagic) {
+		return buffered, nil
+	}
+	return gzip.NewReader(buffered)
+}
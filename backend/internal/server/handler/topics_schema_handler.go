@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSchemaSampleSize = 500
+	maxSchemaSampleSize     = 10_000
+	// The distinct example values kept for every field
+	schemaExamples = 3
+)
+
+// TopicSchemaHandler creates a handler for inferring the schema of the JSON messages of a topic.
+// @Summary Infer the schema of the JSON keys or values of the newest messages of a topic.
+// @Description The report lists every field path with the types observed, how often it was present and null, and
+// @Description examples. With a format, the schema is returned as a JSON Schema or as a draft of an Avro schema.
+// @Tags topics
+// @Produce json
+// @Param topic query string true "The topic to sample"
+// @Param sampleSize query int false "The number of newest messages sampled, 500 by default"
+// @Param part query string false "Whether the keys or the values are inferred, the values by default" Enums(key, value)
+// @Param format query string false "Return the schema in this format instead of the report" Enums(json-schema, avro)
+// @Success 200 {object} model.InferredSchema "The inferred schema, or the schema in the requested format"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 404 {object} ErrorMessage "Topic not found"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /topics/schema [get]
+func TopicSchemaHandler(
+	kafkaService *kafka.KafkaService,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		topic := query.Get("topic")
+		if topic == "" {
+			HttpError(w, "A topic is required.", http.StatusBadRequest, logger)
+			return
+		}
+		sampleSize := defaultSchemaSampleSize
+		if value := query.Get("sampleSize"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > maxSchemaSampleSize {
+				message := fmt.Sprintf("Sample size must be between 1 and %d.", maxSchemaSampleSize)
+				HttpError(w, message, http.StatusBadRequest, logger)
+				return
+			}
+			sampleSize = parsed
+		}
+		part := query.Get("part")
+		if part == "" {
+			part = "value"
+		}
+		if part != "key" && part != "value" {
+			HttpError(w, "unsupported part: "+part, http.StatusBadRequest, logger)
+			return
+		}
+		format := query.Get("format")
+		if format != "" && format != "json-schema" && format != "avro" {
+			HttpError(w, "unsupported format: "+format, http.StatusBadRequest, logger)
+			return
+		}
+
+		topicMessages, err := kafkaService.GetLatestMessagesForTopic(
+			r.Context(),
+			topic,
+			sampleSize,
+			model.FetchOptions{Order: model.OrderTimestamp},
+		)
+		if err != nil {
+			if httpForbidden(w, err, logger) {
+				return
+			}
+			logger.Error("Error encountered when sampling messages", zap.Error(err))
+			HttpError(w, "Couldn't sample messages.", http.StatusInternalServerError, logger)
+			return
+		}
+		if topicMessages == nil {
+			HttpError(w, "Topic not found.", http.StatusNotFound, logger)
+			return
+		}
+
+		inferrer := jsonvalue.NewSchemaInferrer(schemaExamples)
+		inferred := model.InferredSchema{Topic: topic, Part: part, SampledMessages: int64(len(topicMessages.Messages))}
+		for _, message := range topicMessages.Messages {
+			payload := message.ValueJsonPayload
+			if part == "key" {
+				payload = message.KeyJsonPayload
+			}
+			if payload != nil {
+				inferrer.Add(*payload)
+				inferred.JSONMessages++
+			}
+		}
+		inferred.Fields = inferrer.Fields()
+
+		var response any = inferred
+		switch format {
+		case "json-schema":
+			response = inferrer.JSONSchema(topic + "-" + part)
+		case "avro":
+			response = inferrer.AvroSchema(topic+"-"+part, avroNamespace(topic))
+		}
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// avroNamespace derives a namespace from the topic, such as orders.v1 from orders.v1.created. Topic names that
+// aren't dotted get none.
+func avroNamespace(topic string) string {
+	dot := strings.LastIndexByte(topic, '.')
+	if dot <= 0 {
+		return ""
+	}
+	parts := strings.Split(topic[:dot], ".")
+	for _, part := range parts {
+		if part == "" || !isAvroIdentifier(part) {
+			return ""
+		}
+	}
+	return topic[:dot]
+}
+
+func isAvroIdentifier(name string) bool {
+	for i, r := range name {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultSizeSampleSize = 1000
+	maxSizeSampleSize     = 100_000
+	defaultSizeThreshold  = 0.8
+)
+
+// TopicMessageSizesHandler creates a handler for measuring the records of topics against their size limits.
+// @Summary Get the sizes of the keys, values and headers of the newest messages of topics.
+// @Description Sizes are those of the uncompressed records, as p50, p95, p99 and max. They are compared with the
+// @Description max.message.bytes of the topic, or the message.max.bytes of the brokers when the topic doesn't
+// @Description override it, and topics whose largest sampled record reaches the threshold share of it are at risk.
+// @Tags topics
+// @Produce json
+// @Param topic query string false "Only analyze this topic instead of every topic but the internal ones"
+// @Param sampleSize query int false "The most messages sampled from every topic, 1000 by default"
+// @Param threshold query number false "The share of the limit a topic is at risk from, 0.8 by default"
+// @Success 200 {array} model.TopicMessageSizes "The sizes of every topic, highest utilization first"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 404 {object} ErrorMessage "Topic not found"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /topics/sizes [get]
+func TopicMessageSizesHandler(
+	kafkaService *kafka.KafkaService,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		options := model.MessageSizeOptions{SampleSize: defaultSizeSampleSize, Threshold: defaultSizeThreshold}
+		if value := query.Get("sampleSize"); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed <= 0 || parsed > maxSizeSampleSize {
+				message := fmt.Sprintf("Sample size must be between 1 and %d.", maxSizeSampleSize)
+				HttpError(w, message, http.StatusBadRequest, logger)
+				return
+			}
+			options.SampleSize = parsed
+		}
+		if value := query.Get("threshold"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed <= 0 || parsed > 1 {
+				HttpError(w, "Threshold must be above 0 and at most 1.", http.StatusBadRequest, logger)
+				return
+			}
+			options.Threshold = parsed
+		}
+		var topics []string
+		topic := query.Get("topic")
+		if topic != "" {
+			topics = []string{topic}
+		}
+
+		sizes, err := kafkaService.AnalyzeMessageSizes(r.Context(), topics, options)
+		if err != nil {
+			logger.Error("Error encountered when analyzing message sizes", zap.Error(err))
+			HttpError(w, "Couldn't analyze message sizes.", http.StatusInternalServerError, logger)
+			return
+		}
+		if topic != "" && len(sizes) == 0 {
+			HttpError(w, "Topic not found.", http.StatusNotFound, logger)
+			return
+		}
+		err = json.NewEncoder(w).Encode(sizes)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/stats"
+	"go.uber.org/zap"
+	"net/http"
+	"slices"
+)
+
+// TopicStatsHandler creates a handler for getting the throughput, size and retention figures of topics.
+// @Summary Get throughput, size and retention statistics of topics.
+// @Tags topics
+// @Produce json
+// @Param topic query string false "Only return the statistics of this topic"
+// @Description Throughput is measured from high watermarks and partition sizes that are sampled in the background,
+// @Description so it only covers the time since the server started.
+// @Success 200 {array} stats.TopicStats "The statistics of every topic, ordered by name"
+// @Failure 404 {object} ErrorMessage "Topic not found"
+// @Failure 500 {object} ErrorMessage "Internal server error"
+// @Router /topics/stats [get]
+func TopicStatsHandler(
+	sampler *stats.Sampler,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topic := r.URL.Query().Get("topic")
+		topicStats, err := sampler.GetTopicStats(r.Context(), topic)
+		if err != nil {
+			logger.Error("Error encountered when getting topic stats", zap.Error(err))
+			HttpError(w, "Couldn't get topic stats.", http.StatusInternalServerError, logger)
+			return
+		}
+		// Topics the caller may not view are left out as if they didn't exist
+		topicStats = slices.DeleteFunc(topicStats, func(stat stats.TopicStats) bool {
+			return !authorizer.Allows(r.Context(), auth.OperationViewTopics, auth.Resource{Topic: stat.Topic})
+		})
+		if topic != "" && len(topicStats) == 0 {
+			HttpError(w, "Topic not found.", http.StatusNotFound, logger)
+			return
+		}
+		err = json.NewEncoder(w).Encode(topicStats)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
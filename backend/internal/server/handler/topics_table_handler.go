@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/dto"
+	"github.com/Avi18971911/kafka-window/backend/internal/table"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+const (
+	tableScanJobKind     = "table-scan"
+	defaultTablePageSize = 100
+	maxTablePageSize     = 10_000
+)
+
+// TableScanHandler creates a handler for starting a job that scans a compacted topic as a table.
+// @Summary Scan a compacted topic for the newest message of every key.
+// @Description The scan runs as a background job, whose result is a model.TableSummary that includes how many
+// @Description superseded messages compaction hasn't removed yet. Once it succeeds, the rows can be paged through
+// @Description and looked up by key at /topics/tables/{id}, where id is the ID of the job. Only the latest scans
+// @Description are kept.
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param tableScanInput body dto.TableScanInputDTO true "Table scan input"
+// @Success 202 {object} job.Job "The started scan job"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Router /topics/tables [post]
+func TableScanHandler(
+	kafkaService *kafka.KafkaService,
+	jobManager *job.Manager,
+	tableStore *table.Store,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.TableScanInputDTO
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			HttpError(w, "Invalid request payload", http.StatusBadRequest, logger)
+			return
+		}
+
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			if err != nil {
+				logger.Error("Failed to close request body", zap.Error(err))
+			}
+		}(r.Body)
+
+		if req.TopicName == "" {
+			HttpError(w, "topic name is required, but was not provided", http.StatusBadRequest, logger)
+			return
+		}
+		if !authorize(w, r, authorizer, auth.OperationViewMessages, auth.Resource{Topic: req.TopicName}, logger) {
+			return
+		}
+
+		description := fmt.Sprintf("Scan %s as a table", req.TopicName)
+		scan := func(ctx context.Context, run *job.Run) error {
+			topicTable, err := kafkaService.ScanTable(ctx, req.TopicName, func(scanned int64) {
+				run.Report(map[string]int64{"scanned": scanned})
+			})
+			if err != nil {
+				return err
+			}
+			if topicTable == nil {
+				return fmt.Errorf("topic %s not found", req.TopicName)
+			}
+			if err := tableStore.Add(run.ID(), topicTable); err != nil {
+				return err
+			}
+			run.SetResult(topicTable.Summary)
+			return nil
+		}
+		scanJob := submitJob(r, jobManager, tableScanJobKind, description, scan)
+		writeAcceptedJob(w, scanJob, logger)
+	}
+}
+
+// TableHandler creates a handler for paging through the rows of a scanned table.
+// @Summary Get the newest message of every key of a scanned compacted topic.
+// @Tags topics
+// @Produce json
+// @Param id path string true "The ID of the scan job"
+// @Param offset query int false "The number of rows skipped, 0 by default"
+// @Param limit query int false "The most rows returned, 100 by default"
+// @Param tombstones query bool false "Whether keys whose newest message is a tombstone are included"
+// @Success 200 {object} model.TablePage "The rows, ordered by key"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 404 {object} ErrorMessage "Table not found"
+// @Router /topics/tables/{id} [get]
+func TableHandler(
+	tableStore *table.Store,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topicTable := tableStore.Get(mux.Vars(r)["id"])
+		if topicTable == nil {
+			HttpError(w, "Table not found. Scan the topic again.", http.StatusNotFound, logger)
+			return
+		}
+		resource := auth.Resource{Topic: topicTable.Summary.Topic}
+		if !authorize(w, r, authorizer, auth.OperationViewMessages, resource, logger) {
+			return
+		}
+		query := r.URL.Query()
+		offset, limit := 0, defaultTablePageSize
+		if value := query.Get("offset"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				HttpError(w, "Offset must not be negative.", http.StatusBadRequest, logger)
+				return
+			}
+			offset = parsed
+		}
+		if value := query.Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > maxTablePageSize {
+				message := fmt.Sprintf("Limit must be between 1 and %d.", maxTablePageSize)
+				HttpError(w, message, http.StatusBadRequest, logger)
+				return
+			}
+			limit = parsed
+		}
+		tombstones, ok := parseBoolQuery(w, r, "tombstones", logger)
+		if !ok {
+			return
+		}
+
+		rows, total := topicTable.Rows(offset, limit, tombstones)
+		err := json.NewEncoder(w).Encode(model.TablePage{
+			Summary: topicTable.Summary,
+			Total:   total,
+			Offset:  offset,
+			Rows:    rows,
+		})
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// TableKeyHandler creates a handler for looking up the current value of a key in a scanned table.
+// @Summary Get the newest message of a key of a scanned compacted topic.
+// @Tags topics
+// @Produce json
+// @Param id path string true "The ID of the scan job"
+// @Param key query string true "The key, as text or base64 encoded"
+// @Param base64 query bool false "Whether the key is base64 encoded, as keys that aren't valid UTF-8 are listed"
+// @Param tombstones query bool false "Whether a key whose newest message is a tombstone is returned"
+// @Success 200 {object} model.TableRow "The row of the key"
+// @Failure 400 {object} ErrorMessage "Bad request"
+// @Failure 403 {object} ErrorMessage "Not allowed"
+// @Failure 404 {object} ErrorMessage "Table or key not found"
+// @Router /topics/tables/{id}/keys [get]
+func TableKeyHandler(
+	tableStore *table.Store,
+	authorizer *auth.Authorizer,
+	logger *zap.Logger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topicTable := tableStore.Get(mux.Vars(r)["id"])
+		if topicTable == nil {
+			HttpError(w, "Table not found. Scan the topic again.", http.StatusNotFound, logger)
+			return
+		}
+		resource := auth.Resource{Topic: topicTable.Summary.Topic}
+		if !authorize(w, r, authorizer, auth.OperationViewMessages, resource, logger) {
+			return
+		}
+		query := r.URL.Query()
+		if !query.Has("key") {
+			HttpError(w, "A key is required.", http.StatusBadRequest, logger)
+			return
+		}
+		isBase64, ok := parseBoolQuery(w, r, "base64", logger)
+		if !ok {
+			return
+		}
+		key := []byte(query.Get("key"))
+		if isBase64 {
+			decoded, err := base64.StdEncoding.DecodeString(query.Get("key"))
+			if err != nil {
+				HttpError(w, "The key isn't valid base64.", http.StatusBadRequest, logger)
+				return
+			}
+			key = decoded
+		}
+		tombstones, ok := parseBoolQuery(w, r, "tombstones", logger)
+		if !ok {
+			return
+		}
+
+		row, ok := topicTable.Lookup(key, tombstones)
+		if !ok {
+			HttpError(w, "Key not found.", http.StatusNotFound, logger)
+			return
+		}
+		err := json.NewEncoder(w).Encode(row)
+		if err != nil {
+			logger.Error("Error encountered when encoding response", zap.Error(err))
+			HttpError(w, "Couldn't encode response.", http.StatusInternalServerError, logger)
+		}
+	}
+}
+
+// parseBoolQuery reads an optional boolean query parameter, answering with a bad request when it isn't one.
+func parseBoolQuery(w http.ResponseWriter, r *http.Request, name string, logger *zap.Logger) (bool, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, true
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		HttpError(w, name+" must be true or false.", http.StatusBadRequest, logger)
+		return false, false
+	}
+	return parsed, true
+}
@@ -1,24 +1,53 @@
 package router
 
 import (
-	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/alert"
+	"github.com/Avi18971911/kafka-window/backend/internal/audit"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/lag"
 	"github.com/Avi18971911/kafka-window/backend/internal/server/handler"
+	"github.com/Avi18971911/kafka-window/backend/internal/stats"
+	"github.com/Avi18971911/kafka-window/backend/internal/table"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"net/http"
 )
 
 func CreateRouter(
-	ctx context.Context,
 	kafkaService *kafka.KafkaService,
+	jobManager *job.Manager,
+	sampler *stats.Sampler,
+	lagCollector *lag.Collector,
+	alertEngine *alert.Engine,
+	tableStore *table.Store,
+	authenticators []auth.Authenticator,
+	authorizer *auth.Authorizer,
+	auditLog *audit.Log,
 	logger *zap.Logger,
 ) http.Handler {
 	r := mux.NewRouter()
+	r.Use(handler.AuditMiddleware(auditLog, logger))
+	r.Use(handler.AuthMiddleware(authenticators, logger))
 
 	r.Handle(
 		"/topics", handler.AllTopicsHandler(
-			ctx,
+			kafkaService,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/topics/stats", handler.TopicStatsHandler(
+			sampler,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/topics/sizes", handler.TopicMessageSizesHandler(
 			kafkaService,
 			logger,
 		),
@@ -26,11 +55,196 @@ func CreateRouter(
 
 	r.Handle(
 		"/topics/messages", handler.TopicMessagesHandler(
-			ctx,
 			kafkaService,
 			logger,
 		),
 	).Methods("POST")
 
+	r.Handle(
+		"/topics/messages/latest", handler.LatestTopicMessagesHandler(
+			kafkaService,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/messages/export", handler.TopicMessagesExportHandler(
+			kafkaService,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/messages/export/jobs", handler.TopicMessagesExportJobHandler(
+			kafkaService,
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/messages/import", handler.TopicMessagesImportHandler(
+			kafkaService,
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/messages/copy", handler.TopicMessagesCopyHandler(
+			kafkaService,
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/schema", handler.TopicSchemaHandler(
+			kafkaService,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/topics/analysis/keys", handler.KeyDistributionHandler(
+			kafkaService,
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/diff", handler.TopicDiffHandler(
+			kafkaService,
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/tables", handler.TableScanHandler(
+			kafkaService,
+			jobManager,
+			tableStore,
+			authorizer,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/topics/tables/{id}", handler.TableHandler(
+			tableStore,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/topics/tables/{id}/keys", handler.TableKeyHandler(
+			tableStore,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/consumer-groups/{group}/lag", handler.ConsumerGroupLagHandler(
+			lagCollector,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/alerts", handler.AlertsHandler(
+			alertEngine,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/alerts/silences", handler.SilencesHandler(
+			alertEngine,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/alerts/silences", handler.CreateSilenceHandler(
+			alertEngine,
+			authorizer,
+			logger,
+		),
+	).Methods("POST")
+
+	r.Handle(
+		"/alerts/silences/{id}", handler.DeleteSilenceHandler(
+			alertEngine,
+			authorizer,
+			logger,
+		),
+	).Methods("DELETE")
+
+	r.Handle(
+		"/jobs", handler.JobsHandler(
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/jobs/{id}", handler.JobHandler(
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/jobs/{id}", handler.CancelJobHandler(
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("DELETE")
+
+	r.Handle(
+		"/jobs/{id}/file", handler.JobFileHandler(
+			jobManager,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/permissions", handler.PermissionsHandler(
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/permissions/check", handler.PermissionCheckHandler(
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
+	r.Handle(
+		"/audit", handler.AuditHandler(
+			auditLog,
+			authorizer,
+			logger,
+		),
+	).Methods("GET")
+
 	return r
 }
@@ -0,0 +1,53 @@
+package sketch
+
+import (
+	"math"
+)
+
+// CountMin estimates how often every key was added in a fixed amount of memory. Estimates are never below the true
+// count, and exceed it by at most epsilon times the number of additions with probability 1 - delta.
+type CountMin struct {
+	width  uint64
+	counts [][]int64
+}
+
+// NewCountMin creates a sketch whose estimates are within epsilon of the total count with probability 1 - delta.
+func NewCountMin(epsilon float64, delta float64) *CountMin {
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	counts := make([][]int64, depth)
+	for i := range counts {
+		counts[i] = make([]int64, width)
+	}
+	return &CountMin{width: width, counts: counts}
+}
+
+// Add counts key once and returns its estimated count.
+func (c *CountMin) Add(key []byte) int64 {
+	estimate := int64(math.MaxInt64)
+	for row, column := range c.columns(key) {
+		c.counts[row][column]++
+		estimate = min(estimate, c.counts[row][column])
+	}
+	return estimate
+}
+
+// Count returns the estimated count of key.
+func (c *CountMin) Count(key []byte) int64 {
+	estimate := int64(math.MaxInt64)
+	for row, column := range c.columns(key) {
+		estimate = min(estimate, c.counts[row][column])
+	}
+	return estimate
+}
+
+// columns derives a column for every row from a single hash, as two independent hashes would.
+func (c *CountMin) columns(key []byte) []uint64 {
+	keyHash := hash(key)
+	first, second := keyHash&math.MaxUint32, keyHash>>32
+	columns := make([]uint64, len(c.counts))
+	for row := range columns {
+		columns[row] = (first + uint64(row)*second) % c.width
+	}
+	return columns
+}
@@ -0,0 +1,12 @@
+package sketch
+
+import (
+	"hash/maphash"
+)
+
+// The seed is fixed for the life of the process, so that sketches of the same process can be merged
+var seed = maphash.MakeSeed()
+
+func hash(key []byte) uint64 {
+	return maphash.Bytes(seed, key)
+}
@@ -0,0 +1,53 @@
+package sketch
+
+import (
+	"math"
+	"math/bits"
+)
+
+// The number of hash bits that pick a register, which gives 2^14 registers and a standard error of about 0.8%
+const hyperLogLogPrecision = 14
+
+// HyperLogLog estimates the number of distinct keys added to it in a fixed amount of memory.
+type HyperLogLog struct {
+	registers []uint8
+}
+
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{registers: make([]uint8, 1<<hyperLogLogPrecision)}
+}
+
+func (h *HyperLogLog) Add(key []byte) {
+	keyHash := hash(key)
+	register := keyHash >> (64 - hyperLogLogPrecision)
+	// The position of the first set bit of the rest of the hash
+	rank := uint8(bits.LeadingZeros64(keyHash<<hyperLogLogPrecision|1<<(hyperLogLogPrecision-1)) + 1)
+	h.registers[register] = max(h.registers[register], rank)
+}
+
+// Merge adds every key added to other.
+func (h *HyperLogLog) Merge(other *HyperLogLog) {
+	for i, rank := range other.registers {
+		h.registers[i] = max(h.registers[i], rank)
+	}
+}
+
+// Count returns the estimated number of distinct keys.
+func (h *HyperLogLog) Count() int64 {
+	registers := float64(len(h.registers))
+	sum := 0.0
+	empty := 0
+	for _, rank := range h.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			empty++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/registers)
+	estimate := alpha * registers * registers / sum
+	// Small counts are estimated more closely from the registers no key reached
+	if estimate <= 2.5*registers && empty > 0 {
+		estimate = registers * math.Log(registers/float64(empty))
+	}
+	return int64(math.Round(estimate))
+}
@@ -0,0 +1,77 @@
+package sketch
+
+import (
+	"cmp"
+	"slices"
+)
+
+// TopK keeps the k keys that a count-min sketch estimates were added most often. Keys are only tracked from the
+// moment they enter the top, so what else is tracked about them may miss their earliest additions.
+type TopK struct {
+	k        int
+	sketch   *CountMin
+	counts   map[string]int64
+	minKey   string
+	minCount int64
+}
+
+func NewTopK(k int, sketch *CountMin) *TopK {
+	return &TopK{k: k, sketch: sketch, counts: make(map[string]int64, k)}
+}
+
+// Add counts key once and tells whether it is among the top keys afterwards.
+func (t *TopK) Add(key []byte) bool {
+	count := t.sketch.Add(key)
+	if _, ok := t.counts[string(key)]; ok {
+		t.counts[string(key)] = count
+		if string(key) == t.minKey {
+			t.updateMin()
+		}
+		return true
+	}
+	if len(t.counts) < t.k {
+		t.counts[string(key)] = count
+		t.updateMin()
+		return true
+	}
+	if count <= t.minCount {
+		return false
+	}
+	delete(t.counts, t.minKey)
+	t.counts[string(key)] = count
+	t.updateMin()
+	return true
+}
+
+func (t *TopK) updateMin() {
+	first := true
+	for key, count := range t.counts {
+		if first || count < t.minCount {
+			t.minKey, t.minCount = key, count
+			first = false
+		}
+	}
+}
+
+type KeyCount struct {
+	Key   string
+	Count int64
+}
+
+// Top returns the top keys, most frequent first.
+func (t *TopK) Top() []KeyCount {
+	top := make([]KeyCount, 0, len(t.counts))
+	for key, count := range t.counts {
+		top = append(top, KeyCount{Key: key, Count: count})
+	}
+	slices.SortFunc(top, func(a, b KeyCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Key, b.Key))
+	})
+	return top
+}
+
+// Contains tells whether key is among the top keys.
+func (t *TopK) Contains(key string) bool {
+	_, ok := t.counts[key]
+	return ok
+}
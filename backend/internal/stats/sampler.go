@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"go.uber.org/zap"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// How often the offsets and sizes of every partition are sampled
+	SampleInterval time.Duration
+	// The windows that throughput is computed over
+	Windows []time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		SampleInterval: 15 * time.Second,
+		Windows:        []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute},
+	}
+}
+
+// Sampler samples the high watermarks and sizes of every partition in the background, so that the throughput of
+// topics can be told without a metrics system.
+type Sampler struct {
+	kafkaService *kafka.KafkaService
+	config       Config
+	// The samples of every partition by topic, oldest first, reaching back just past the longest window
+	samples map[string]map[int32][]sample
+	mutex   sync.RWMutex
+	logger  *zap.Logger
+}
+
+type sample struct {
+	time    time.Time
+	offsets model.PartitionOffsets
+	// -1 when the size couldn't be read
+	size model.PartitionSize
+}
+
+// NewSampler creates a sampler that takes no samples until it is run.
+func NewSampler(kafkaService *kafka.KafkaService, config Config, logger *zap.Logger) *Sampler {
+	if len(config.Windows) == 0 {
+		config.Windows = DefaultConfig().Windows
+	}
+	config.Windows = slices.Clone(config.Windows)
+	slices.Sort(config.Windows)
+	return &Sampler{
+		kafkaService: kafkaService,
+		config:       config,
+		samples:      make(map[string]map[int32][]sample),
+		logger:       logger,
+	}
+}
+
+// Run takes a sample every interval until ctx is done.
+func (s *Sampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SampleInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.sample(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Warn("failed to sample partitions", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sampler) sample(ctx context.Context) error {
+	offsets, err := s.kafkaService.GetPartitionOffsets(ctx)
+	if err != nil {
+		return err
+	}
+	sampledAt := time.Now()
+	sizes, err := s.kafkaService.GetPartitionSizes(ctx)
+	if err != nil {
+		// Throughput in messages can still be told
+		s.logger.Warn("failed to sample partition sizes", zap.Error(err))
+	}
+
+	// Samples older than needed for the longest window are dropped, keeping one that reaches past it
+	cutoff := sampledAt.Add(-slices.Max(s.config.Windows) - s.config.SampleInterval)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// Topics that were deleted are dropped along with their samples
+	samples := make(map[string]map[int32][]sample, len(offsets))
+	for topic, partitionOffsets := range offsets {
+		samples[topic] = make(map[int32][]sample, len(partitionOffsets))
+		for partition, partitionOffset := range partitionOffsets {
+			size, ok := sizes[topic][partition]
+			if !ok {
+				size = model.PartitionSize{Bytes: -1, ReplicatedBytes: -1}
+			}
+			previous := s.samples[topic][partition]
+			for len(previous) > 1 && previous[1].time.Before(cutoff) {
+				previous = previous[1:]
+			}
+			// Copied, since readers may still hold the previous samples
+			partitionSamples := make([]sample, len(previous), len(previous)+1)
+			copy(partitionSamples, previous)
+			samples[topic][partition] = append(partitionSamples, sample{
+				time:    sampledAt,
+				offsets: partitionOffset,
+				size:    size,
+			})
+		}
+	}
+	s.samples = samples
+	return nil
+}
+
+// getSamples returns the samples of every partition, taking a first sample when the sampler hasn't run yet.
+func (s *Sampler) getSamples(ctx context.Context) (map[string]map[int32][]sample, error) {
+	s.mutex.RLock()
+	samples := s.samples
+	s.mutex.RUnlock()
+	if len(samples) > 0 {
+		return samples, nil
+	}
+	if err := s.sample(ctx); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.samples, nil
+}
@@ -0,0 +1,325 @@
+package stats
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"go.uber.org/zap"
+	"maps"
+	"slices"
+	"time"
+)
+
+// TopicStats are the throughput, size and retention figures of a topic, summed over its partitions.
+type TopicStats struct {
+	Topic string `json:"topic" validate:"required"`
+	// The number of offsets between the oldest and the newest of every partition, which compaction and
+	// transaction markers leave without records
+	Offsets int64 `json:"offsets" validate:"required"`
+	// The size of the largest replica of every partition. -1 when the size of a partition couldn't be read
+	SizeBytes int64 `json:"sizeBytes" validate:"required"`
+	// The size of every replica of every partition. -1 when the size of a partition couldn't be read
+	ReplicatedSizeBytes int64 `json:"replicatedSizeBytes" validate:"required"`
+	// The throughput over each window, empty until the sampler has taken two samples
+	Rates []Rate `json:"rates" validate:"required"`
+	// How many times the mean throughput of the partitions the busiest one received over the longest window, where 1
+	// means the partitions are even. Missing when the topic received no messages
+	ThroughputSkew *float64 `json:"throughputSkew,omitempty"`
+	// How many times the mean size of the partitions the largest one is
+	SizeSkew   *float64         `json:"sizeSkew,omitempty"`
+	Retention  RetentionStats   `json:"retention" validate:"required"`
+	Partitions []PartitionStats `json:"partitions" validate:"required"`
+}
+
+// Rate is the throughput over a window, measured from the samples closest to both of its ends.
+type Rate struct {
+	WindowSeconds int64 `json:"windowSeconds" validate:"required"`
+	// The part of the window the samples cover, which is less than the window until the sampler ran long enough
+	CoveredSeconds    float64 `json:"coveredSeconds" validate:"required"`
+	MessagesPerSecond float64 `json:"messagesPerSecond" validate:"required"`
+	// Measured from the growth of the partitions on disk. When retention or compaction shrank a partition within
+	// the window, it is estimated from the messages instead
+	BytesPerSecond float64 `json:"bytesPerSecond" validate:"required"`
+}
+
+// RetentionStats tell how close a topic is to the limits that log cleanup deletes records by.
+type RetentionStats struct {
+	CleanupPolicy model.CleanupPolicy `json:"cleanupPolicy" validate:"required"`
+	// Missing when records are kept indefinitely
+	RetentionMs *int64 `json:"retentionMs,omitempty"`
+	// The limit on each partition. Missing when there is none
+	RetentionBytes *int64 `json:"retentionBytes,omitempty"`
+	// The age of the oldest record of the topic. Only read for topics whose records are deleted by time
+	OldestRecordAgeMs *int64 `json:"oldestRecordAgeMs,omitempty"`
+	// The age of the oldest record as a share of RetentionMs. It goes past 1 since cleanup deletes whole segments,
+	// once their newest record is past retention
+	TimeUtilization *float64 `json:"timeUtilization,omitempty"`
+	// The largest share of RetentionBytes that a partition uses, since the limit applies to each partition
+	SizeUtilization *float64 `json:"sizeUtilization,omitempty"`
+}
+
+type PartitionStats struct {
+	Partition    int32 `json:"partition" validate:"required"`
+	OldestOffset int64 `json:"oldestOffset" validate:"required"`
+	// The high watermark
+	NewestOffset int64 `json:"newestOffset" validate:"required"`
+	// The size of the largest replica. -1 when it couldn't be read
+	SizeBytes int64 `json:"sizeBytes" validate:"required"`
+	// The size of every replica. -1 when it couldn't be read
+	ReplicatedSizeBytes int64  `json:"replicatedSizeBytes" validate:"required"`
+	Rates               []Rate `json:"rates" validate:"required"`
+	// The timestamp of the oldest record. Only read for topics whose records are deleted by time
+	OldestTimestamp *time.Time `json:"oldestTimestamp,omitempty"`
+}
+
+// GetTopicStats returns the stats of every topic ordered by name, or of only topic when it isn't empty. It returns
+// nil when topic doesn't exist.
+func (s *Sampler) GetTopicStats(ctx context.Context, topic string) ([]TopicStats, error) {
+	samples, err := s.getSamples(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample partitions: %w", err)
+	}
+	if topic != "" {
+		topicSamples, ok := samples[topic]
+		if !ok {
+			return nil, nil
+		}
+		samples = map[string]map[int32][]sample{topic: topicSamples}
+	}
+	retention, err := s.kafkaService.GetTopicRetention(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention: %w", err)
+	}
+
+	topicStats := make([]TopicStats, 0, len(samples))
+	for _, name := range slices.Sorted(maps.Keys(samples)) {
+		stats := s.getTopicStats(name, samples[name], retention[name])
+		if err := s.addOldestRecords(ctx, &stats); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.logger.Warn(
+				"failed to read the oldest records of topic",
+				zap.String("topic", name),
+				zap.Error(err),
+			)
+		}
+		topicStats = append(topicStats, stats)
+	}
+	return topicStats, nil
+}
+
+func (s *Sampler) getTopicStats(
+	topic string,
+	partitionSamples map[int32][]sample,
+	retention model.TopicRetention,
+) TopicStats {
+	stats := TopicStats{
+		Topic:      topic,
+		Rates:      make([]Rate, 0),
+		Partitions: make([]PartitionStats, 0, len(partitionSamples)),
+		Retention: RetentionStats{
+			CleanupPolicy:  retention.CleanupPolicy,
+			RetentionMs:    retention.RetentionMs,
+			RetentionBytes: retention.RetentionBytes,
+		},
+	}
+	for _, partition := range slices.Sorted(maps.Keys(partitionSamples)) {
+		samples := partitionSamples[partition]
+		latest := samples[len(samples)-1]
+		stats.Partitions = append(stats.Partitions, PartitionStats{
+			Partition:           partition,
+			OldestOffset:        latest.offsets.Oldest,
+			NewestOffset:        latest.offsets.Newest,
+			SizeBytes:           latest.size.Bytes,
+			ReplicatedSizeBytes: latest.size.ReplicatedBytes,
+			Rates:               s.getRates(samples),
+		})
+		stats.Offsets += latest.offsets.Newest - latest.offsets.Oldest
+		if stats.SizeBytes >= 0 && latest.size.Bytes >= 0 {
+			stats.SizeBytes += latest.size.Bytes
+			stats.ReplicatedSizeBytes += latest.size.ReplicatedBytes
+		} else {
+			stats.SizeBytes = -1
+			stats.ReplicatedSizeBytes = -1
+		}
+	}
+	stats.Rates = sumRates(stats.Partitions)
+	stats.ThroughputSkew = getThroughputSkew(stats.Partitions)
+	stats.SizeSkew = getSizeSkew(stats.Partitions)
+	if retention.RetentionBytes != nil && *retention.RetentionBytes > 0 && deletesRecords(retention.CleanupPolicy) {
+		utilization := 0.0
+		for _, partition := range stats.Partitions {
+			utilization = max(utilization, float64(partition.SizeBytes)/float64(*retention.RetentionBytes))
+		}
+		stats.Retention.SizeUtilization = &utilization
+	}
+	return stats
+}
+
+// addOldestRecords reads the timestamps of the oldest records of topics whose records are deleted by time. Other
+// topics are skipped, since their oldest records take a fetch for every partition to read and tell nothing about
+// when they will be deleted.
+func (s *Sampler) addOldestRecords(ctx context.Context, stats *TopicStats) error {
+	retentionMs := stats.Retention.RetentionMs
+	if retentionMs == nil || *retentionMs <= 0 || !deletesRecords(stats.Retention.CleanupPolicy) {
+		return nil
+	}
+	oldestOffsets := make(map[int32]int64, len(stats.Partitions))
+	for _, partition := range stats.Partitions {
+		if partition.NewestOffset > partition.OldestOffset {
+			oldestOffsets[partition.Partition] = partition.OldestOffset
+		}
+	}
+	if len(oldestOffsets) == 0 {
+		return nil
+	}
+	timestamps, err := s.kafkaService.GetFirstTimestamps(ctx, stats.Topic, oldestOffsets)
+	var oldest *time.Time
+	for i := range stats.Partitions {
+		timestamp, ok := timestamps[stats.Partitions[i].Partition]
+		if !ok {
+			continue
+		}
+		stats.Partitions[i].OldestTimestamp = &timestamp
+		if oldest == nil || timestamp.Before(*oldest) {
+			oldest = &timestamp
+		}
+	}
+	if oldest != nil {
+		age := time.Since(*oldest).Milliseconds()
+		utilization := float64(age) / float64(*retentionMs)
+		stats.Retention.OldestRecordAgeMs = &age
+		stats.Retention.TimeUtilization = &utilization
+	}
+	return err
+}
+
+// getRates measures the throughput of a partition over each window, from its latest sample and the oldest one
+// within the window. A sample up to half an interval older than the window still counts, since samples are only
+// taken every interval.
+func (s *Sampler) getRates(samples []sample) []Rate {
+	rates := make([]Rate, 0, len(s.config.Windows))
+	if len(samples) < 2 {
+		return rates
+	}
+	latest := samples[len(samples)-1]
+	for _, window := range s.config.Windows {
+		reach := window + s.config.SampleInterval/2
+		first := slices.IndexFunc(samples, func(sample sample) bool {
+			return latest.time.Sub(sample.time) <= reach
+		})
+		if first == len(samples)-1 {
+			continue
+		}
+		rates = append(rates, getRate(window, samples[first], latest))
+	}
+	return rates
+}
+
+func getRate(window time.Duration, first sample, latest sample) Rate {
+	seconds := latest.time.Sub(first.time).Seconds()
+	// The offsets only go down when the topic was recreated
+	messages := max(0, latest.offsets.Newest-first.offsets.Newest)
+	rate := Rate{
+		WindowSeconds:     int64(window.Seconds()),
+		CoveredSeconds:    seconds,
+		MessagesPerSecond: float64(messages) / seconds,
+	}
+	growth := latest.size.Bytes - first.size.Bytes
+	switch {
+	case first.size.Bytes < 0 || latest.size.Bytes < 0:
+	case growth >= 0 && latest.offsets.Oldest == first.offsets.Oldest:
+		rate.BytesPerSecond = float64(growth) / seconds
+	case latest.offsets.Newest > latest.offsets.Oldest:
+		bytesPerOffset := float64(latest.size.Bytes) / float64(latest.offsets.Newest-latest.offsets.Oldest)
+		rate.BytesPerSecond = rate.MessagesPerSecond * bytesPerOffset
+	}
+	return rate
+}
+
+// sumRates adds up the throughput of the partitions for every window. A window covers as much as the partition
+// that covers the least of it.
+func sumRates(partitions []PartitionStats) []Rate {
+	totals := make(map[int64]*Rate)
+	for _, partition := range partitions {
+		for _, rate := range partition.Rates {
+			total, ok := totals[rate.WindowSeconds]
+			if !ok {
+				total = &Rate{WindowSeconds: rate.WindowSeconds, CoveredSeconds: rate.CoveredSeconds}
+				totals[rate.WindowSeconds] = total
+			}
+			total.CoveredSeconds = min(total.CoveredSeconds, rate.CoveredSeconds)
+			total.MessagesPerSecond += rate.MessagesPerSecond
+			total.BytesPerSecond += rate.BytesPerSecond
+		}
+	}
+	rates := make([]Rate, 0, len(totals))
+	for _, total := range totals {
+		rates = append(rates, *total)
+	}
+	slices.SortFunc(rates, func(a, b Rate) int {
+		return cmp.Compare(a.WindowSeconds, b.WindowSeconds)
+	})
+	return rates
+}
+
+// getThroughputSkew compares the partitions over the longest window that every partition has a rate for.
+func getThroughputSkew(partitions []PartitionStats) *float64 {
+	partitionsByWindow := make(map[int64]int)
+	for _, partition := range partitions {
+		for _, rate := range partition.Rates {
+			partitionsByWindow[rate.WindowSeconds]++
+		}
+	}
+	longestWindow := int64(-1)
+	for window, count := range partitionsByWindow {
+		if count == len(partitions) {
+			longestWindow = max(longestWindow, window)
+		}
+	}
+	if longestWindow < 0 {
+		return nil
+	}
+	rates := make([]float64, 0, len(partitions))
+	for _, partition := range partitions {
+		for _, rate := range partition.Rates {
+			if rate.WindowSeconds == longestWindow {
+				rates = append(rates, rate.MessagesPerSecond)
+			}
+		}
+	}
+	return getSkew(rates)
+}
+
+func getSizeSkew(partitions []PartitionStats) *float64 {
+	sizes := make([]float64, 0, len(partitions))
+	for _, partition := range partitions {
+		if partition.SizeBytes < 0 {
+			return nil
+		}
+		sizes = append(sizes, float64(partition.SizeBytes))
+	}
+	return getSkew(sizes)
+}
+
+// getSkew is how many times the mean of values the largest one is.
+func getSkew(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	if sum == 0 {
+		return nil
+	}
+	skew := slices.Max(values) / (sum / float64(len(values)))
+	return &skew
+}
+
+func deletesRecords(policy model.CleanupPolicy) bool {
+	return policy == model.CleanupPolicyDelete || policy == model.CleanupPolicyBoth
+}
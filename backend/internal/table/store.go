@@ -0,0 +1,129 @@
+package table
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"sync"
+)
+
+type Config struct {
+	// The number of scanned tables that are kept in memory. The oldest scan is dropped to make room for a new one
+	MaxTables int
+}
+
+func DefaultConfig() Config {
+	return Config{MaxTables: 5}
+}
+
+// Store keeps the tables of the latest scans of compacted topics in memory, so that they can be paged through and
+// looked up without scanning the topic again.
+type Store struct {
+	config Config
+	tables map[string]*Table
+	// The IDs of the tables, oldest first
+	order []string
+	mutex sync.RWMutex
+}
+
+func NewStore(config Config) *Store {
+	if config.MaxTables <= 0 {
+		config.MaxTables = DefaultConfig().MaxTables
+	}
+	return &Store{
+		config: config,
+		tables: make(map[string]*Table),
+	}
+}
+
+// Add indexes topicTable, which must not change afterwards, and keeps it as id, dropping the oldest tables beyond
+// the limit.
+func (s *Store) Add(id string, topicTable *model.TopicTable) error {
+	table, err := newTable(topicTable)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.tables[id]; !ok {
+		s.order = append(s.order, id)
+	}
+	s.tables[id] = table
+	for len(s.order) > s.config.MaxTables {
+		delete(s.tables, s.order[0])
+		s.order = s.order[1:]
+	}
+	return nil
+}
+
+// Get returns the table kept as id, or nil when it was never scanned or was dropped since.
+func (s *Store) Get(id string) *Table {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.tables[id]
+}
+
+// Table is the result of a single scan, indexed by key. It doesn't change once scanned.
+type Table struct {
+	Summary model.TableSummary
+	rows    []model.TableRow
+	// The positions of the rows that aren't tombstones
+	live []int
+	// The position of the row of every key
+	index map[string]int
+}
+
+func newTable(topicTable *model.TopicTable) (*Table, error) {
+	table := &Table{
+		Summary: topicTable.Summary,
+		rows:    topicTable.Rows,
+		index:   make(map[string]int, len(topicTable.Rows)),
+	}
+	for i, row := range table.rows {
+		key, err := decodeKey(row.Key, row.Base64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index key %s: %w", row.Key, err)
+		}
+		table.index[string(key)] = i
+		if !row.Tombstone {
+			table.live = append(table.live, i)
+		}
+	}
+	return table, nil
+}
+
+// Rows returns up to limit rows from offset in the order of their keys, along with the number of rows there are.
+// Keys whose newest message is a tombstone are left out unless tombstones is set.
+func (t *Table) Rows(offset int, limit int, tombstones bool) ([]model.TableRow, int) {
+	total := len(t.live)
+	if tombstones {
+		total = len(t.rows)
+	}
+	start := min(offset, total)
+	end := min(start+limit, total)
+	rows := make([]model.TableRow, 0, end-start)
+	for i := start; i < end; i++ {
+		if tombstones {
+			rows = append(rows, t.rows[i])
+		} else {
+			rows = append(rows, t.rows[t.live[i]])
+		}
+	}
+	return rows, total
+}
+
+// Lookup returns the row of the raw key. A tombstone is only returned when tombstones is set.
+func (t *Table) Lookup(key []byte, tombstones bool) (model.TableRow, bool) {
+	i, ok := t.index[string(key)]
+	if !ok || (t.rows[i].Tombstone && !tombstones) {
+		return model.TableRow{}, false
+	}
+	return t.rows[i], true
+}
+
+func decodeKey(key string, isBase64 bool) ([]byte, error) {
+	if !isBase64 {
+		return []byte(key), nil
+	}
+	return base64.StdEncoding.DecodeString(key)
+}
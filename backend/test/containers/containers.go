@@ -3,6 +3,7 @@ package containers
 import (
 	"context"
 	"fmt"
+	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/kafka"
 	"log"
 	"time"
@@ -19,6 +20,8 @@ func startKafkaContainer(ctx context.Context) (bootstrapAddress string, stopCont
 		containerCtx,
 		"confluentinc/cp-kafka:7.6.1",
 		kafka.WithClusterID(ContainerClusterId),
+		// Lets tests of compacted topics see compaction within seconds
+		testcontainers.WithEnv(map[string]string{"KAFKA_LOG_CLEANER_BACKOFF_MS": "500"}),
 	)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to start Kafka container: %v", err)
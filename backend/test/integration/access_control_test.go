@@ -0,0 +1,240 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/handler"
+	"github.com/IBM/sarama"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAccessControl(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	publicTopic := "test-access-control-public"
+	privateTopic := "test-access-control-private"
+	for _, topic := range []string{publicTopic, privateTopic} {
+		err = admin.CreateTopic(topic, &sarama.TopicDetail{NumPartitions: 1, ReplicationFactor: 1}, false)
+		assert.NoError(t, err)
+		err = produceMessages(client, []*sarama.ProducerMessage{{Topic: topic, Value: sarama.StringEncoder(`{}`)}})
+		assert.NoError(t, err)
+	}
+
+	authorizer, err := auth.NewAuthorizer(auth.RBACConfig{
+		Roles: []auth.Role{
+			{Name: "viewer", Permissions: []auth.Permission{{
+				Operations: []auth.Operation{auth.OperationViewTopics, auth.OperationViewMessages},
+				Topics:     []string{"test-access-control-pub*"},
+			}}},
+			{Name: "producer", Permissions: []auth.Permission{{
+				Operations: []auth.Operation{auth.OperationProduce},
+				Clusters:   []string{"default"},
+				Topics:     []string{"test-access-control-*"},
+			}}},
+			{Name: "admin", Permissions: []auth.Permission{{Operations: []auth.Operation{auth.OperationAll}}}},
+		},
+		Bindings: []auth.Binding{
+			{Role: "producer", Users: []string{"bob"}},
+			{Role: "admin", Users: []string{"root"}},
+		},
+		DefaultRoles: []string{"viewer"},
+		Cluster:      "default",
+	})
+	assert.NoError(t, err)
+	kafkaService.SetAuthorizer(authorizer)
+
+	asUser := func(name string) context.Context {
+		return auth.WithIdentity(context.Background(), &auth.Identity{Name: name, Method: auth.MethodToken})
+	}
+	getTopicNames := func(t *testing.T, ctx context.Context) []string {
+		topics, err := kafkaService.GetTopics(ctx)
+		assert.NoError(t, err)
+		var names []string
+		for _, topic := range topics {
+			names = append(names, topic.Name)
+		}
+		return names
+	}
+
+	t.Run("Should only list the topics the caller may view", func(t *testing.T) {
+		names := getTopicNames(t, asUser("alice"))
+		assert.Contains(t, names, publicTopic)
+		assert.NotContains(t, names, privateTopic)
+
+		assert.Contains(t, getTopicNames(t, asUser("root")), privateTopic)
+		// The background work of the server has no identity, and sees everything
+		assert.Contains(t, getTopicNames(t, context.Background()), privateTopic)
+	})
+
+	t.Run("Should refuse reading and producing to topics the caller may not", func(t *testing.T) {
+		partitions := model.PartitionInput{PartitionDetailsMap: map[int32]model.PartitionDetails{
+			0: {StartOffset: 0, EndOffset: 0},
+		}}
+		alice := asUser("alice")
+		messages, err := kafkaService.GetMessagesForTopic(alice, publicTopic, partitions, model.FetchOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, messages.Messages, 1)
+
+		_, err = kafkaService.GetMessagesForTopic(alice, privateTopic, partitions, model.FetchOptions{})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		err = kafkaService.StreamMessagesForTopic(
+			asUser("bob"),
+			privateTopic,
+			partitions,
+			nil,
+			func(record *model.Record) error { return nil },
+		)
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+
+		copyDestination := model.CopyDestination{Topic: privateTopic}
+		_, err = kafkaService.CopyMessages(
+			alice,
+			model.CopySource{Topic: publicTopic},
+			copyDestination,
+			model.CopyOptions{},
+			func(progress model.CopyProgress) {},
+		)
+		var forbidden *auth.ForbiddenError
+		assert.ErrorAs(t, err, &forbidden)
+		assert.Equal(t, auth.OperationProduce, forbidden.Operation)
+		assert.Equal(t, auth.Resource{Cluster: "default", Topic: privateTopic}, forbidden.Resource)
+
+		progress, err := kafkaService.CopyMessages(
+			asUser("bob"),
+			model.CopySource{Topic: publicTopic},
+			copyDestination,
+			model.CopyOptions{},
+			func(progress model.CopyProgress) {},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), progress.Copied)
+	})
+
+	dataDir := t.TempDir()
+	jobManager, err := job.NewManager(
+		context.Background(),
+		job.NewFileStore(filepath.Join(dataDir, "jobs.json")),
+		job.Config{MaxConcurrent: 1, HistorySize: 10, FileDir: filepath.Join(dataDir, "files")},
+		logger,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create job manager: %s", err)
+	}
+	aliceJob := jobManager.SubmitAs("alice", "test", "Owned by alice", func(ctx context.Context, run *job.Run) error {
+		return nil
+	})
+	jobManager.Wait()
+
+	authenticators, err := auth.NewAuthenticators(auth.Config{Tokens: []auth.Token{
+		{Name: "alice", Token: "alice-token"},
+		{Name: "bob", Token: "bob-token"},
+		{Name: "root", Token: "root-token"},
+	}})
+	assert.NoError(t, err)
+	r := mux.NewRouter()
+	r.Use(handler.AuthMiddleware(authenticators, logger))
+	r.Handle("/topics/messages", handler.TopicMessagesHandler(kafkaService, logger)).Methods("POST")
+	r.Handle("/jobs", handler.JobsHandler(jobManager, authorizer, logger)).Methods("GET")
+	r.Handle("/jobs/{id}", handler.JobHandler(jobManager, authorizer, logger)).Methods("GET")
+	r.Handle("/permissions", handler.PermissionsHandler(authorizer, logger)).Methods("GET")
+	r.Handle("/permissions/check", handler.PermissionCheckHandler(authorizer, logger)).Methods("GET")
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	request := func(t *testing.T, method string, path string, token string, body interface{}, result interface{}) int {
+		var requestBody io.Reader
+		if body != nil {
+			encoded, err := json.Marshal(body)
+			assert.NoError(t, err)
+			requestBody = bytes.NewReader(encoded)
+		}
+		httpRequest, err := http.NewRequest(method, server.URL+path, requestBody)
+		assert.NoError(t, err)
+		httpRequest.Header.Set("X-API-Token", token)
+		response, err := http.DefaultClient.Do(httpRequest)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+		if result != nil {
+			assert.NoError(t, json.NewDecoder(response.Body).Decode(result))
+		}
+		return response.StatusCode
+	}
+
+	t.Run("Should answer with 403 when the caller may not perform an operation", func(t *testing.T) {
+		body := map[string]interface{}{
+			"topicName":  privateTopic,
+			"partitions": []map[string]int64{{"partition": 0, "startOffset": 0, "endOffset": 0}},
+		}
+		var errorMessage handler.ErrorMessage
+		status := request(t, http.MethodPost, "/topics/messages", "alice-token", body, &errorMessage)
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Contains(t, errorMessage.Message, "view-messages")
+
+		status = request(t, http.MethodPost, "/topics/messages", "root-token", body, nil)
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("Should only show callers their own jobs unless they may admin jobs", func(t *testing.T) {
+		var jobs []job.Job
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, "/jobs", "alice-token", nil, &jobs))
+		assert.Len(t, jobs, 1)
+		assert.Equal(t, "alice", jobs[0].Owner)
+
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, "/jobs", "bob-token", nil, &jobs))
+		assert.Empty(t, jobs)
+		assert.Equal(t, http.StatusNotFound, request(t, http.MethodGet, "/jobs/"+aliceJob.ID, "bob-token", nil, nil))
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, "/jobs/"+aliceJob.ID, "root-token", nil, nil))
+	})
+
+	t.Run("Should tell callers what they may do", func(t *testing.T) {
+		var grants auth.Grants
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, "/permissions", "bob-token", nil, &grants))
+		assert.True(t, grants.Restricted)
+		assert.Equal(t, "bob", grants.Identity.Name)
+		assert.Equal(t, []string{"viewer", "producer"}, grants.Roles)
+		assert.True(t, slices.ContainsFunc(grants.Permissions, func(permission auth.Permission) bool {
+			return slices.Contains(permission.Operations, auth.OperationProduce)
+		}))
+
+		var check handler.PermissionCheck
+		path := "/permissions/check?operation=produce&topic=" + privateTopic
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, path, "bob-token", nil, &check))
+		assert.True(t, check.Allowed)
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, path, "alice-token", nil, &check))
+		assert.False(t, check.Allowed)
+		path = "/permissions/check?operation=produce&cluster=mirror&topic=" + privateTopic
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, path, "bob-token", nil, &check))
+		assert.False(t, check.Allowed)
+
+		status := request(t, http.MethodGet, "/permissions/check?operation=fly", "bob-token", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
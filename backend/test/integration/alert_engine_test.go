@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/alert"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/lag"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAlertEngine(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-alert-engine"
+	group := "test-alert-engine-group"
+	err = admin.CreateTopic(topic, &sarama.TopicDetail{NumPartitions: 1, ReplicationFactor: 1}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, produceMessages(client, createPartitionMessages(topic, 0, 50)))
+	offsetManager, err := sarama.NewOffsetManagerFromClient(group, client)
+	assert.NoError(t, err)
+	defer offsetManager.Close()
+	partitionManager, err := offsetManager.ManagePartition(topic, 0)
+	assert.NoError(t, err)
+	partitionManager.MarkOffset(5, "")
+	offsetManager.Commit()
+
+	var notifications []alert.Alert
+	var mutex sync.Mutex
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification alert.Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		mutex.Lock()
+		defer mutex.Unlock()
+		notifications = append(notifications, notification)
+	}))
+	defer webhook.Close()
+	received := func(rule string, state alert.State) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, notification := range notifications {
+			if notification.Rule == rule && notification.State == state {
+				return true
+			}
+		}
+		return false
+	}
+
+	store, err := lag.OpenStore(filepath.Join(t.TempDir(), "lag.db"))
+	assert.NoError(t, err)
+	defer store.Close()
+	interval := 300 * time.Millisecond
+	collector := lag.NewCollector(kafkaService, store, lag.Config{Interval: interval, RawRetention: time.Hour}, logger)
+	sinks, err := alert.NewSinks([]alert.SinkConfig{{Name: "webhook", Type: alert.SinkWebhook, URL: webhook.URL}}, logger)
+	assert.NoError(t, err)
+	engine, err := alert.NewEngine(
+		kafkaService,
+		collector,
+		alert.Config{
+			Interval: interval,
+			Rules: []alert.Rule{
+				{Name: "high-lag", Type: alert.RuleLagAbove, Groups: []string{group}, Threshold: 20},
+				{Name: "idle", Type: alert.RuleTopicIdle, Topics: []string{topic}, For: 2 * time.Second},
+			},
+		},
+		sinks,
+		alert.NewFileSilenceStore(filepath.Join(t.TempDir(), "silences.json")),
+		logger,
+	)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go collector.Run(ctx)
+	go engine.Run(ctx)
+
+	t.Run("Should fire alerts when lag is high and a topic is idle", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			return received("high-lag", alert.StateFiring) && received("idle", alert.StateFiring)
+		}, 10*time.Second, interval)
+		firing := engine.Alerts(alert.StateFiring)
+		assert.Len(t, firing, 2)
+		for _, firingAlert := range firing {
+			assert.Equal(t, topic, firingAlert.Topic)
+			if firingAlert.Rule == "high-lag" {
+				assert.Equal(t, group, firingAlert.Group)
+				assert.Equal(t, 45.0, firingAlert.Value)
+			}
+		}
+	})
+
+	t.Run("Should resolve alerts whose conditions no longer hold", func(t *testing.T) {
+		silence, err := engine.AddSilence(alert.Silence{Rule: "idle", EndsAt: time.Now().Add(time.Minute)})
+		assert.NoError(t, err)
+		partitionManager.MarkOffset(50, "")
+		offsetManager.Commit()
+		assert.NoError(t, produceMessages(client, createPartitionMessages(topic, 0, 1)))
+
+		assert.Eventually(t, func() bool {
+			return len(engine.Alerts(alert.StateResolved)) == 2
+		}, 10*time.Second, interval)
+		assert.True(t, received("high-lag", alert.StateResolved))
+		// The idle alert resolved while silenced
+		assert.False(t, received("idle", alert.StateResolved))
+		assert.Equal(t, []alert.Silence{silence}, engine.Silences())
+	})
+
+	cancel()
+	assert.NoError(t, partitionManager.Close())
+	teardown(t, kafkaService, admin, []string{topic})
+}
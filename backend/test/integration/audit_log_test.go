@@ -0,0 +1,206 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/audit"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/handler"
+	"github.com/IBM/sarama"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	publicTopic := "test-audit-public"
+	privateTopic := "test-audit-private"
+	auditTopic := "test-audit-entries"
+	for _, topic := range []string{publicTopic, privateTopic, auditTopic} {
+		assert.NoError(t, createTopic(admin, topic, 1, 1))
+	}
+	err = produceMessages(client, []*sarama.ProducerMessage{{Topic: publicTopic, Value: sarama.StringEncoder(`{}`)}})
+	assert.NoError(t, err)
+
+	authorizer, err := auth.NewAuthorizer(auth.RBACConfig{
+		Roles: []auth.Role{
+			{Name: "viewer", Permissions: []auth.Permission{{
+				Operations: []auth.Operation{auth.OperationViewMessages},
+				Topics:     []string{publicTopic},
+			}}},
+			{Name: "auditor", Permissions: []auth.Permission{{Operations: []auth.Operation{auth.OperationViewAudit}}}},
+		},
+		Bindings:     []auth.Binding{{Role: "auditor", Users: []string{"auditor"}}},
+		DefaultRoles: []string{"viewer"},
+		Cluster:      "default",
+	})
+	assert.NoError(t, err)
+	kafkaService.SetAuthorizer(authorizer)
+	authenticators, err := auth.NewAuthenticators(auth.Config{Tokens: []auth.Token{
+		{Name: "alice", Token: "alice-token"},
+		{Name: "auditor", Token: "auditor-token"},
+	}})
+	assert.NoError(t, err)
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	fileWriter, err := audit.OpenFileWriter(auditPath)
+	assert.NoError(t, err)
+	kafkaWriter, err := audit.NewKafkaWriter([]string{bootstrapAddress}, config, auditTopic, logger)
+	assert.NoError(t, err)
+	auditLog := audit.NewLog(
+		audit.Config{RecentEntries: 100},
+		nil,
+		[]audit.Writer{fileWriter, kafkaWriter},
+		logger,
+	)
+
+	r := mux.NewRouter()
+	r.Use(handler.AuditMiddleware(auditLog, logger))
+	r.Use(handler.AuthMiddleware(authenticators, logger))
+	r.Handle("/topics/messages", handler.TopicMessagesHandler(kafkaService, logger)).Methods("POST")
+	r.Handle("/audit", handler.AuditHandler(auditLog, authorizer, logger)).Methods("GET")
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	request := func(t *testing.T, method string, path string, token string, body interface{}, result interface{}) int {
+		var requestBody io.Reader
+		if body != nil {
+			encoded, err := json.Marshal(body)
+			assert.NoError(t, err)
+			requestBody = bytes.NewReader(encoded)
+		}
+		httpRequest, err := http.NewRequest(method, server.URL+path, requestBody)
+		assert.NoError(t, err)
+		httpRequest.Header.Set("Content-Type", "application/json")
+		httpRequest.Header.Set("X-API-Token", token)
+		response, err := http.DefaultClient.Do(httpRequest)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+		if result != nil {
+			assert.NoError(t, json.NewDecoder(response.Body).Decode(result))
+		}
+		return response.StatusCode
+	}
+	fetch := func(topic string) map[string]interface{} {
+		return map[string]interface{}{
+			"topicName":  topic,
+			"partitions": []map[string]int64{{"partition": 0, "startOffset": 0, "endOffset": 0}},
+			"password":   "hunter2",
+		}
+	}
+
+	t.Run("Should record who viewed which topic, with secrets redacted", func(t *testing.T) {
+		status := request(t, http.MethodPost, "/topics/messages", "alice-token", fetch(publicTopic), nil)
+		assert.Equal(t, http.StatusOK, status)
+
+		var entries []audit.Entry
+		status = request(t, http.MethodGet, "/audit?user=alice", "auditor-token", nil, &entries)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, entries, 1)
+		entry := entries[0]
+		assert.NotEmpty(t, entry.ID)
+		assert.Equal(t, auth.MethodToken, entry.AuthMethod)
+		assert.Equal(t, "POST /topics/messages", entry.Operation)
+		assert.Equal(t, []auth.Resource{{Topic: publicTopic}}, entry.Resources)
+		assert.Equal(t, audit.OutcomeSucceeded, entry.Outcome)
+		assert.Equal(t, http.StatusOK, entry.Status)
+		body := entry.Parameters["body"].(map[string]interface{})
+		assert.Equal(t, publicTopic, body["topicName"])
+		assert.Equal(t, "[REDACTED]", body["password"])
+	})
+
+	t.Run("Should record denied requests and refuse the audit log to those who may not view it", func(t *testing.T) {
+		status := request(t, http.MethodPost, "/topics/messages", "alice-token", fetch(privateTopic), nil)
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, http.StatusForbidden, request(t, http.MethodGet, "/audit", "alice-token", nil, nil))
+
+		var entries []audit.Entry
+		path := "/audit?outcome=denied&topic=" + privateTopic
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, path, "auditor-token", nil, &entries))
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "alice", entries[0].User)
+		assert.Equal(t, http.StatusForbidden, entries[0].Status)
+
+		path = "/audit?user=alice&limit=2"
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, path, "auditor-token", nil, &entries))
+		assert.Len(t, entries, 2)
+		// Newest first, which is the request that denied alice the audit log
+		assert.Equal(t, "GET /audit", entries[0].Operation)
+		assert.Equal(t, audit.OutcomeDenied, entries[0].Outcome)
+	})
+
+	t.Run("Should record requests refused for invalid credentials", func(t *testing.T) {
+		status := request(t, http.MethodPost, "/topics/messages", "stolen-token", fetch(publicTopic), nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		var entries []audit.Entry
+		path := "/audit?outcome=denied&limit=1"
+		assert.Equal(t, http.StatusOK, request(t, http.MethodGet, path, "auditor-token", nil, &entries))
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "POST /topics/messages", entries[0].Operation)
+			assert.Equal(t, http.StatusUnauthorized, entries[0].Status)
+			assert.Equal(t, audit.OutcomeDenied, entries[0].Outcome)
+			// Nobody could be told apart from the credentials
+			assert.Empty(t, entries[0].User)
+			assert.Equal(t, []auth.Resource{{Topic: publicTopic}}, entries[0].Resources)
+		}
+	})
+
+	t.Run("Should append every entry to the file and the topic", func(t *testing.T) {
+		recent := auditLog.Query(audit.Filter{})
+		assert.NoError(t, auditLog.Close())
+
+		entries, err := audit.ReadTail(auditPath, 100)
+		assert.NoError(t, err)
+		assert.Len(t, entries, len(recent))
+		data, err := os.ReadFile(auditPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "hunter2")
+
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
+			auditTopic,
+			getPartitionInput(0, 0, int64(len(recent)-1)),
+		)
+		assert.NoError(t, err)
+		assert.Len(t, messages, len(recent))
+		assert.Equal(t, "alice", messages[0].Key)
+	})
+
+	t.Run("Should refuse entries once closed, as those of requests finishing during shutdown", func(t *testing.T) {
+		entry := audit.Entry{User: "alice", Operation: "POST /topics/messages"}
+		assert.ErrorIs(t, fileWriter.Write(entry), audit.ErrWriterClosed)
+		assert.ErrorIs(t, kafkaWriter.Write(entry), audit.ErrWriterClosed)
+		assert.NotPanics(t, func() {
+			auditLog.Record(entry)
+		})
+		assert.NoError(t, auditLog.Close())
+	})
+
+	teardown(t, kafkaService, admin, []string{publicTopic, privateTopic, auditTopic})
+}
@@ -0,0 +1,219 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/auth"
+	"github.com/Avi18971911/kafka-window/backend/internal/server/handler"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthentication(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+
+	// A local stand-in for the JWKS of an OIDC provider
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	writeJWKS := func(w http.ResponseWriter) {
+		err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(signingKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signingKey.E)).Bytes()),
+			}},
+		})
+		assert.NoError(t, err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJWKS(w)
+	}))
+	defer jwks.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	htpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+	assert.NoError(t, os.WriteFile(htpasswdFile, []byte("# users\nalice:"+string(hash)+"\n"), 0o600))
+
+	authenticators, err := auth.NewAuthenticators(auth.Config{
+		Tokens:       []auth.Token{{Name: "ci", Token: "ci-token"}},
+		HtpasswdFile: htpasswdFile,
+		OIDC: &auth.OIDCConfig{
+			Issuer:        "https://issuer.example",
+			Audience:      "kafka-window",
+			JWKSURL:       jwks.URL,
+			UsernameClaim: "email",
+		},
+	})
+	assert.NoError(t, err)
+	server := httptest.NewServer(handler.AuthMiddleware(authenticators, logger)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewEncoder(w).Encode(auth.IdentityFrom(r.Context())))
+		}),
+	))
+	defer server.Close()
+
+	request := func(t *testing.T, setCredentials func(r *http.Request)) (int, map[string]string) {
+		r, err := http.NewRequest(http.MethodGet, server.URL+"/topics", nil)
+		assert.NoError(t, err)
+		setCredentials(r)
+		response, err := http.DefaultClient.Do(r)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+		return response.StatusCode, body
+	}
+	signToken := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test-key"
+		signed, err := token.SignedString(signingKey)
+		assert.NoError(t, err)
+		return signed
+	}
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   "https://issuer.example",
+			"aud":   "kafka-window",
+			"sub":   "user-1",
+			"email": "bob@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	t.Run("Should refuse requests without credentials", func(t *testing.T) {
+		status, body := request(t, func(r *http.Request) {})
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, "Authentication required.", body["message"])
+	})
+
+	t.Run("Should accept API tokens", func(t *testing.T) {
+		status, body := request(t, func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer ci-token")
+		})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "ci", body["name"])
+		assert.Equal(t, string(auth.MethodToken), body["method"])
+
+		status, _ = request(t, func(r *http.Request) {
+			r.Header.Set("X-API-Token", "ci-token")
+		})
+		assert.Equal(t, http.StatusOK, status)
+
+		status, body = request(t, func(r *http.Request) {
+			r.Header.Set("X-API-Token", "wrong-token")
+		})
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, "Invalid credentials.", body["message"])
+	})
+
+	t.Run("Should accept users of the htpasswd file", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			status, body := request(t, func(r *http.Request) {
+				r.SetBasicAuth("alice", "secret")
+			})
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, "alice", body["name"])
+		}
+		status, _ := request(t, func(r *http.Request) {
+			r.SetBasicAuth("alice", "wrong")
+		})
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("Should accept JWTs signed with a key of the JWKS", func(t *testing.T) {
+		token := signToken(validClaims())
+		status, body := request(t, func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+token)
+		})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "bob@example.com", body["name"])
+		assert.Equal(t, string(auth.MethodOIDC), body["method"])
+	})
+
+	t.Run("Should refuse JWTs that don't hold", func(t *testing.T) {
+		expired := validClaims()
+		expired["exp"] = time.Now().Add(-time.Minute).Unix()
+		otherAudience := validClaims()
+		otherAudience["aud"] = "other"
+		otherIssuer := validClaims()
+		otherIssuer["iss"] = "https://other.example"
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+		foreign := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims())
+		foreign.Header["kid"] = "test-key"
+		foreignToken, err := foreign.SignedString(otherKey)
+		assert.NoError(t, err)
+
+		for _, token := range []string{signToken(expired), signToken(otherAudience), signToken(otherIssuer), foreignToken} {
+			status, body := request(t, func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+token)
+			})
+			assert.Equal(t, http.StatusUnauthorized, status)
+			assert.Equal(t, "Invalid credentials.", body["message"])
+		}
+	})
+
+	t.Run("Should share a JWKS fetch that outlives the request that started it", func(t *testing.T) {
+		var fetches atomic.Int32
+		slowJWKS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			time.Sleep(300 * time.Millisecond)
+			writeJWKS(w)
+		}))
+		defer slowJWKS.Close()
+		authenticator, err := auth.NewJWTAuthenticator(auth.OIDCConfig{
+			Issuer:        "https://issuer.example",
+			Audience:      "kafka-window",
+			JWKSURL:       slowJWKS.URL,
+			UsernameClaim: "email",
+		}, http.DefaultClient)
+		assert.NoError(t, err)
+		token := signToken(validClaims())
+		newRequest := func(ctx context.Context) *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/topics", nil).WithContext(ctx)
+			r.Header.Set("Authorization", "Bearer "+token)
+			return r
+		}
+
+		// The request gives up before the JWKS arrives, which neither cancels the fetch nor makes the token invalid
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = authenticator.Authenticate(newRequest(ctx))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, auth.ErrInvalidCredentials)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				identity, err := authenticator.Authenticate(newRequest(context.Background()))
+				if assert.NoError(t, err) {
+					assert.Equal(t, "bob@example.com", identity.Name)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), fetches.Load())
+	})
+}
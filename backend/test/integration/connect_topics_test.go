@@ -0,0 +1,182 @@
+package integration
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type connectRecordCase struct {
+	key           string
+	value         sarama.Encoder
+	expectedKey   string
+	expectedValue string
+}
+
+func TestConnectTopics(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	// Produces the records to a fresh topic, then reads them back and compares their decoded keys and values
+	assertDecodedRecords := func(t *testing.T, topic string, cases []connectRecordCase) {
+		assert.NoError(t, createTopic(admin, topic, 1, 1))
+		messages := make([]*sarama.ProducerMessage, len(cases))
+		for i, recordCase := range cases {
+			messages[i] = &sarama.ProducerMessage{
+				Topic: topic,
+				Key:   sarama.StringEncoder(recordCase.key),
+				Value: recordCase.value,
+			}
+		}
+		assert.NoError(t, produceMessages(client, messages))
+
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, -1),
+			model.FetchOptions{},
+		)
+		if !assert.NoError(t, err) || !assert.Len(t, topicMessages.Messages, len(cases)) {
+			return
+		}
+		for i, recordCase := range cases {
+			message := topicMessages.Messages[i]
+			assert.Equal(t, model.JSONPayload, message.KeyPayloadType, recordCase.key)
+			assert.JSONEq(t, recordCase.expectedKey, message.Key, recordCase.key)
+			assert.JSONEq(t, recordCase.expectedValue, message.Value, recordCase.key)
+		}
+		teardown(t, kafkaService, admin, []string{topic})
+	}
+
+	t.Run("Should decode the keys of a Connect config topic", func(t *testing.T) {
+		assertDecodedRecords(t, "test-connect-configs", []connectRecordCase{
+			{
+				key:           "connector-foo",
+				value:         sarama.StringEncoder(`{"properties":{"connector.class":"FileStreamSource"}}`),
+				expectedKey:   `{"type":"connector","connector":"foo"}`,
+				expectedValue: `{"properties":{"connector.class":"FileStreamSource"}}`,
+			},
+			{
+				key:           "task-foo-0",
+				value:         sarama.StringEncoder(`{"properties":{"task.class":"FileStreamSourceTask"}}`),
+				expectedKey:   `{"type":"task","connector":"foo","task":0}`,
+				expectedValue: `{"properties":{"task.class":"FileStreamSourceTask"}}`,
+			},
+			{
+				key:           "task-my-connector-12",
+				value:         sarama.StringEncoder(`{"properties":{}}`),
+				expectedKey:   `{"type":"task","connector":"my-connector","task":12}`,
+				expectedValue: `{"properties":{}}`,
+			},
+			{
+				key:           "commit-foo",
+				value:         sarama.StringEncoder(`{"tasks":1}`),
+				expectedKey:   `{"type":"commit","connector":"foo"}`,
+				expectedValue: `{"tasks":1}`,
+			},
+			{
+				key:           "target-state-foo",
+				value:         sarama.StringEncoder(`{"state":"PAUSED"}`),
+				expectedKey:   `{"type":"targetState","connector":"foo"}`,
+				expectedValue: `{"state":"PAUSED"}`,
+			},
+			{
+				key:           "session-key",
+				value:         sarama.StringEncoder(`{"algorithm":"HmacSHA256"}`),
+				expectedKey:   `{"type":"sessionKey"}`,
+				expectedValue: `{"algorithm":"HmacSHA256"}`,
+			},
+			{
+				key:           "connector-foo",
+				value:         nil,
+				expectedKey:   `{"type":"connector","connector":"foo"}`,
+				expectedValue: `{"tombstone":true,"reason":"connector configuration deleted"}`,
+			},
+		})
+	})
+
+	t.Run("Should decode the keys of a Connect offset topic", func(t *testing.T) {
+		assertDecodedRecords(t, "test-connect-offsets", []connectRecordCase{
+			{
+				key:           `["foo",{"filename":"/tmp/input.txt"}]`,
+				value:         sarama.StringEncoder(`{"position":1024}`),
+				expectedKey:   `{"connector":"foo","partition":{"filename":"/tmp/input.txt"}}`,
+				expectedValue: `{"position":1024}`,
+			},
+			{
+				key:           `["foo",{"filename":"/tmp/input.txt"}]`,
+				value:         nil,
+				expectedKey:   `{"connector":"foo","partition":{"filename":"/tmp/input.txt"}}`,
+				expectedValue: `{"tombstone":true,"reason":"source offset reset"}`,
+			},
+		})
+	})
+
+	t.Run("Should decode the keys of a Connect status topic", func(t *testing.T) {
+		assertDecodedRecords(t, "test-connect-status", []connectRecordCase{
+			{
+				key:           "status-connector-foo",
+				value:         sarama.StringEncoder(`{"state":"RUNNING","worker_id":"worker:8083","generation":2}`),
+				expectedKey:   `{"type":"connectorStatus","connector":"foo"}`,
+				expectedValue: `{"state":"RUNNING","worker_id":"worker:8083","generation":2}`,
+			},
+			{
+				key:           "status-task-foo-0",
+				value:         sarama.StringEncoder(`{"state":"FAILED","trace":"boom","worker_id":"worker:8083"}`),
+				expectedKey:   `{"type":"taskStatus","connector":"foo","task":0}`,
+				expectedValue: `{"state":"FAILED","trace":"boom","worker_id":"worker:8083"}`,
+			},
+			{
+				key:           "status-topic-orders:connector-foo",
+				value:         sarama.StringEncoder(`{"topic":{"name":"orders","connector":"foo"}}`),
+				expectedKey:   `{"type":"topicStatus","connector":"foo","topic":"orders"}`,
+				expectedValue: `{"topic":{"name":"orders","connector":"foo"}}`,
+			},
+			{
+				key:           "status-connector-foo",
+				value:         nil,
+				expectedKey:   `{"type":"connectorStatus","connector":"foo"}`,
+				expectedValue: `{"tombstone":true,"reason":"status removed"}`,
+			},
+		})
+	})
+
+	t.Run("Should decode keys that aren't Connect shaped as usual", func(t *testing.T) {
+		topic := "test-connect-lookalike-status"
+		assert.NoError(t, createTopic(admin, topic, 1, 1))
+		err := produceMessages(client, []*sarama.ProducerMessage{
+			{Topic: topic, Key: sarama.StringEncoder("plain-key"), Value: sarama.StringEncoder(`{"value":1}`)},
+		})
+		assert.NoError(t, err)
+
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, -1),
+			model.FetchOptions{},
+		)
+		if assert.NoError(t, err) && assert.Len(t, topicMessages.Messages, 1) {
+			assert.Equal(t, model.StringPayload, topicMessages.Messages[0].KeyPayloadType)
+			assert.Equal(t, "plain-key", topicMessages.Messages[0].Key)
+		}
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+}
@@ -0,0 +1,184 @@
+package integration
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestConsumerOffsets(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	messageDecoder := decoder.NewMessageDecoder(avroService, logger)
+
+	decode := func(t *testing.T, key []byte, value []byte) (map[string]interface{}, map[string]interface{}) {
+		decoded, err := messageDecoder.DecodeKeyAndValue("__consumer_offsets", key, value)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, model.ConsumerOffsetPayload, decoded.Key.Type)
+		assert.Equal(t, model.ConsumerOffsetPayload, decoded.Value.Type)
+		var decodedKey, decodedValue map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(decoded.Key.Payload), &decodedKey))
+		assert.NoError(t, json.Unmarshal([]byte(decoded.Value.Payload), &decodedValue))
+		return decodedKey, decodedValue
+	}
+
+	commitKey := kmsg.NewOffsetCommitKey()
+	commitKey.Version = 1
+	commitKey.Group = "test-group"
+	commitKey.Topic = "orders"
+	commitKey.Partition = 3
+	commitKeyBytes := commitKey.AppendTo(nil)
+
+	metadataKey := kmsg.NewGroupMetadataKey()
+	metadataKey.Version = 2
+	metadataKey.Group = "test-group"
+	metadataKeyBytes := metadataKey.AppendTo(nil)
+
+	t.Run("Should decode every offset commit value version", func(t *testing.T) {
+		expectedValues := []string{
+			`{"version":0,"offset":42,"metadata":"meta","commitTimestamp":1000}`,
+			`{"version":1,"offset":42,"metadata":"meta","commitTimestamp":1000,"expireTimestamp":2000}`,
+			`{"version":2,"offset":42,"metadata":"meta","commitTimestamp":1000}`,
+			`{"version":3,"offset":42,"leaderEpoch":7,"metadata":"meta","commitTimestamp":1000}`,
+		}
+		for version, expectedValue := range expectedValues {
+			t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+				commitValue := kmsg.NewOffsetCommitValue()
+				commitValue.Version = int16(version)
+				commitValue.Offset = 42
+				commitValue.LeaderEpoch = 7
+				commitValue.Metadata = "meta"
+				commitValue.CommitTimestamp = 1000
+				commitValue.ExpireTimestamp = 2000
+
+				decoded, err := messageDecoder.DecodeKeyAndValue(
+					"__consumer_offsets",
+					commitKeyBytes,
+					commitValue.AppendTo(nil),
+				)
+				assert.NoError(t, err)
+				assert.JSONEq(
+					t,
+					`{"type":"offsetCommit","version":1,"group":"test-group","topic":"orders","partition":3}`,
+					decoded.Key.Payload,
+				)
+				assert.JSONEq(t, expectedValue, decoded.Value.Payload)
+			})
+		}
+	})
+
+	t.Run("Should summarize every group metadata value version", func(t *testing.T) {
+		subscription := kmsg.NewConsumerMemberMetadata()
+		subscription.Topics = []string{"orders"}
+		assignment := kmsg.NewConsumerMemberAssignment()
+		assignedTopic := kmsg.NewConsumerMemberAssignmentTopic()
+		assignedTopic.Topic = "orders"
+		assignedTopic.Partitions = []int32{0, 1}
+		assignment.Topics = append(assignment.Topics, assignedTopic)
+
+		for version := int16(0); version <= 4; version++ {
+			t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+				metadataValue := kmsg.NewGroupMetadataValue()
+				metadataValue.Version = version
+				metadataValue.ProtocolType = "consumer"
+				metadataValue.Generation = 5
+				metadataValue.Protocol = stringPointer("range")
+				metadataValue.Leader = stringPointer("member-1")
+				metadataValue.CurrentStateTimestamp = 3000
+				member := kmsg.NewGroupMetadataValueMember()
+				member.MemberID = "member-1"
+				member.InstanceID = stringPointer("instance-1")
+				member.ClientID = "client"
+				member.ClientHost = "/10.0.0.1"
+				member.RebalanceTimeoutMillis = 60000
+				member.SessionTimeoutMillis = 45000
+				member.Subscription = subscription.AppendTo(nil)
+				member.Assignment = assignment.AppendTo(nil)
+				metadataValue.Members = append(metadataValue.Members, member)
+
+				decodedKey, decodedValue := decode(t, metadataKeyBytes, metadataValue.AppendTo(nil))
+				assert.Equal(t, map[string]interface{}{
+					"type":    "groupMetadata",
+					"version": float64(2),
+					"group":   "test-group",
+				}, decodedKey)
+
+				expectedValue := map[string]interface{}{
+					"version":      float64(version),
+					"protocolType": "consumer",
+					"generation":   float64(5),
+					"protocol":     "range",
+					"leader":       "member-1",
+				}
+				expectedMember := map[string]interface{}{
+					"memberId":             "member-1",
+					"clientId":             "client",
+					"clientHost":           "/10.0.0.1",
+					"sessionTimeoutMillis": float64(45000),
+					"subscription":         map[string]interface{}{"topics": []interface{}{"orders"}},
+					"assignment": []interface{}{
+						map[string]interface{}{"topic": "orders", "partitions": []interface{}{float64(0), float64(1)}},
+					},
+				}
+				// Fields are only summarized from the version that introduced them
+				if version >= 1 {
+					expectedMember["rebalanceTimeoutMillis"] = float64(60000)
+				}
+				if version >= 2 {
+					expectedValue["currentStateTimestamp"] = float64(3000)
+				}
+				if version >= 3 {
+					expectedMember["groupInstanceId"] = "instance-1"
+				}
+				expectedValue["members"] = []interface{}{expectedMember}
+				assert.Equal(t, expectedValue, decodedValue)
+			})
+		}
+	})
+
+	t.Run("Should show tombstones of offsets and groups", func(t *testing.T) {
+		_, decodedValue := decode(t, commitKeyBytes, nil)
+		assert.Equal(t, map[string]interface{}{"tombstone": true, "reason": "offset deleted"}, decodedValue)
+
+		_, decodedValue = decode(t, metadataKeyBytes, nil)
+		assert.Equal(t, map[string]interface{}{"tombstone": true, "reason": "group deleted"}, decodedValue)
+	})
+
+	t.Run("Should keep the raw bytes of unknown key and value versions", func(t *testing.T) {
+		unknownValue := []byte{0, 9, 1, 2, 3}
+		decodedKey, decodedValue := decode(t, commitKeyBytes, unknownValue)
+		assert.Equal(t, "offsetCommit", decodedKey["type"])
+		assert.Equal(t, map[string]interface{}{
+			"version": float64(9),
+			"unknown": true,
+			"raw":     base64.StdEncoding.EncodeToString(unknownValue),
+		}, decodedValue)
+
+		_, decodedValue = decode(t, metadataKeyBytes, unknownValue)
+		assert.Equal(t, true, decodedValue["unknown"])
+
+		unknownKey := []byte{0, 7, 'k', 'e', 'y'}
+		decodedKey, decodedValue = decode(t, unknownKey, unknownValue)
+		assert.Equal(t, map[string]interface{}{
+			"version": float64(7),
+			"unknown": true,
+			"raw":     base64.StdEncoding.EncodeToString(unknownKey),
+		}, decodedKey)
+		assert.Equal(t, float64(9), decodedValue["version"])
+
+		_, decodedValue = decode(t, unknownKey, nil)
+		assert.Equal(t, map[string]interface{}{"tombstone": true, "reason": "record deleted"}, decodedValue)
+	})
+}
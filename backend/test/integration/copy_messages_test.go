@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyMessages(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	t.Run("Should copy filtered and transformed messages to a topic on another configured cluster", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+		kafkaService.RegisterCluster("mirror", []string{bootstrapAddress}, config)
+
+		sourceTopic := "test-topic-copy-source"
+		destinationTopic := "test-topic-copy-destination"
+		assert.NoError(t, createTopic(admin, sourceTopic, 1, 1))
+		assert.NoError(t, createTopic(admin, destinationTopic, 1, 1))
+		jsonMessages, err := createInitialMessages(sourceTopic, 0, decoder.JSON, 100)
+		assert.NoError(t, err)
+		assert.NoError(t, produceMessages(client, jsonMessages))
+
+		dataDir := t.TempDir()
+		jobManager, err := job.NewManager(
+			context.Background(),
+			job.NewFileStore(filepath.Join(dataDir, "jobs.json")),
+			job.Config{MaxConcurrent: 1, HistorySize: 10, FileDir: filepath.Join(dataDir, "files")},
+			logger,
+		)
+		assert.NoError(t, err)
+		copyJob := jobManager.Submit("copy", "Copy for test", func(ctx context.Context, run *job.Run) error {
+			_, err := kafkaService.CopyMessages(
+				ctx,
+				model.CopySource{
+					Topic:  sourceTopic,
+					Filter: &model.MessageFilter{ValueContains: "Test message 1"},
+				},
+				model.CopyDestination{Cluster: "mirror", Topic: destinationTopic},
+				model.CopyOptions{
+					KeepKey:       true,
+					KeepPartition: true,
+					ValueTransform: []model.TransformStep{
+						{Op: model.TransformSet, Path: "$.copied", Value: "true"},
+					},
+				},
+				func(progress model.CopyProgress) {
+					run.Report(map[string]int64{"copied": progress.Copied})
+				},
+			)
+			return err
+		})
+
+		jobManager.Wait()
+		finishedJob, _ := jobManager.Get(copyJob.ID)
+		assert.Equal(t, job.StatusSucceeded, finishedJob.Status)
+		// "Test message 1" and "Test message 10" through "Test message 19"
+		assert.Equal(t, int64(11), finishedJob.Progress["copied"])
+
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
+			destinationTopic,
+			getPartitionInput(0, 0, 10),
+		)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 11)
+		for _, message := range messages {
+			assert.NotNil(t, message.ValueJsonPayload)
+			assert.True(t, *message.ValueJsonPayload.ObjectVal["copied"].BoolVal)
+			assert.Contains(t, message.Key, "Test message 1")
+		}
+
+		teardown(t, kafkaService, admin, []string{sourceTopic, destinationTopic})
+	})
+}
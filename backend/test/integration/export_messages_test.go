@@ -0,0 +1,152 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/export"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestExportMessages(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	t.Run("Should export filtered json encoded messages as JSONL, CSV and Avro", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-export-json"
+		err := createTopic(admin, topic, 1, 1)
+		assert.NoError(t, err)
+		jsonMessages, err := createInitialMessages(topic, 0, decoder.JSON, 100)
+		assert.NoError(t, err)
+		err = produceMessages(client, jsonMessages)
+		assert.NoError(t, err)
+
+		filter := &model.MessageFilter{ValuePath: "$.value", ValueEquals: "Test message 42"}
+		exportTopic := func(format export.Format, options export.Options) []byte {
+			var buffer bytes.Buffer
+			writer, err := export.NewWriter(format, &buffer, options)
+			assert.NoError(t, err)
+			err = kafkaService.StreamMessagesForTopic(
+				context.Background(),
+				topic,
+				getPartitionInput(0, 0, 99),
+				filter,
+				writer.Write,
+			)
+			assert.NoError(t, err)
+			assert.NoError(t, writer.Close())
+			return buffer.Bytes()
+		}
+
+		jsonl := exportTopic(export.FormatJSONL, export.Options{IncludeRawBytes: true})
+		scanner := bufio.NewScanner(bytes.NewReader(jsonl))
+		var lines []export.JSONLRecord
+		for scanner.Scan() {
+			var line export.JSONLRecord
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		assert.Len(t, lines, 1)
+		assert.Equal(t, int64(42), lines[0].Offset)
+		assert.Equal(t, map[string]interface{}{"value": "Test message 42"}, lines[0].ValueJSON)
+		assert.NotNil(t, lines[0].ValueBase64)
+
+		csvExport := exportTopic(export.FormatCSV, export.Options{
+			Columns: []export.Column{{Header: "offset", Field: "offset"}, {Header: "value", Field: "$.value"}},
+		})
+		rows, err := csv.NewReader(bytes.NewReader(csvExport)).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"offset", "value"}, {"42", "Test message 42"}}, rows)
+
+		avroExport := exportTopic(export.FormatAvro, export.Options{})
+		reader, err := goavro.NewOCFReader(bytes.NewReader(avroExport))
+		assert.NoError(t, err)
+		var records []interface{}
+		for reader.Scan() {
+			record, err := reader.Read()
+			assert.NoError(t, err)
+			records = append(records, record)
+		}
+		assert.Len(t, records, 1)
+		encodedVal, err := jsonMessages[42].Value.Encode()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"bytes": encodedVal}, records[0].(map[string]interface{})["value"])
+
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+
+	t.Run("Should export the decoded values of keyless records and tombstones", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-export-null"
+		assert.NoError(t, createTopic(admin, topic, 1, 1))
+		err := produceMessages(client, []*sarama.ProducerMessage{
+			{Topic: topic, Value: sarama.StringEncoder(`{"value":"keyless"}`)},
+			{Topic: topic, Key: sarama.StringEncoder("deleted"), Value: nil},
+		})
+		assert.NoError(t, err)
+
+		var buffer bytes.Buffer
+		writer, err := export.NewWriter(export.FormatJSONL, &buffer, export.Options{})
+		assert.NoError(t, err)
+		err = kafkaService.StreamMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, 1),
+			nil,
+			writer.Write,
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		scanner := bufio.NewScanner(&buffer)
+		var lines []export.JSONLRecord
+		for scanner.Scan() {
+			var line export.JSONLRecord
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		assert.Len(t, lines, 2)
+		// Raw bytes are only exported for records that couldn't be decoded
+		assert.Nil(t, lines[0].Key)
+		assert.Nil(t, lines[0].KeyBase64)
+		assert.Equal(t, model.NullPayload, lines[0].KeyPayloadType)
+		assert.Equal(t, model.JSONPayload, lines[0].ValuePayloadType)
+		assert.Equal(t, map[string]interface{}{"value": "keyless"}, lines[0].ValueJSON)
+
+		assert.Equal(t, "deleted", *lines[1].Key)
+		assert.Nil(t, lines[1].Value)
+		assert.Nil(t, lines[1].ValueBase64)
+		assert.Equal(t, model.NullPayload, lines[1].ValuePayloadType)
+
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+}
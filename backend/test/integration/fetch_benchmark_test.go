@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+)
+
+// The number of partitions the consumer-per-partition path consumed at once
+const partitionConsumerWorkerCount = 10
+
+// BenchmarkGetLastMessagesForTopic measures a UI refresh of the last messages of every partition of a topic, with
+// direct Fetch requests as the service makes them and with a consumer per partition as the service used to.
+func BenchmarkGetLastMessagesForTopic(b *testing.B) {
+	const messagesPerPartition = 20
+	logger := zap.NewNop()
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	messageDecoder := decoder.NewMessageDecoder(avroService, logger)
+
+	for _, numPartitions := range []int32{1, 10, 100} {
+		b.Run(fmt.Sprintf("partitions=%d", numPartitions), func(b *testing.B) {
+			assertPrerequisites(b)
+			config := sarama.NewConfig()
+			config.Version = sarama.V3_6_0_0
+			config.Producer.Return.Successes = true
+			config.Producer.Partitioner = sarama.NewManualPartitioner
+
+			kafkaService := kafka.NewKafkaService(messageDecoder, logger)
+			client, admin := getClientAndAdmin(b, bootstrapAddress, config)
+			initializeKafkaService(b, kafkaService, bootstrapAddress, config)
+
+			topic := fmt.Sprintf("benchmark-topic-fetch-%d", numPartitions)
+			if err := createTopic(admin, topic, numPartitions, 1); err != nil {
+				b.Fatalf("Failed to create topic: %s", err)
+			}
+			partitionInput := model.PartitionInput{PartitionDetailsMap: make(map[int32]model.PartitionDetails)}
+			for partition := int32(0); partition < numPartitions; partition++ {
+				messages, err := createInitialMessages(topic, partition, decoder.JSON, messagesPerPartition)
+				if err != nil {
+					b.Fatalf("Failed to create messages: %s", err)
+				}
+				if err := produceMessages(client, messages); err != nil {
+					b.Fatalf("Failed to produce messages: %s", err)
+				}
+				partitionInput.PartitionDetailsMap[partition] = model.PartitionDetails{
+					StartOffset: -messagesPerPartition,
+					EndOffset:   -1,
+				}
+			}
+			expectedMessages := int(numPartitions) * messagesPerPartition
+
+			b.Run("path=fetch", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					messages, err := kafkaService.GetLastMessagesForTopic(context.Background(), topic, partitionInput)
+					if err != nil {
+						b.Fatalf("Failed to fetch messages: %s", err)
+					}
+					if len(messages) != expectedMessages {
+						b.Fatalf("Expected %d messages, got %d", expectedMessages, len(messages))
+					}
+				}
+			})
+
+			b.Run("path=consumer-per-partition", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					messages, err := getLastMessagesWithPartitionConsumers(
+						client,
+						messageDecoder,
+						topic,
+						numPartitions,
+						messagesPerPartition,
+					)
+					if err != nil {
+						b.Fatalf("Failed to consume messages: %s", err)
+					}
+					if messages != expectedMessages {
+						b.Fatalf("Expected %d messages, got %d", expectedMessages, messages)
+					}
+				}
+			})
+
+			teardown(b, kafkaService, admin, []string{topic})
+		})
+	}
+}
+
+// getLastMessagesWithPartitionConsumers reads and decodes the last messages of every partition of topic the way the
+// service did before it made Fetch requests itself: a pool of workers that each open a consumer per partition. It
+// returns how many messages were read.
+func getLastMessagesWithPartitionConsumers(
+	client sarama.Client,
+	messageDecoder *decoder.MessageDecoder,
+	topic string,
+	numPartitions int32,
+	messagesPerPartition int64,
+) (int, error) {
+	partitions := make(chan int32, numPartitions)
+	for partition := int32(0); partition < numPartitions; partition++ {
+		partitions <- partition
+	}
+	close(partitions)
+
+	var mutex sync.Mutex
+	var total int
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < min(partitionConsumerWorkerCount, int(numPartitions)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for partition := range partitions {
+				messages, err := consumeLastMessages(client, messageDecoder, topic, partition, messagesPerPartition)
+				mutex.Lock()
+				total += messages
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return total, firstErr
+}
+
+func consumeLastMessages(
+	client sarama.Client,
+	messageDecoder *decoder.MessageDecoder,
+	topic string,
+	partition int32,
+	messagesPerPartition int64,
+) (int, error) {
+	newestOffset, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get newest offset: %w", err)
+	}
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create consumer: %w", err)
+	}
+	defer consumer.Close()
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, newestOffset-messagesPerPartition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create partition consumer: %w", err)
+	}
+	defer partitionConsumer.Close()
+
+	for i := int64(0); i < messagesPerPartition; i++ {
+		message, ok := <-partitionConsumer.Messages()
+		if !ok {
+			return int(i), fmt.Errorf("partition consumer of partition %d closed", partition)
+		}
+		if _, err := messageDecoder.DecodeKeyAndValue(topic, message.Key, message.Value); err != nil {
+			return int(i), fmt.Errorf("failed to decode message: %w", err)
+		}
+	}
+	return int(messagesPerPartition), nil
+}
@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// Fetches used to wait for a message per offset, and so hung until a timeout on ranges with offsets but no messages
+const maxFetchDuration = 2 * time.Second
+
+func TestFetchCompletion(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	t.Run("Should complete fetches of compacted topics right away", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-fetch-compacted"
+		err := admin.CreateTopic(topic, &sarama.TopicDetail{
+			NumPartitions:     1,
+			ReplicationFactor: 1,
+			ConfigEntries: map[string]*string{
+				"cleanup.policy":            stringPointer("compact"),
+				"segment.ms":                stringPointer("100"),
+				"min.cleanable.dirty.ratio": stringPointer("0.01"),
+				"delete.retention.ms":       stringPointer("100"),
+			},
+		}, false)
+		assert.NoError(t, err)
+		assert.NoError(t, produceMessages(client, createKeyedMessages(topic, 50, 5)))
+		// Only closed segments are compacted, and a segment is closed by the first record after segment.ms
+		time.Sleep(200 * time.Millisecond)
+		assert.NoError(t, produceMessages(client, createKeyedMessages(topic, 1, 1)))
+
+		var topicMessages *model.TopicMessages
+		deadline := time.Now().Add(30 * time.Second)
+		for {
+			start := time.Now()
+			topicMessages, err = kafkaService.GetMessagesForTopic(
+				context.Background(),
+				topic,
+				getPartitionInput(0, 0, -1),
+				model.FetchOptions{},
+			)
+			assert.NoError(t, err)
+			assert.Less(t, time.Since(start), maxFetchDuration)
+			if len(topicMessages.Messages) < 51 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+
+		assert.Less(t, len(topicMessages.Messages), 51, "the topic was not compacted in time")
+		assert.Len(t, topicMessages.Partitions, 1)
+		coverage := topicMessages.Partitions[0]
+		assert.True(t, coverage.Complete)
+		assert.Equal(t, int64(0), coverage.StartOffset)
+		assert.Equal(t, int64(50), coverage.EndOffset)
+		assert.Equal(t, int64(51), coverage.NextOffset)
+		assert.Equal(t, int64(51), coverage.HighWatermark)
+		latestValues := make(map[string]string)
+		for _, message := range topicMessages.Messages {
+			latestValues[message.Key] = message.Value
+		}
+		assert.Equal(t, `{"value":"value 49"}`, latestValues[`{"key":"key 4"}`])
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+
+	t.Run("Should complete fetches of ranges ending in transaction markers right away", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		_, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-fetch-transactional"
+		assert.NoError(t, createTopic(admin, topic, 1, 1))
+		producer := newTransactionalProducer(t, "test-fetch-completion")
+		defer producer.Close()
+		assert.NoError(t, produceTransaction(producer, createKeyedMessages(topic, 10, 10), true))
+		assert.NoError(t, produceTransaction(producer, createKeyedMessages(topic, 5, 5), false))
+
+		start := time.Now()
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, -1),
+			model.FetchOptions{},
+		)
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), maxFetchDuration)
+
+		// Ten committed records, a commit marker, five aborted records and an abort marker
+		assert.Len(t, topicMessages.Messages, 15)
+		assert.Len(t, topicMessages.Partitions, 1)
+		coverage := topicMessages.Partitions[0]
+		assert.True(t, coverage.Complete)
+		assert.Equal(t, int64(16), coverage.EndOffset)
+		assert.Equal(t, int64(17), coverage.NextOffset)
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+// createKeyedMessages creates numMessages JSON messages that cycle through numKeys keys.
+func createKeyedMessages(topic string, numMessages int, numKeys int) []*sarama.ProducerMessage {
+	messages := make([]*sarama.ProducerMessage, numMessages)
+	for i := 0; i < numMessages; i++ {
+		messages[i] = &sarama.ProducerMessage{
+			Topic: topic,
+			Key:   sarama.StringEncoder(fmt.Sprintf(`{"key":"key %d"}`, i%numKeys)),
+			Value: sarama.StringEncoder(fmt.Sprintf(`{"value":"value %d"}`, i)),
+		}
+	}
+	return messages
+}
+
+func newTransactionalProducer(t *testing.T, transactionalID string) sarama.SyncProducer {
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Idempotent = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Transaction.ID = transactionalID
+	config.Net.MaxOpenRequests = 1
+	producer, err := sarama.NewSyncProducer([]string{bootstrapAddress}, config)
+	if err != nil {
+		t.Fatalf("Failed to create transactional producer: %s", err)
+	}
+	return producer
+}
+
+func produceTransaction(producer sarama.SyncProducer, messages []*sarama.ProducerMessage, commit bool) error {
+	if err := producer.BeginTxn(); err != nil {
+		return err
+	}
+	if err := producer.SendMessages(messages); err != nil {
+		return err
+	}
+	if commit {
+		return producer.CommitTxn()
+	}
+	return producer.AbortTxn()
+}
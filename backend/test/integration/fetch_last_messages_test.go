@@ -1,15 +1,23 @@
 package integration
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
 	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
 	"github.com/IBM/sarama"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.uber.org/zap"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -19,7 +27,8 @@ func TestFetchLastMessages(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create logger: %s", err)
 	}
-	kafkaService := kafka.NewKafkaService(logger)
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
 
 	t.Run("Should be able to fetch last 100 plaintext encoded messages", func(t *testing.T) {
 		assertPrerequisites(t)
@@ -41,13 +50,10 @@ func TestFetchLastMessages(t *testing.T) {
 		err = produceMessages(client, plaintextMessages)
 		assert.NoError(t, err)
 
-		messages, err := kafkaService.getLastMessagesForPartition(
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
 			topic,
-			0,
-			100,
-			100,
-			decoder.PlainText,
-			decoder.PlainText,
+			getPartitionInput(0, 0, 99),
 		)
 		assert.NoError(t, err)
 		assert.Len(t, messages, 100)
@@ -90,13 +96,10 @@ func TestFetchLastMessages(t *testing.T) {
 		err = produceMessages(client, base64Messages)
 		assert.NoError(t, err)
 
-		messages, err := kafkaService.getLastMessagesForPartition(
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
 			topic,
-			0,
-			100,
-			100,
-			decoder.Base64,
-			decoder.Base64,
+			getPartitionInput(0, 0, 99),
 		)
 		assert.NoError(t, err)
 		assert.Len(t, messages, 100)
@@ -143,13 +146,10 @@ func TestFetchLastMessages(t *testing.T) {
 		err = produceMessages(client, jsonMessages)
 		assert.NoError(t, err)
 
-		messages, err := kafkaService.getLastMessagesForPartition(
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
 			topic,
-			0,
-			100,
-			100,
-			decoder.JSON,
-			decoder.JSON,
+			getPartitionInput(0, 0, 99),
 		)
 		assert.NoError(t, err)
 		assert.Len(t, messages, 100)
@@ -178,6 +178,152 @@ func TestFetchLastMessages(t *testing.T) {
 		}
 		teardown(t, kafkaService, admin, []string{topic})
 	})
+
+	t.Run("Should be able to fetch last 100 MessagePack, CBOR and BSON encoded messages", func(t *testing.T) {
+		for _, encoding := range []decoder.Encoding{decoder.MessagePack, decoder.CBOR, decoder.BSON} {
+			assertPrerequisites(t)
+			config := sarama.NewConfig()
+			config.Version = sarama.V3_6_0_0
+			config.Producer.Return.Successes = true
+
+			client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+			initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+			topic := "test-topic-fetch-" + string(encoding)
+			numPartitions := int32(1)
+			replicationFactor := int16(1)
+
+			err := createTopic(admin, topic, numPartitions, replicationFactor)
+			assert.NoError(t, err)
+			binaryMessages, err := createInitialMessages(topic, 0, encoding, 100)
+			assert.NoError(t, err)
+			err = produceMessages(client, binaryMessages)
+			assert.NoError(t, err)
+
+			messages, err := kafkaService.GetLastMessagesForTopic(
+				context.Background(),
+				topic,
+				getPartitionInput(0, 0, 99),
+			)
+			assert.NoError(t, err)
+			assert.Len(t, messages, 100)
+			for i, message := range messages {
+				messageString := "Test message " + strconv.FormatInt(int64(i), 10)
+
+				assert.Equal(t, int64(i), message.Offset)
+				assert.Equal(t, model.JSONPayload, message.KeyPayloadType, string(encoding))
+				assert.Equal(t, model.JSONPayload, message.ValuePayloadType, string(encoding))
+
+				assert.NotNil(t, message.KeyJsonPayload)
+				assert.NotNil(t, message.ValueJsonPayload)
+				assert.Equal(t, messageString, *message.KeyJsonPayload.ObjectVal["key"].StringVal)
+				assert.Equal(t, messageString, *message.ValueJsonPayload.ObjectVal["value"].StringVal)
+			}
+			teardown(t, kafkaService, admin, []string{topic})
+		}
+	})
+
+	t.Run("Should strip gzip compression from message values before decoding", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-fetch-gzip"
+		numPartitions := int32(1)
+		replicationFactor := int16(1)
+
+		err := createTopic(admin, topic, numPartitions, replicationFactor)
+		assert.NoError(t, err)
+		jsonMessages, err := createInitialMessages(topic, 0, decoder.JSON, 10)
+		assert.NoError(t, err)
+		for _, message := range jsonMessages {
+			encodedValue, err := message.Value.Encode()
+			assert.NoError(t, err)
+			var compressed bytes.Buffer
+			writer := gzip.NewWriter(&compressed)
+			_, err = writer.Write(encodedValue)
+			assert.NoError(t, err)
+			assert.NoError(t, writer.Close())
+			message.Value = sarama.ByteEncoder(compressed.Bytes())
+		}
+		err = produceMessages(client, jsonMessages)
+		assert.NoError(t, err)
+
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, 9),
+		)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 10)
+		for i, message := range messages {
+			messageString := "Test message " + strconv.FormatInt(int64(i), 10)
+
+			assert.Equal(t, model.JSONPayload, message.ValuePayloadType)
+			assert.Equal(t, []model.CompressionType{model.CompressionGzip}, message.ValueCompression)
+			assert.Empty(t, message.KeyCompression)
+			assert.NotNil(t, message.ValueJsonPayload)
+			assert.Equal(t, messageString, *message.ValueJsonPayload.ObjectVal["value"].StringVal)
+		}
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+
+	t.Run("Should decode message values that start with a gzip magic number but aren't compressed", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-fetch-gzip-lookalike"
+		numPartitions := int32(1)
+		replicationFactor := int16(1)
+
+		err := createTopic(admin, topic, numPartitions, replicationFactor)
+		assert.NoError(t, err)
+		// A BSON document of 0x8b1f bytes starts with the length prefix 1f 8b, which is also the gzip magic number
+		empty, err := bson.Marshal(bson.M{"value": ""})
+		assert.NoError(t, err)
+		value, err := bson.Marshal(bson.M{"value": strings.Repeat("a", 0x8b1f-len(empty))})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0x1f, 0x8b}, value[:2])
+		err = produceMessages(client, []*sarama.ProducerMessage{
+			{Topic: topic, Key: sarama.StringEncoder("lookalike"), Value: sarama.ByteEncoder(value)},
+		})
+		assert.NoError(t, err)
+
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, 0),
+		)
+		assert.NoError(t, err)
+		if assert.Len(t, messages, 1) {
+			message := messages[0]
+			assert.Equal(t, model.JSONPayload, message.ValuePayloadType)
+			assert.Empty(t, message.ValueCompression)
+			assert.NotNil(t, message.ValueJsonPayload)
+			assert.Len(t, *message.ValueJsonPayload.ObjectVal["value"].StringVal, 0x8b1f-len(empty))
+		}
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+}
+
+func getPartitionInput(partition int32, startOffset int64, endOffset int64) model.PartitionInput {
+	return model.PartitionInput{
+		PartitionDetailsMap: map[int32]model.PartitionDetails{
+			partition: {
+				StartOffset: startOffset,
+				EndOffset:   endOffset,
+			},
+		},
+	}
 }
 
 func createTopic(
@@ -230,6 +376,33 @@ func createInitialMessages(
 		case decoder.Base64:
 			encodedKey = []byte(base64.StdEncoding.EncodeToString([]byte(messageString)))
 			encodedValue = []byte(base64.StdEncoding.EncodeToString([]byte(messageString)))
+		case decoder.MessagePack:
+			encodedKey, err = msgpack.Marshal(map[string]string{"key": messageString})
+			if err != nil {
+				return nil, err
+			}
+			encodedValue, err = msgpack.Marshal(map[string]string{"value": messageString})
+			if err != nil {
+				return nil, err
+			}
+		case decoder.CBOR:
+			encodedKey, err = cbor.Marshal(map[string]string{"key": messageString})
+			if err != nil {
+				return nil, err
+			}
+			encodedValue, err = cbor.Marshal(map[string]string{"value": messageString})
+			if err != nil {
+				return nil, err
+			}
+		case decoder.BSON:
+			encodedKey, err = bson.Marshal(KeyJSON{Key: messageString})
+			if err != nil {
+				return nil, err
+			}
+			encodedValue, err = bson.Marshal(ValueJSON{Value: messageString})
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		message := &sarama.ProducerMessage{
@@ -264,9 +437,9 @@ func produceMessages(
 }
 
 type KeyJSON struct {
-	Key string `json:"key"`
+	Key string `json:"key" bson:"key"`
 }
 
 type ValueJSON struct {
-	Value string `json:"value"`
+	Value string `json:"value" bson:"value"`
 }
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestGoroutineLeaks(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-topic-goroutine-leaks"
+	numPartitions := int32(20)
+	assert.NoError(t, createTopic(admin, topic, numPartitions, 1))
+	allPartitions := model.PartitionInput{PartitionDetailsMap: make(map[int32]model.PartitionDetails)}
+	for partition := int32(0); partition < numPartitions; partition++ {
+		messages, err := createInitialMessages(topic, partition, decoder.JSON, 500)
+		assert.NoError(t, err)
+		assert.NoError(t, produceMessages(client, messages))
+		allPartitions.PartitionDetailsMap[partition] = model.PartitionDetails{StartOffset: 0, EndOffset: -1}
+	}
+
+	// A full fetch opens the connections to the brokers, which the client keeps for as long as it lives
+	_, err = kafkaService.GetLastMessagesForTopic(context.Background(), topic, allPartitions)
+	assert.NoError(t, err)
+	background := goleak.IgnoreCurrent()
+
+	t.Run("Should not leak goroutines when a fetch is canceled midway", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := kafkaService.GetLastMessagesForTopic(ctx, topic, allPartitions)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		goleak.VerifyNone(t, background)
+	})
+
+	t.Run("Should not start fetching with a canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := kafkaService.GetLastMessagesForTopic(ctx, topic, allPartitions)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = kafkaService.GetTopics(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = kafkaService.GetConsumerGroupsDetailsListeningToTopic(ctx, topic)
+		assert.ErrorIs(t, err, context.Canceled)
+		goleak.VerifyNone(t, background)
+	})
+
+	t.Run("Should not leak goroutines when a stream is canceled or its handler fails", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := kafkaService.StreamMessagesForTopic(ctx, topic, allPartitions, nil, func(record *model.Record) error {
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		stop := errors.New("stop")
+		err = kafkaService.StreamMessagesForTopic(
+			context.Background(),
+			topic,
+			allPartitions,
+			nil,
+			func(record *model.Record) error {
+				return stop
+			},
+		)
+		assert.ErrorIs(t, err, stop)
+		goleak.VerifyNone(t, background)
+	})
+
+	teardown(t, kafkaService, admin, []string{topic})
+}
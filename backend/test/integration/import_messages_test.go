@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/export"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"testing"
+)
+
+func TestImportMessages(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	t.Run("Should replay an export into another topic and skip already imported records on resume", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		sourceTopic := "test-topic-import-source"
+		destinationTopic := "test-topic-import-destination"
+		assert.NoError(t, createTopic(admin, sourceTopic, 1, 1))
+		assert.NoError(t, createTopic(admin, destinationTopic, 1, 1))
+		jsonMessages, err := createInitialMessages(sourceTopic, 0, decoder.JSON, 100)
+		assert.NoError(t, err)
+		assert.NoError(t, produceMessages(client, jsonMessages))
+
+		var exported bytes.Buffer
+		writer, err := export.NewWriter(export.FormatJSONL, &exported, export.Options{IncludeRawBytes: true})
+		assert.NoError(t, err)
+		err = kafkaService.StreamMessagesForTopic(
+			context.Background(),
+			sourceTopic,
+			getPartitionInput(0, 0, 99),
+			nil,
+			writer.Write,
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		options := model.ImportOptions{
+			Partitioning: model.ImportPartitioningKeep,
+			Timestamps:   model.ImportTimestampsKeep,
+		}
+		result, err := kafkaService.ImportMessages(
+			context.Background(),
+			destinationTopic,
+			export.NewJSONLReader(bytes.NewReader(exported.Bytes())),
+			options,
+			nil,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), result.Read)
+		assert.Equal(t, int64(100), result.Produced)
+		assert.NotEmpty(t, result.ImportID)
+
+		options.ImportID = result.ImportID
+		resumed, err := kafkaService.ImportMessages(
+			context.Background(),
+			destinationTopic,
+			export.NewJSONLReader(bytes.NewReader(exported.Bytes())),
+			options,
+			nil,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), resumed.Read)
+		assert.Equal(t, int64(0), resumed.Produced)
+		assert.Equal(t, int64(100), resumed.Skipped)
+
+		messages, err := kafkaService.GetLastMessagesForTopic(
+			context.Background(),
+			destinationTopic,
+			getPartitionInput(0, 0, 199),
+		)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 100)
+		for i, message := range messages {
+			encodedVal, err := jsonMessages[i].Value.Encode()
+			assert.NoError(t, err)
+			assert.Equal(t, string(encodedVal), message.Value)
+			assert.Equal(t, jsonMessages[i].Timestamp.UnixMilli(), message.Timestamp.UnixMilli())
+		}
+
+		teardown(t, kafkaService, admin, []string{sourceTopic, destinationTopic})
+	})
+
+	t.Run("Should partition records by key the way the Java producer does", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		_, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-import-by-key"
+		assert.NoError(t, createTopic(admin, topic, 3, 1))
+		// The partitions the Java producer picks for these keys out of 3, from the murmur2 test vectors of Kafka
+		expectedPartitions := map[string]int32{
+			"21":                         0,
+			"foobar":                     0,
+			"a-little-bit-long-string":   2,
+			"a-little-bit-longer-string": 2,
+			"abc":                        0,
+		}
+		var records []*model.Record
+		for key := range expectedPartitions {
+			records = append(records, &model.Record{Key: []byte(key), Value: []byte(`{}`)})
+		}
+
+		result, err := kafkaService.ImportMessages(
+			context.Background(),
+			topic,
+			&recordSliceReader{records: records},
+			model.ImportOptions{Partitioning: model.ImportPartitioningKey, Timestamps: model.ImportTimestampsNow},
+			nil,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(records)), result.Produced)
+
+		actualPartitions := make(map[string]int32)
+		for partition := int32(0); partition < 3; partition++ {
+			topicMessages, err := kafkaService.GetMessagesForTopic(
+				context.Background(),
+				topic,
+				getPartitionInput(partition, 0, -1),
+				model.FetchOptions{},
+			)
+			if !assert.NoError(t, err) {
+				continue
+			}
+			for _, message := range topicMessages.Messages {
+				actualPartitions[message.Key] = partition
+			}
+		}
+		assert.Equal(t, expectedPartitions, actualPartitions)
+
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+}
+
+// recordSliceReader reads records from a slice, in order.
+type recordSliceReader struct {
+	records []*model.Record
+}
+
+func (r *recordSliceReader) Read() (*model.Record, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}
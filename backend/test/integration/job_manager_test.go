@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"github.com/Avi18971911/kafka-window/backend/internal/job"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"path/filepath"
+	"testing"
+)
+
+func TestJobManager(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+
+	newManager := func(dataDir string, maxConcurrent int) *job.Manager {
+		jobManager, err := job.NewManager(
+			context.Background(),
+			job.NewFileStore(filepath.Join(dataDir, "jobs.json")),
+			job.Config{MaxConcurrent: maxConcurrent, HistorySize: 2, FileDir: filepath.Join(dataDir, "files")},
+			logger,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create job manager: %s", err)
+		}
+		return jobManager
+	}
+
+	t.Run("Should queue jobs beyond the concurrency limit and cancel queued jobs right away", func(t *testing.T) {
+		jobManager := newManager(t.TempDir(), 1)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		running := jobManager.Submit("test", "Blocks until released", func(ctx context.Context, run *job.Run) error {
+			close(started)
+			run.Report(map[string]int64{"step": 1})
+			<-release
+			return nil
+		})
+		<-started
+		queued := jobManager.Submit("test", "Waits for a slot", func(ctx context.Context, run *job.Run) error {
+			return nil
+		})
+
+		status, _ := jobManager.Get(queued.ID)
+		assert.Equal(t, job.StatusQueued, status.Status)
+		jobManager.Cancel(queued.ID)
+		close(release)
+		jobManager.Wait()
+
+		status, _ = jobManager.Get(queued.ID)
+		assert.Equal(t, job.StatusCanceled, status.Status)
+		assert.Nil(t, status.StartedAt)
+		status, _ = jobManager.Get(running.ID)
+		assert.Equal(t, job.StatusSucceeded, status.Status)
+		assert.Equal(t, int64(1), status.Progress["step"])
+	})
+
+	t.Run("Should cancel running jobs and record failures", func(t *testing.T) {
+		jobManager := newManager(t.TempDir(), 2)
+		started := make(chan struct{})
+		running := jobManager.Submit("test", "Runs until canceled", func(ctx context.Context, run *job.Run) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		failing := jobManager.Submit("test", "Fails", func(ctx context.Context, run *job.Run) error {
+			return errors.New("broken")
+		})
+		<-started
+		jobManager.Cancel(running.ID)
+		jobManager.Wait()
+
+		status, _ := jobManager.Get(running.ID)
+		assert.Equal(t, job.StatusCanceled, status.Status)
+		status, _ = jobManager.Get(failing.ID)
+		assert.Equal(t, job.StatusFailed, status.Status)
+		assert.Equal(t, "broken", status.Error)
+	})
+
+	t.Run("Should persist a bounded history and fail jobs interrupted by a restart", func(t *testing.T) {
+		dataDir := t.TempDir()
+		store := job.NewFileStore(filepath.Join(dataDir, "jobs.json"))
+		jobManager := newManager(dataDir, 1)
+		var ids []string
+		for i := 0; i < 3; i++ {
+			submitted := jobManager.Submit("test", "Succeeds", func(ctx context.Context, run *job.Run) error {
+				return nil
+			})
+			ids = append(ids, submitted.ID)
+			jobManager.Wait()
+		}
+		// Simulate a job that was running when the server stopped
+		history, err := store.Load()
+		assert.NoError(t, err)
+		history = append(history, job.Job{ID: "interrupted", Kind: "test", Status: job.StatusRunning})
+		assert.NoError(t, store.Save(history))
+
+		restarted := newManager(dataDir, 1)
+		jobs := restarted.List("test", "")
+		assert.Len(t, jobs, 2)
+		assert.Equal(t, "interrupted", jobs[0].ID)
+		assert.Equal(t, job.StatusFailed, jobs[0].Status)
+		assert.Equal(t, ids[2], jobs[1].ID)
+		_, ok := restarted.Get(ids[0])
+		assert.False(t, ok)
+	})
+}
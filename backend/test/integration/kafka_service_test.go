@@ -2,6 +2,8 @@ package integration
 
 import (
 	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
 	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
 	"github.com/IBM/sarama"
@@ -17,7 +19,8 @@ func TestKafkaService(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create logger: %s", err)
 	}
-	kafkaService := kafka.NewKafkaService(logger)
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
 
 	t.Run("Should be able to retrieve all topics", func(t *testing.T) {
 		assertPrerequisites(t)
@@ -42,7 +45,7 @@ func TestKafkaService(t *testing.T) {
 		for {
 			select {
 			case <-ticker.C:
-				topics, err = kafkaService.GetTopics()
+				topics, err = kafkaService.GetTopics(context.Background())
 				assert.NoError(t, err)
 
 				if len(topics) == len(topicList) {
@@ -60,6 +63,67 @@ func TestKafkaService(t *testing.T) {
 		teardown(t, kafkaService, admin, topicList)
 	})
 
+	t.Run("Should tag Kafka Connect and Kafka Streams internal topics with their kind", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		_, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		compact := "compact"
+		deletePolicy := "delete"
+		topicConfigs := map[string]*string{
+			"connect-offsets":                         &compact,
+			"word-count-counts-store-changelog":       &compact,
+			"word-count-KSTREAM-MAP-0000-repartition": &deletePolicy,
+			"orders-changelog":                        &deletePolicy,
+		}
+		expectedKinds := map[string]model.TopicKind{
+			"connect-offsets":                         model.TopicKindConnectInternal,
+			"word-count-counts-store-changelog":       model.TopicKindStreamsChangelog,
+			"word-count-KSTREAM-MAP-0000-repartition": model.TopicKindStreamsRepartition,
+			"orders-changelog":                        model.TopicKindStandard,
+		}
+		topicList := make([]string, 0, len(topicConfigs))
+		for topic, cleanupPolicy := range topicConfigs {
+			err := admin.CreateTopic(topic, &sarama.TopicDetail{
+				NumPartitions:     1,
+				ReplicationFactor: 1,
+				ConfigEntries:     map[string]*string{"cleanup.policy": cleanupPolicy},
+			}, false)
+			assert.NoError(t, err)
+			topicList = append(topicList, topic)
+		}
+
+		timeout := 10 * time.Second
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		timeoutTimer := time.NewTimer(timeout)
+		defer timeoutTimer.Stop()
+
+		var topics []model.TopicDetails
+
+	WaitLoop:
+		for {
+			select {
+			case <-ticker.C:
+				topics, err = kafkaService.GetTopics(context.Background())
+				assert.NoError(t, err)
+
+				if len(topics) == len(topicList) {
+					break WaitLoop
+				}
+			case <-timeoutTimer.C:
+				t.Fatalf("Timed out waiting for topics to appear")
+			}
+		}
+		for _, topic := range topics {
+			assert.Equal(t, expectedKinds[topic.Name], topic.Kind, topic.Name)
+		}
+		teardown(t, kafkaService, admin, topicList)
+	})
+
 	t.Run("Should be able to retrieve all consumer groups", func(t *testing.T) {
 		assertPrerequisites(t)
 		config := sarama.NewConfig()
@@ -86,7 +150,7 @@ func TestKafkaService(t *testing.T) {
 		for {
 			select {
 			case <-ticker.C:
-				consumers, err = kafkaService.GetConsumerGroups()
+				consumers, err = kafkaService.GetConsumerGroups(context.Background())
 				assert.NoError(t, err)
 
 				if len(consumers) == len(consumerList) {
@@ -131,7 +195,7 @@ func TestKafkaService(t *testing.T) {
 		for {
 			select {
 			case <-ticker.C:
-				details, err = kafkaService.GetConsumerGroupsDetailsListeningToTopic(topic)
+				details, err = kafkaService.GetConsumerGroupsDetailsListeningToTopic(context.Background(), topic)
 
 				if len(details) == len(consumerList) &&
 					len(details[0].ConsumerDetails) > 0 &&
@@ -166,14 +230,14 @@ func TestKafkaService(t *testing.T) {
 	})
 }
 
-func assertPrerequisites(t *testing.T) {
+func assertPrerequisites(t testing.TB) {
 	if bootstrapAddress == "" {
 		t.Fatal("bootstrapAddress is nil")
 	}
 }
 
 func getClientAndAdmin(
-	t *testing.T,
+	t testing.TB,
 	bootstrapAddress string,
 	config *sarama.Config,
 ) (sarama.Client, sarama.ClusterAdmin) {
@@ -189,12 +253,12 @@ func getClientAndAdmin(
 }
 
 func initializeKafkaService(
-	t *testing.T,
+	t testing.TB,
 	kafkaService *kafka.KafkaService,
 	bootstrapAddress string,
 	config *sarama.Config,
 ) {
-	err := kafkaService.ConnectToCluster([]string{bootstrapAddress}, config)
+	err := kafkaService.ConnectToCluster(context.Background(), []string{bootstrapAddress}, config)
 	if err != nil {
 		t.Fatalf("Failed to connect to cluster: %s", err)
 	}
@@ -247,7 +311,7 @@ func createTopics(admin sarama.ClusterAdmin, topics []string) error {
 	return nil
 }
 
-func teardown(t *testing.T, kafkaService *kafka.KafkaService, admin sarama.ClusterAdmin, topics []string) {
+func teardown(t testing.TB, kafkaService *kafka.KafkaService, admin sarama.ClusterAdmin, topics []string) {
 	for _, topic := range topics {
 		err := admin.DeleteTopic(topic)
 		if err != nil {
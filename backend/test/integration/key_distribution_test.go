@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestKeyDistribution(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-key-distribution"
+	err = admin.CreateTopic(topic, &sarama.TopicDetail{NumPartitions: 3, ReplicationFactor: 1}, false)
+	assert.NoError(t, err)
+	var messages []*sarama.ProducerMessage
+	for partition := int32(0); partition < 3; partition++ {
+		for i := 0; i < 100; i++ {
+			messages = append(messages, createPartitionKeyedMessage(topic, partition, fmt.Sprintf("key-%d-%d", partition, i)))
+		}
+	}
+	// A single key doubles the messages of partition 0
+	for i := 0; i < 500; i++ {
+		messages = append(messages, createPartitionKeyedMessage(topic, 0, "hot-key"))
+	}
+	for i := 0; i < 20; i++ {
+		messages = append(messages, &sarama.ProducerMessage{Topic: topic, Partition: 1, Value: sarama.StringEncoder("x")})
+	}
+	assert.NoError(t, produceMessages(client, messages))
+
+	t.Run("Should flag an imbalanced topic and the key that causes it", func(t *testing.T) {
+		var progress int64
+		distribution, err := kafkaService.AnalyzeKeyDistribution(
+			context.Background(),
+			topic,
+			model.KeyDistributionOptions{SampleSize: 10_000, TopK: 5, ImbalanceThreshold: 1.5},
+			func(sampled int64) {
+				progress = sampled
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(820), distribution.SampledMessages)
+		assert.Equal(t, int64(820), progress)
+		assert.Equal(t, int64(20), distribution.NullKeys)
+		assert.InDelta(t, 301, distribution.DistinctKeys, 5)
+		assert.True(t, distribution.Imbalanced)
+		assert.InDelta(t, 600.0/(820.0/3), distribution.MessageSkew, 0.01)
+
+		assert.Len(t, distribution.Partitions, 3)
+		assert.Equal(t, int64(600), distribution.Partitions[0].Messages)
+		assert.True(t, distribution.Partitions[0].Hot)
+		assert.False(t, distribution.Partitions[1].Hot)
+		assert.Equal(t, int64(20), distribution.Partitions[1].NullKeys)
+		assert.InDelta(t, 100, distribution.Partitions[2].DistinctKeys, 3)
+
+		assert.Len(t, distribution.TopKeys, 5)
+		assert.Equal(t, "hot-key", distribution.TopKeys[0].Key)
+		assert.Equal(t, int64(500), distribution.TopKeys[0].Count)
+		assert.Equal(t, int32(0), distribution.TopKeys[0].Partition)
+		assert.Len(t, distribution.HotKeys, 1)
+		assert.Equal(t, "hot-key", distribution.HotKeys[0].Key)
+	})
+
+	t.Run("Should sample partitions in proportion to their offsets", func(t *testing.T) {
+		distribution, err := kafkaService.AnalyzeKeyDistribution(
+			context.Background(),
+			topic,
+			model.KeyDistributionOptions{SampleSize: 82, TopK: 5, ImbalanceThreshold: 1.5},
+			func(int64) {},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(60), distribution.Partitions[0].SampledMessages)
+		assert.Equal(t, int64(12), distribution.Partitions[1].SampledMessages)
+		assert.Equal(t, int64(10), distribution.Partitions[2].SampledMessages)
+	})
+
+	t.Run("Should return nil for a topic that doesn't exist", func(t *testing.T) {
+		distribution, err := kafkaService.AnalyzeKeyDistribution(
+			context.Background(),
+			"test-key-distribution-missing",
+			model.KeyDistributionOptions{SampleSize: 10, TopK: 5, ImbalanceThreshold: 1.5},
+			func(int64) {},
+		)
+		assert.NoError(t, err)
+		assert.Nil(t, distribution)
+	})
+
+	teardown(t, kafkaService, admin, []string{topic})
+}
+
+func createPartitionKeyedMessage(topic string, partition int32, key string) *sarama.ProducerMessage {
+	return &sarama.ProducerMessage{
+		Topic:     topic,
+		Partition: partition,
+		Key:       sarama.StringEncoder(key),
+		Value:     sarama.StringEncoder(`{"value":"` + key + `"}`),
+	}
+}
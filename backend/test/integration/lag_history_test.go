@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/lag"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLagHistory(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-lag-history"
+	group := "test-lag-history-group"
+	err = admin.CreateTopic(topic, &sarama.TopicDetail{NumPartitions: 2, ReplicationFactor: 1}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, produceMessages(client, createPartitionMessages(topic, 0, 40)))
+	assert.NoError(t, produceMessages(client, createPartitionMessages(topic, 1, 20)))
+
+	offsetManager, err := sarama.NewOffsetManagerFromClient(group, client)
+	assert.NoError(t, err)
+	defer offsetManager.Close()
+	commit := func(partition int32, offset int64) {
+		partitionManager, err := offsetManager.ManagePartition(topic, partition)
+		assert.NoError(t, err)
+		partitionManager.MarkOffset(offset, "")
+		offsetManager.Commit()
+		assert.NoError(t, partitionManager.Close())
+	}
+	commit(0, 10)
+	commit(1, 20)
+
+	store, err := lag.OpenStore(filepath.Join(t.TempDir(), "lag.db"))
+	assert.NoError(t, err)
+	defer store.Close()
+	interval := 500 * time.Millisecond
+	collector := lag.NewCollector(
+		kafkaService,
+		store,
+		lag.Config{Interval: interval, RawRetention: time.Hour},
+		logger,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	go collector.Run(ctx)
+	time.Sleep(interval + interval/2)
+	commit(0, 30)
+	time.Sleep(2 * interval)
+
+	t.Run("Should record the lag and consume rate of a group", func(t *testing.T) {
+		history, err := collector.GetHistory(group, topic, start, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, group, history.Group)
+		assert.Equal(t, int64(0), history.ResolutionSeconds)
+		assert.GreaterOrEqual(t, len(history.Points), 3)
+
+		first := history.Points[0]
+		assert.Equal(t, int64(30), first.CommittedOffset)
+		assert.Equal(t, int64(60), first.HighWatermark)
+		assert.Equal(t, int64(30), first.Lag)
+		assert.Nil(t, first.ConsumeRate)
+
+		last := history.Points[len(history.Points)-1]
+		assert.Equal(t, int64(10), last.Lag)
+		consumed := false
+		for _, point := range history.Points[1:] {
+			assert.NotNil(t, point.ConsumeRate)
+			assert.Equal(t, 0.0, *point.ProduceRate)
+			if *point.ConsumeRate > 0 {
+				consumed = true
+				assert.NotNil(t, point.TimeToCatchUpSeconds)
+				assert.Greater(t, *point.TimeToCatchUpSeconds, 0.0)
+			}
+		}
+		assert.True(t, consumed)
+	})
+
+	t.Run("Should return no points for groups without history", func(t *testing.T) {
+		history, err := collector.GetHistory("unknown-group", topic, start, time.Now())
+		assert.NoError(t, err)
+		assert.Empty(t, history.Points)
+	})
+
+	cancel()
+	teardown(t, kafkaService, admin, []string{topic})
+}
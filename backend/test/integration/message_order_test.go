@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestMessageOrder(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-topic-message-order"
+	assert.NoError(t, createTopic(admin, topic, 3, 1))
+	// Partition 0 was written to an hour ago, while partitions 1 and 2 take turns writing a message a second
+	now := time.Now().Truncate(time.Millisecond)
+	messages := make([]*sarama.ProducerMessage, 0)
+	for i := 0; i < 20; i++ {
+		messages = append(messages, createTimestampedMessage(topic, 0, now.Add(-time.Hour+time.Duration(i)*time.Second)))
+	}
+	for i := 0; i < 10; i++ {
+		messages = append(messages, createTimestampedMessage(topic, int32(1+i%2), now.Add(time.Duration(i-10)*time.Second)))
+	}
+	assert.NoError(t, produceMessages(client, messages))
+	allPartitions := model.PartitionInput{PartitionDetailsMap: map[int32]model.PartitionDetails{
+		0: {StartOffset: 0, EndOffset: -1},
+		1: {StartOffset: 0, EndOffset: -1},
+		2: {StartOffset: 0, EndOffset: -1},
+	}}
+
+	t.Run("Should list messages partition by partition by default", func(t *testing.T) {
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			allPartitions,
+			model.FetchOptions{},
+		)
+		assert.NoError(t, err)
+		assert.Len(t, topicMessages.Messages, 30)
+		for i := 1; i < len(topicMessages.Messages); i++ {
+			previous, message := topicMessages.Messages[i-1], topicMessages.Messages[i]
+			assert.True(
+				t,
+				previous.Partition < message.Partition ||
+					previous.Partition == message.Partition && previous.Offset < message.Offset,
+			)
+		}
+	})
+
+	t.Run("Should merge partitions by timestamp", func(t *testing.T) {
+		for _, order := range []model.MessageOrder{model.OrderTimestamp, model.OrderNewestFirst} {
+			topicMessages, err := kafkaService.GetMessagesForTopic(
+				context.Background(),
+				topic,
+				allPartitions,
+				model.FetchOptions{Order: order},
+			)
+			assert.NoError(t, err)
+			assert.Len(t, topicMessages.Messages, 30)
+			for i := 1; i < len(topicMessages.Messages); i++ {
+				previous, message := topicMessages.Messages[i-1], topicMessages.Messages[i]
+				if order == model.OrderTimestamp {
+					assert.True(t, previous.Timestamp.Before(message.Timestamp))
+				} else {
+					assert.True(t, previous.Timestamp.After(message.Timestamp))
+				}
+			}
+		}
+	})
+
+	t.Run("Should fetch the newest messages across the whole topic", func(t *testing.T) {
+		topicMessages, err := kafkaService.GetLatestMessagesForTopic(
+			context.Background(),
+			topic,
+			6,
+			model.FetchOptions{Order: model.OrderNewestFirst},
+		)
+		assert.NoError(t, err)
+		assert.Len(t, topicMessages.Messages, 6)
+		for i, message := range topicMessages.Messages {
+			assert.Equal(t, now.Add(time.Duration(-1-i)*time.Second), message.Timestamp)
+			assert.NotEqual(t, int32(0), message.Partition)
+		}
+		for _, coverage := range topicMessages.Partitions {
+			assert.True(t, coverage.Complete)
+			assert.NotEqual(t, int32(0), coverage.Partition)
+		}
+	})
+
+	t.Run("Should fetch the whole topic when it holds fewer messages than requested", func(t *testing.T) {
+		topicMessages, err := kafkaService.GetLatestMessagesForTopic(
+			context.Background(),
+			topic,
+			100,
+			model.FetchOptions{Order: model.OrderTimestamp},
+		)
+		assert.NoError(t, err)
+		assert.Len(t, topicMessages.Messages, 30)
+		assert.Len(t, topicMessages.Partitions, 3)
+	})
+
+	teardown(t, kafkaService, admin, []string{topic})
+}
+
+func createTimestampedMessage(topic string, partition int32, timestamp time.Time) *sarama.ProducerMessage {
+	return &sarama.ProducerMessage{
+		Topic:     topic,
+		Partition: partition,
+		Key:       sarama.StringEncoder(fmt.Sprintf(`{"partition":%d}`, partition)),
+		Value:     sarama.StringEncoder(fmt.Sprintf(`{"timestamp":"%s"}`, timestamp.Format(time.RFC3339Nano))),
+		Timestamp: timestamp,
+	}
+}
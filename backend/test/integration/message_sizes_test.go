@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"strings"
+	"testing"
+)
+
+func TestMessageSizes(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	limitedTopic := "test-message-sizes-limited"
+	err = admin.CreateTopic(limitedTopic, &sarama.TopicDetail{
+		NumPartitions:     2,
+		ReplicationFactor: 1,
+		ConfigEntries: map[string]*string{
+			"max.message.bytes": stringPointer("4096"),
+		},
+	}, false)
+	assert.NoError(t, err)
+	defaultTopic := "test-message-sizes-default"
+	err = admin.CreateTopic(defaultTopic, &sarama.TopicDetail{NumPartitions: 1, ReplicationFactor: 1}, false)
+	assert.NoError(t, err)
+
+	var messages []*sarama.ProducerMessage
+	for i := 0; i < 99; i++ {
+		messages = append(messages, &sarama.ProducerMessage{
+			Topic:     limitedTopic,
+			Partition: int32(i % 2),
+			Key:       sarama.StringEncoder("key"),
+			Value:     sarama.StringEncoder(strings.Repeat("v", 100)),
+			Headers:   []sarama.RecordHeader{{Key: []byte("h"), Value: []byte("1234")}},
+		})
+	}
+	messages = append(messages, &sarama.ProducerMessage{
+		Topic:     limitedTopic,
+		Partition: 1,
+		Key:       sarama.StringEncoder("key"),
+		Value:     sarama.StringEncoder(strings.Repeat("v", 3500)),
+	})
+	for i := 0; i < 10; i++ {
+		messages = append(messages, &sarama.ProducerMessage{Topic: defaultTopic, Value: sarama.StringEncoder("small")})
+	}
+	assert.NoError(t, produceMessages(client, messages))
+
+	options := model.MessageSizeOptions{SampleSize: 1000, Threshold: 0.8}
+
+	t.Run("Should flag a topic whose largest records near its own limit", func(t *testing.T) {
+		sizes, err := kafkaService.AnalyzeMessageSizes(context.Background(), []string{limitedTopic}, options)
+		assert.NoError(t, err)
+		assert.Len(t, sizes, 1)
+		topicSizes := sizes[0]
+		assert.Equal(t, int64(100), topicSizes.SampledMessages)
+		assert.Equal(t, int64(3), topicSizes.Key.Max)
+		assert.Equal(t, int64(100), topicSizes.Value.P50)
+		assert.Equal(t, int64(100), topicSizes.Value.P99)
+		assert.Equal(t, int64(3500), topicSizes.Value.Max)
+		assert.Equal(t, int64(5), topicSizes.Headers.P50)
+		// The largest record has no headers
+		assert.Equal(t, int64(5), topicSizes.Headers.Max)
+		assert.Equal(t, int64(108), topicSizes.Record.P95)
+		assert.Equal(t, int64(3503), topicSizes.Record.Max)
+
+		assert.True(t, topicSizes.TopicOverride)
+		assert.Equal(t, int64(4096), *topicSizes.MaxMessageBytes)
+		assert.NotNil(t, topicSizes.BrokerMaxMessageBytes)
+		assert.InDelta(t, 3503.0/4096.0, *topicSizes.Utilization, 1e-9)
+		assert.True(t, topicSizes.AtRisk)
+	})
+
+	t.Run("Should fall back to the limit of the brokers", func(t *testing.T) {
+		sizes, err := kafkaService.AnalyzeMessageSizes(context.Background(), []string{defaultTopic}, options)
+		assert.NoError(t, err)
+		assert.Len(t, sizes, 1)
+		topicSizes := sizes[0]
+		assert.Equal(t, int64(10), topicSizes.SampledMessages)
+		assert.Equal(t, int64(0), topicSizes.Key.Max)
+		assert.Equal(t, int64(5), topicSizes.Record.Max)
+		assert.False(t, topicSizes.TopicOverride)
+		assert.Equal(t, *topicSizes.BrokerMaxMessageBytes, *topicSizes.MaxMessageBytes)
+		assert.False(t, topicSizes.AtRisk)
+	})
+
+	t.Run("Should order every topic by utilization", func(t *testing.T) {
+		sizes, err := kafkaService.AnalyzeMessageSizes(context.Background(), nil, options)
+		assert.NoError(t, err)
+		var topics []string
+		for _, topicSizes := range sizes {
+			topics = append(topics, topicSizes.Topic)
+		}
+		assert.Contains(t, topics, defaultTopic)
+		assert.Equal(t, limitedTopic, topics[0])
+	})
+
+	t.Run("Should leave out missing topics", func(t *testing.T) {
+		sizes, err := kafkaService.AnalyzeMessageSizes(
+			context.Background(),
+			[]string{"test-message-sizes-missing"},
+			options,
+		)
+		assert.NoError(t, err)
+		assert.Empty(t, sizes)
+	})
+
+	teardown(t, kafkaService, admin, []string{limitedTopic, defaultTopic})
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/jsonvalue"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestSchemaInference(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-schema-inference"
+	assert.NoError(t, createTopics(admin, []string{topic}))
+	values := []string{
+		`{"id": 1, "name": "a", "price": 1.5, "customer": {"email": "a@example.com"}, "tags": ["new"]}`,
+		`{"id": 2, "name": null, "price": 2, "customer": {"email": "b@example.com"}, "tags": []}`,
+		`{"id": 3, "name": "c", "price": 3, "status": "paid", "tags": [1]}`,
+		`{"id": "4", "name": "d", "price": 4, "status": "paid", "tags": ["old"]}`,
+	}
+	messages := make([]*sarama.ProducerMessage, 0, len(values)+1)
+	for _, value := range values {
+		messages = append(messages, &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(value)})
+	}
+	messages = append(messages, &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder("not json")})
+	assert.NoError(t, produceMessages(client, messages))
+
+	topicMessages, err := kafkaService.GetLatestMessagesForTopic(
+		context.Background(),
+		topic,
+		10,
+		model.FetchOptions{Order: model.OrderTimestamp},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, topicMessages.Messages, 5)
+	inferrer := jsonvalue.NewSchemaInferrer(2)
+	for _, message := range topicMessages.Messages {
+		if message.ValueJsonPayload != nil {
+			inferrer.Add(*message.ValueJsonPayload)
+		}
+	}
+
+	t.Run("Should report the types, presence and nullability of every field", func(t *testing.T) {
+		fields := make(map[string]model.FieldSchema)
+		for _, field := range inferrer.Fields() {
+			fields[field.Path] = field
+		}
+		assert.Equal(t, map[model.SchemaType]int64{model.SchemaObject: 4}, fields["$"].Types)
+
+		assert.True(t, fields["$.id"].Conflict)
+		assert.Equal(t, map[model.SchemaType]int64{model.SchemaInteger: 3, model.SchemaString: 1}, fields["$.id"].Types)
+		assert.Len(t, fields["$.id"].Examples, 2)
+
+		assert.False(t, fields["$.price"].Conflict)
+		assert.Equal(t, 0.25, fields["$.name"].Nullability)
+		assert.Equal(t, 0.5, fields["$.status"].Presence)
+		assert.Equal(t, 0.5, fields["$.customer"].Presence)
+		assert.Equal(t, 1.0, fields["$.customer.email"].Presence)
+		assert.True(t, fields["$.tags[*]"].Conflict)
+	})
+
+	t.Run("Should export the schema as JSON Schema", func(t *testing.T) {
+		schema := inferrer.JSONSchema(topic)
+		assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+		assert.Equal(t, []string{"id", "name", "price", "tags"}, schema["required"])
+		properties := schema["properties"].(map[string]any)
+		assert.Equal(t, model.SchemaNumber, properties["price"].(map[string]any)["type"])
+		assert.Equal(t, []model.SchemaType{model.SchemaNull, model.SchemaString}, properties["name"].(map[string]any)["type"])
+	})
+
+	t.Run("Should export the schema as an Avro schema", func(t *testing.T) {
+		schema, err := json.Marshal(inferrer.AvroSchema(topic+"-value", "com.example"))
+		assert.NoError(t, err)
+		_, err = goavro.NewCodec(string(schema))
+		assert.NoError(t, err)
+		var record struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+			Fields    []struct {
+				Name    string `json:"name"`
+				Type    any    `json:"type"`
+				Default any    `json:"default"`
+			} `json:"fields"`
+		}
+		assert.NoError(t, json.Unmarshal(schema, &record))
+		assert.Equal(t, "TestSchemaInferenceValue", record.Name)
+		assert.Equal(t, "com.example", record.Namespace)
+		types := make(map[string]any)
+		for _, field := range record.Fields {
+			types[field.Name] = field.Type
+		}
+		assert.Equal(t, []any{"long", "string"}, types["id"])
+		assert.Equal(t, "double", types["price"])
+		assert.Equal(t, []any{"null", "string"}, types["status"])
+	})
+
+	teardown(t, kafkaService, admin, []string{topic})
+}
@@ -0,0 +1,156 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestTopicDiff(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+	// The same cluster under another name, as a migration to another cluster would be compared
+	kafkaService.RegisterCluster("mirror", []string{bootstrapAddress}, config)
+
+	sourceTopic := "test-topic-diff-source"
+	targetTopic := "test-topic-diff-target"
+	for _, topic := range []string{sourceTopic, targetTopic} {
+		err = admin.CreateTopic(topic, &sarama.TopicDetail{NumPartitions: 2, ReplicationFactor: 1}, false)
+		assert.NoError(t, err)
+	}
+
+	var messages []*sarama.ProducerMessage
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		messages = append(messages, createDiffMessage(sourceTopic, int32(i%2), key, fmt.Sprintf(`{"id":%d,"ts":1}`, i)))
+		switch i {
+		case 3:
+			// Missing from the target
+		case 5:
+			messages = append(messages, createDiffMessage(targetTopic, 0, key, `{"id":50,"ts":2,"added":true}`))
+		default:
+			// Copied to other partitions, with the fields in another order and another timestamp
+			value := fmt.Sprintf(`{"ts":2,"id":%d}`, i)
+			messages = append(messages, createDiffMessage(targetTopic, int32((i+1)%2), key, value))
+		}
+	}
+	messages = append(messages, createDiffMessage(targetTopic, 1, "key-10", `{"id":10,"ts":2}`))
+	assert.NoError(t, produceMessages(client, messages))
+
+	options := model.DiffOptions{IgnorePaths: []string{"$.ts"}, MaxReported: 10}
+
+	t.Run("Should report missing, extra and differing records", func(t *testing.T) {
+		var read int64
+		diff, err := kafkaService.DiffTopics(
+			context.Background(),
+			model.DiffSource{Topic: sourceTopic},
+			model.DiffSource{Cluster: "mirror", Topic: targetTopic},
+			options,
+			func(progress int64) {
+				read = progress
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), diff.SourceMessages)
+		assert.Equal(t, int64(10), diff.TargetMessages)
+		assert.Equal(t, int64(20), read)
+		assert.Equal(t, int64(8), diff.Matched)
+		assert.Equal(t, int64(1), diff.Missing)
+		assert.Equal(t, int64(1), diff.Extra)
+		assert.Equal(t, int64(1), diff.Differing)
+
+		assert.Len(t, diff.MissingRecords, 1)
+		assert.Equal(t, "key-3", *diff.MissingRecords[0].Key)
+		assert.Len(t, diff.ExtraRecords, 1)
+		assert.Equal(t, "key-10", *diff.ExtraRecords[0].Key)
+		assert.Len(t, diff.DifferingRecords, 1)
+		differing := diff.DifferingRecords[0]
+		assert.Equal(t, "key-5", *differing.Source.Key)
+		assert.Equal(t, int32(1), differing.Source.Partition)
+		assert.Equal(t, int32(0), differing.Target.Partition)
+		assert.Len(t, differing.Changes, 2)
+		assert.Equal(t, "$.added", differing.Changes[0].Path)
+		assert.Equal(t, model.ChangeAdded, differing.Changes[0].Type)
+		assert.Equal(t, "$.id", differing.Changes[1].Path)
+		assert.Equal(t, model.ChangeChanged, differing.Changes[1].Type)
+		assert.Equal(t, 5.0, *differing.Changes[1].Source.NumberVal)
+		assert.Equal(t, 50.0, *differing.Changes[1].Target.NumberVal)
+	})
+
+	t.Run("Should only ignore the given paths", func(t *testing.T) {
+		diff, err := kafkaService.DiffTopics(
+			context.Background(),
+			model.DiffSource{Topic: sourceTopic},
+			model.DiffSource{Topic: targetTopic},
+			model.DiffOptions{MaxReported: 1},
+			func(int64) {},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), diff.Matched)
+		assert.Equal(t, int64(9), diff.Differing)
+		assert.Len(t, diff.DifferingRecords, 1)
+		assert.Equal(t, "$.ts", diff.DifferingRecords[0].Changes[0].Path)
+	})
+
+	t.Run("Should compare offset ranges", func(t *testing.T) {
+		// Partition 0 of the source holds key-0, key-2, key-4, key-6 and key-8
+		diff, err := kafkaService.DiffTopics(
+			context.Background(),
+			model.DiffSource{Topic: sourceTopic, Partitions: model.PartitionInput{
+				PartitionDetailsMap: map[int32]model.PartitionDetails{0: {StartOffset: 0, EndOffset: 2}},
+			}},
+			model.DiffSource{Topic: sourceTopic, Partitions: model.PartitionInput{
+				PartitionDetailsMap: map[int32]model.PartitionDetails{0: {StartOffset: 1, EndOffset: 4}},
+			}},
+			options,
+			func(int64) {},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), diff.Matched)
+		assert.Equal(t, int64(1), diff.Missing)
+		assert.Equal(t, "key-0", *diff.MissingRecords[0].Key)
+		assert.Equal(t, int64(2), diff.Extra)
+	})
+
+	t.Run("Should refuse unknown clusters", func(t *testing.T) {
+		_, err := kafkaService.DiffTopics(
+			context.Background(),
+			model.DiffSource{Topic: sourceTopic},
+			model.DiffSource{Cluster: "unknown", Topic: targetTopic},
+			options,
+			func(int64) {},
+		)
+		assert.Error(t, err)
+	})
+
+	teardown(t, kafkaService, admin, []string{sourceTopic, targetTopic})
+}
+
+func createDiffMessage(topic string, partition int32, key string, value string) *sarama.ProducerMessage {
+	return &sarama.ProducerMessage{
+		Topic:     topic,
+		Partition: partition,
+		Key:       sarama.StringEncoder(key),
+		Value:     sarama.StringEncoder(value),
+	}
+}
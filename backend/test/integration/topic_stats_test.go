@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/Avi18971911/kafka-window/backend/internal/stats"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestTopicStats(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-topic-stats"
+	err = admin.CreateTopic(topic, &sarama.TopicDetail{
+		NumPartitions:     2,
+		ReplicationFactor: 1,
+		ConfigEntries: map[string]*string{
+			"retention.bytes": stringPointer("1048576"),
+		},
+	}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, produceMessages(client, createPartitionMessages(topic, 0, 20)))
+
+	sampleInterval := 200 * time.Millisecond
+	sampler := stats.NewSampler(
+		kafkaService,
+		stats.Config{SampleInterval: sampleInterval, Windows: []time.Duration{time.Second, 10 * time.Second}},
+		logger,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sampler.Run(ctx)
+	time.Sleep(2 * sampleInterval)
+	assert.NoError(t, produceMessages(client, createPartitionMessages(topic, 0, 30)))
+	assert.NoError(t, produceMessages(client, createPartitionMessages(topic, 1, 10)))
+	time.Sleep(3 * sampleInterval)
+
+	t.Run("Should report throughput, sizes and skew of a topic", func(t *testing.T) {
+		topicStats, err := sampler.GetTopicStats(context.Background(), topic)
+		assert.NoError(t, err)
+		assert.Len(t, topicStats, 1)
+		stats := topicStats[0]
+		assert.Equal(t, topic, stats.Topic)
+		assert.Equal(t, int64(60), stats.Offsets)
+		assert.Len(t, stats.Partitions, 2)
+		assert.Equal(t, int64(50), stats.Partitions[0].NewestOffset)
+		assert.Equal(t, int64(10), stats.Partitions[1].NewestOffset)
+		assert.Greater(t, stats.SizeBytes, int64(0))
+		assert.Equal(t, stats.SizeBytes, stats.ReplicatedSizeBytes)
+
+		assert.Len(t, stats.Rates, 2)
+		for _, rate := range stats.Rates {
+			assert.Greater(t, rate.MessagesPerSecond, 0.0)
+			assert.Greater(t, rate.BytesPerSecond, 0.0)
+		}
+		assert.NotNil(t, stats.ThroughputSkew)
+		assert.Greater(t, *stats.ThroughputSkew, 1.0)
+		assert.NotNil(t, stats.SizeSkew)
+		assert.Greater(t, *stats.SizeSkew, 1.0)
+	})
+
+	t.Run("Should report how much of its retention a topic uses", func(t *testing.T) {
+		topicStats, err := sampler.GetTopicStats(context.Background(), topic)
+		assert.NoError(t, err)
+		retention := topicStats[0].Retention
+		assert.Equal(t, model.CleanupPolicyDelete, retention.CleanupPolicy)
+		// The broker's default of seven days
+		assert.Equal(t, int64(7*24*time.Hour/time.Millisecond), *retention.RetentionMs)
+		assert.Equal(t, int64(1048576), *retention.RetentionBytes)
+		assert.NotNil(t, retention.SizeUtilization)
+		assert.Greater(t, *retention.SizeUtilization, 0.0)
+		assert.NotNil(t, retention.OldestRecordAgeMs)
+		assert.NotNil(t, retention.TimeUtilization)
+		assert.NotNil(t, topicStats[0].Partitions[0].OldestTimestamp)
+	})
+
+	t.Run("Should report missing topics", func(t *testing.T) {
+		topicStats, err := sampler.GetTopicStats(context.Background(), "test-topic-stats-missing")
+		assert.NoError(t, err)
+		assert.Nil(t, topicStats)
+	})
+
+	teardown(t, kafkaService, admin, []string{topic})
+}
+
+func createPartitionMessages(topic string, partition int32, numMessages int) []*sarama.ProducerMessage {
+	messages := make([]*sarama.ProducerMessage, numMessages)
+	for i := range messages {
+		messages[i] = createTimestampedMessage(topic, partition, time.Now())
+	}
+	return messages
+}
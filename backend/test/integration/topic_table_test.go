@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/table"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestTopicTable(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-topic-table"
+	err = admin.CreateTopic(topic, &sarama.TopicDetail{
+		NumPartitions:     2,
+		ReplicationFactor: 1,
+		ConfigEntries: map[string]*string{
+			"cleanup.policy":            stringPointer("compact"),
+			"min.cleanable.dirty.ratio": stringPointer("0.3"),
+		},
+	}, false)
+	assert.NoError(t, err)
+	uncompactedTopic := "test-topic-table-uncompacted"
+	err = admin.CreateTopic(uncompactedTopic, &sarama.TopicDetail{NumPartitions: 1, ReplicationFactor: 1}, false)
+	assert.NoError(t, err)
+
+	// Ten keys over two partitions, each written three times, after which one key is deleted
+	var messages []*sarama.ProducerMessage
+	for version := 0; version < 3; version++ {
+		for i := 0; i < 10; i++ {
+			messages = append(messages, &sarama.ProducerMessage{
+				Topic:     topic,
+				Partition: int32(i % 2),
+				Key:       sarama.StringEncoder(fmt.Sprintf("key-%d", i)),
+				Value:     sarama.StringEncoder(fmt.Sprintf(`{"version":%d}`, version)),
+			})
+		}
+	}
+	messages = append(messages, &sarama.ProducerMessage{
+		Topic:     topic,
+		Partition: 1,
+		Key:       sarama.StringEncoder("key-3"),
+	})
+	assert.NoError(t, produceMessages(client, messages))
+
+	var scanned int64
+	topicTable, err := kafkaService.ScanTable(context.Background(), topic, func(progress int64) {
+		scanned = progress
+	})
+	assert.NoError(t, err)
+
+	t.Run("Should report the health of compaction", func(t *testing.T) {
+		summary := topicTable.Summary
+		assert.Equal(t, int64(31), summary.ScannedMessages)
+		assert.Equal(t, int64(31), scanned)
+		assert.Equal(t, int64(9), summary.Keys)
+		assert.Equal(t, int64(1), summary.Tombstones)
+		assert.Equal(t, int64(21), summary.DuplicateMessages)
+		assert.InDelta(t, 21.0/31.0, summary.DirtyRatio, 1e-9)
+		assert.NotNil(t, summary.MinCleanableDirtyRatio)
+		assert.Equal(t, 0.3, *summary.MinCleanableDirtyRatio)
+	})
+
+	store := table.NewStore(table.Config{MaxTables: 1})
+	assert.NoError(t, store.Add("scan", topicTable))
+	scan := store.Get("scan")
+
+	t.Run("Should page through the latest value of every key", func(t *testing.T) {
+		rows, total := scan.Rows(0, 5, false)
+		assert.Equal(t, 9, total)
+		assert.Len(t, rows, 5)
+		assert.Equal(t, "key-0", rows[0].Key)
+		assert.Equal(t, "key-4", rows[3].Key)
+		assert.Equal(t, `{"version":2}`, rows[0].Message.Value)
+
+		rows, total = scan.Rows(5, 5, true)
+		assert.Equal(t, 10, total)
+		assert.Len(t, rows, 5)
+	})
+
+	t.Run("Should look up keys", func(t *testing.T) {
+		row, ok := scan.Lookup([]byte("key-7"), false)
+		assert.True(t, ok)
+		assert.Equal(t, int32(1), row.Partition)
+		assert.False(t, row.Tombstone)
+		assert.Equal(t, `{"version":2}`, row.Message.Value)
+
+		_, ok = scan.Lookup([]byte("key-3"), false)
+		assert.False(t, ok)
+		row, ok = scan.Lookup([]byte("key-3"), true)
+		assert.True(t, ok)
+		assert.True(t, row.Tombstone)
+		assert.Nil(t, row.Message)
+
+		_, ok = scan.Lookup([]byte("key-missing"), true)
+		assert.False(t, ok)
+	})
+
+	t.Run("Should only keep the latest scans", func(t *testing.T) {
+		assert.NoError(t, store.Add("rescan", topicTable))
+		assert.Nil(t, store.Get("scan"))
+		assert.NotNil(t, store.Get("rescan"))
+	})
+
+	t.Run("Should refuse topics that aren't compacted", func(t *testing.T) {
+		_, err := kafkaService.ScanTable(context.Background(), uncompactedTopic, func(int64) {})
+		assert.Error(t, err)
+	})
+
+	t.Run("Should report missing topics", func(t *testing.T) {
+		missingTable, err := kafkaService.ScanTable(context.Background(), "test-topic-table-missing", func(int64) {})
+		assert.NoError(t, err)
+		assert.Nil(t, missingTable)
+	})
+
+	teardown(t, kafkaService, admin, []string{topic, uncompactedTopic})
+}
@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"context"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestTransactionState(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	assertPrerequisites(t)
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_6_0_0
+	config.Producer.Return.Successes = true
+
+	client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+	initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+	topic := "test-topic-transaction-state"
+	assert.NoError(t, createTopic(admin, topic, 1, 1))
+	// Offsets 0-2 and marker 3, offsets 4-5 and marker 6, offset 7, then offsets 8-9 of a transaction left open
+	committingProducer := newTransactionalProducer(t, "test-transaction-state-commit")
+	defer committingProducer.Close()
+	assert.NoError(t, produceTransaction(committingProducer, createKeyedMessages(topic, 3, 3), true))
+	assert.NoError(t, produceTransaction(committingProducer, createKeyedMessages(topic, 2, 2), false))
+	assert.NoError(t, produceMessages(client, createKeyedMessages(topic, 1, 1)))
+	openProducer := newTransactionalProducer(t, "test-transaction-state-open")
+	defer openProducer.Close()
+	assert.NoError(t, openProducer.BeginTxn())
+	assert.NoError(t, openProducer.SendMessages(createKeyedMessages(topic, 2, 2)))
+
+	getStates := func(messages []*model.Message) map[int64]model.TransactionState {
+		states := make(map[int64]model.TransactionState, len(messages))
+		for _, message := range messages {
+			states[message.Offset] = message.TransactionState
+		}
+		return states
+	}
+
+	t.Run("Should mark the transaction state of each message under read_uncommitted", func(t *testing.T) {
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, -1),
+			model.FetchOptions{},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int64]model.TransactionState{
+			0: model.TransactionCommitted,
+			1: model.TransactionCommitted,
+			2: model.TransactionCommitted,
+			4: model.TransactionAborted,
+			5: model.TransactionAborted,
+			7: "",
+			8: model.TransactionOpen,
+			9: model.TransactionOpen,
+		}, getStates(topicMessages.Messages))
+		coverage := topicMessages.Partitions[0]
+		assert.True(t, coverage.Complete)
+		assert.Equal(t, int64(10), coverage.HighWatermark)
+		assert.Equal(t, int64(8), coverage.LastStableOffset)
+	})
+
+	t.Run("Should leave out aborted and open transactions under read_committed", func(t *testing.T) {
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, -1),
+			model.FetchOptions{IsolationLevel: model.ReadCommitted},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int64]model.TransactionState{
+			0: model.TransactionCommitted,
+			1: model.TransactionCommitted,
+			2: model.TransactionCommitted,
+			7: "",
+		}, getStates(topicMessages.Messages))
+		coverage := topicMessages.Partitions[0]
+		assert.True(t, coverage.Complete)
+		assert.Equal(t, int64(8), coverage.NextOffset)
+	})
+
+	t.Run("Should show transaction markers as messages when asked to", func(t *testing.T) {
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 0, -1),
+			model.FetchOptions{IncludeControlRecords: true},
+		)
+		assert.NoError(t, err)
+		controlTypes := make(map[int64]model.ControlType)
+		for _, message := range topicMessages.Messages {
+			if message.ControlType != "" {
+				controlTypes[message.Offset] = message.ControlType
+			}
+		}
+		assert.Equal(t, map[int64]model.ControlType{3: model.ControlCommit, 6: model.ControlAbort}, controlTypes)
+		assert.Len(t, topicMessages.Messages, 10)
+	})
+
+	t.Run("Should resolve the state of transactions that end after the requested range", func(t *testing.T) {
+		assert.NoError(t, openProducer.CommitTxn())
+		topicMessages, err := kafkaService.GetMessagesForTopic(
+			context.Background(),
+			topic,
+			getPartitionInput(0, 8, 9),
+			model.FetchOptions{},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int64]model.TransactionState{
+			8: model.TransactionCommitted,
+			9: model.TransactionCommitted,
+		}, getStates(topicMessages.Messages))
+	})
+
+	teardown(t, kafkaService, admin, []string{topic})
+}
@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Avi18971911/kafka-window/backend/internal/avro"
+	"github.com/Avi18971911/kafka-window/backend/internal/decoder"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka"
+	"github.com/Avi18971911/kafka-window/backend/internal/kafka/model"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+type decodedTransactionKey struct {
+	TransactionalID string `json:"transactionalId"`
+}
+
+type decodedTransactionValue struct {
+	ProducerID    int64  `json:"producerId"`
+	ProducerEpoch int16  `json:"producerEpoch"`
+	State         string `json:"state"`
+	Partitions    []struct {
+		Topic      string  `json:"topic"`
+		Partitions []int32 `json:"partitions"`
+	} `json:"partitions"`
+}
+
+func TestTransactionStateTopic(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %s", err)
+	}
+	avroService := avro.NewAvroService(avro.NewConfig(false, nil))
+	kafkaService := kafka.NewKafkaService(decoder.NewMessageDecoder(avroService, logger), logger)
+
+	t.Run("Should decode the records the coordinator writes for a committed transaction", func(t *testing.T) {
+		assertPrerequisites(t)
+		config := sarama.NewConfig()
+		config.Version = sarama.V3_6_0_0
+		config.Producer.Return.Successes = true
+
+		client, admin := getClientAndAdmin(t, bootstrapAddress, config)
+		initializeKafkaService(t, kafkaService, bootstrapAddress, config)
+
+		topic := "test-topic-transaction-state-log"
+		transactionalID := "test-transaction-state-log"
+		assert.NoError(t, createTopic(admin, topic, 2, 1))
+		producer := newTransactionalProducer(t, transactionalID)
+		defer producer.Close()
+		assert.NoError(t, producer.BeginTxn())
+		var producedPartitions []int32
+		for _, message := range createKeyedMessages(topic, 4, 4) {
+			partition, _, err := producer.SendMessage(message)
+			assert.NoError(t, err)
+			if !slices.Contains(producedPartitions, partition) {
+				producedPartitions = append(producedPartitions, partition)
+			}
+		}
+		assert.NoError(t, producer.CommitTxn())
+
+		// The coordinator keeps a transactional ID in the partition its Java hash code maps to
+		assert.NoError(t, client.RefreshMetadata("__transaction_state"))
+		partitions, err := client.Partitions("__transaction_state")
+		assert.NoError(t, err)
+		partition := getTransactionStatePartition(transactionalID, len(partitions))
+
+		var states []decodedTransactionValue
+		assert.Eventually(t, func() bool {
+			topicMessages, err := kafkaService.GetMessagesForTopic(
+				context.Background(),
+				"__transaction_state",
+				getPartitionInput(partition, 0, -1),
+				model.FetchOptions{},
+			)
+			if err != nil {
+				return false
+			}
+			states = states[:0]
+			for _, message := range topicMessages.Messages {
+				assert.Equal(t, model.TransactionStatePayload, message.KeyPayloadType)
+				var key decodedTransactionKey
+				assert.NoError(t, json.Unmarshal([]byte(message.Key), &key))
+				if key.TransactionalID != transactionalID {
+					continue
+				}
+				assert.Equal(t, model.TransactionStatePayload, message.ValuePayloadType)
+				var value decodedTransactionValue
+				assert.NoError(t, json.Unmarshal([]byte(message.Value), &value))
+				states = append(states, value)
+			}
+			return len(states) > 0 && states[len(states)-1].State == "CompleteCommit"
+		}, 10*time.Second, 500*time.Millisecond)
+
+		prepareCommit := slices.IndexFunc(states, func(state decodedTransactionValue) bool {
+			return state.State == "PrepareCommit"
+		})
+		if !assert.NotEqual(t, -1, prepareCommit) {
+			return
+		}
+		prepared := states[prepareCommit]
+		completed := states[len(states)-1]
+		assert.GreaterOrEqual(t, prepared.ProducerID, int64(0))
+		assert.GreaterOrEqual(t, prepared.ProducerEpoch, int16(0))
+		assert.Equal(t, prepared.ProducerID, completed.ProducerID)
+		assert.Equal(t, prepared.ProducerEpoch, completed.ProducerEpoch)
+		assert.Len(t, prepared.Partitions, 1)
+		assert.Equal(t, topic, prepared.Partitions[0].Topic)
+		assert.ElementsMatch(t, producedPartitions, prepared.Partitions[0].Partitions)
+		// The partitions are cleared once the markers are written
+		assert.Empty(t, completed.Partitions)
+
+		teardown(t, kafkaService, admin, []string{topic})
+	})
+}
+
+// getTransactionStatePartition mirrors how the coordinator partitions __transaction_state, by the absolute Java
+// hash code of the transactional ID.
+func getTransactionStatePartition(transactionalID string, numPartitions int) int32 {
+	var hash int32
+	for _, char := range transactionalID {
+		hash = 31*hash + char
+	}
+	if hash == math.MinInt32 {
+		// Kafka's abs maps the lowest int, which has no positive counterpart, to 0
+		hash = 0
+	} else if hash < 0 {
+		hash = -hash
+	}
+	return hash % int32(numPartitions)
+}
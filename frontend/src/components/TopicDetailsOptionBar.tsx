@@ -1,4 +1,5 @@
 import React, {useEffect, useRef} from "react";
+import {MessageOrder} from "../model/MessageDetails.ts";
 
 export type partitionNumberOption = 'All' | number
 
@@ -8,6 +9,7 @@ type TopicDetailsOptionBarProps = {
     partitions: partitionNumberOption[],
     onPartitionChange: (partition: partitionNumberOption) => void
     onPartitionDetailsChange: (startOffset: number, endOffset: number) => void
+    onOrderChange: (order: MessageOrder) => void
 }
 
 const partitionOffsetOptions: partitionOffsetOption[] = [
@@ -16,6 +18,12 @@ const partitionOffsetOptions: partitionOffsetOption[] = [
     'Custom',
 ]
 
+const messageOrderOptions: { value: MessageOrder, label: string }[] = [
+    { value: 'partition_offset', label: 'Partition, Offset' },
+    { value: 'timestamp', label: 'Oldest First' },
+    { value: 'newest_first', label: 'Newest First' },
+]
+
 type offsetState = {
     startOffset: number,
     endOffset: number,
@@ -26,7 +34,7 @@ const defaultStartOffset = -1*defaultNumMessages;
 const defaultEndOffset = -1;
 
 const TopicDetailsOptionBar: React.FC<TopicDetailsOptionBarProps> = (
-    { partitions, onPartitionChange, onPartitionDetailsChange }
+    { partitions, onPartitionChange, onPartitionDetailsChange, onOrderChange }
 ) => {
     const [offsets, setOffsets] = React.useState<offsetState>(
         { startOffset: defaultStartOffset, endOffset: defaultEndOffset }
@@ -132,6 +140,10 @@ const TopicDetailsOptionBar: React.FC<TopicDetailsOptionBarProps> = (
         };
     }, []);
 
+    const handleOrderChange = (event: React.ChangeEvent<HTMLSelectElement>) => {
+        onOrderChange(event.target.value as MessageOrder);
+    }
+
     const handleStartOffsetChange = (event: React.ChangeEvent<HTMLInputElement>) => {
         const value = parseInt(event.target.value, 10);
         if (isNaN(value) || value == 0) {
@@ -199,6 +211,27 @@ const TopicDetailsOptionBar: React.FC<TopicDetailsOptionBarProps> = (
                 </select>
             </div>
 
+            <div
+                style={{
+                    display: 'flex',
+                    gap: '0.5rem',
+                    alignItems: 'center',
+                    flexDirection: 'column',
+                    fontSize: '0.875rem',
+                }}
+            >
+                Order
+                <select defaultValue = 'partition_offset' onChange={handleOrderChange}>
+                    {
+                        messageOrderOptions.map((option) => (
+                            <option key={option.value} value={option.value}>
+                                {option.label}
+                            </option>
+                        ))
+                    }
+                </select>
+            </div>
+
             {
                 isCustomOffset ?
                     <div
@@ -9,8 +9,16 @@ export type MessageDetails = {
     value: string
     valueJsonPayload: JSONValue
     valuePayloadType: PayloadType
+    transactionState?: TransactionState
+    controlType?: ControlType
 }
 
-export type PayloadType = 'string' | 'json' | 'consumerOffset'
+export type MessageOrder = 'partition_offset' | 'timestamp' | 'newest_first'
+
+export type TransactionState = 'committed' | 'aborted' | 'open'
+
+export type ControlType = 'commit' | 'abort'
+
+export type PayloadType = 'string' | 'json' | 'consumerOffset' | 'transactionState' | 'null'
 
 export type JSONValue = string | number | boolean | null | JSONValue[] | { [key: string]: JSONValue };
@@ -3,6 +3,7 @@ export type TopicDetails = {
     numPartitions: number;
     replicationFactor: number;
     isInternal: boolean;
+    kind: string;
     cleanupPolicy: string;
     retentionMs: RetentionMs | undefined;
     retentionBytes: number | undefined;
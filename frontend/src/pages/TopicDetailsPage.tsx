@@ -3,7 +3,7 @@ import {useApiClientContext} from "../provider/ApiClientProvider.tsx";
 import {TopicDetails} from "../model/TopicDetails.ts";
 import {PartitionDetails} from "../model/PartitionDetails.ts";
 import {mapModelMessageToMessage} from "../service/MessageService.ts";
-import {MessageDetails} from "../model/MessageDetails.ts";
+import {MessageDetails, MessageOrder} from "../model/MessageDetails.ts";
 import MessageDataTable from "../components/MessageDataTable.tsx";
 import {Link, useLocation} from "react-router-dom";
 import TopicDetailsOptionBar, {partitionNumberOption} from "../components/TopicDetailsOptionBar.tsx";
@@ -21,6 +21,7 @@ const TopicDetailsPage: React.FC = () => {
         getDefaultPartitionDetails(topic?.numPartitions ?? 0).map (partition => partition.partition)
     )
     const [messages, setMessages] = useState<MessageDetails[]>([])
+    const [order, setOrder] = useState<MessageOrder>('partition_offset')
     const [error, setError] = useState<string | null>(null)
     const apiClient = useApiClientContext()
     const partitionProps = useMemo(() =>
@@ -57,7 +58,8 @@ const TopicDetailsPage: React.FC = () => {
         const apiRequest = {
             topicMessagesInput: {
                 topicName: topic.topic,
-                partitions: partitionDetails
+                partitions: partitionDetails,
+                order: order
             }
         }
         apiClient.topicsMessagesPost(
@@ -67,7 +69,7 @@ const TopicDetailsPage: React.FC = () => {
             },
         ).then(
             (response) => {
-                const mappedResponse = mapModelMessageToMessage(response)
+                const mappedResponse = mapModelMessageToMessage(response.messages)
                 setMessages(mappedResponse)
             }
         ).catch(
@@ -81,7 +83,7 @@ const TopicDetailsPage: React.FC = () => {
         return () => {
             abortController.abort();
         }
-    }, [apiClient, partitionDetails, topic])
+    }, [apiClient, partitionDetails, order, topic])
 
     const messagesToShow = useMemo(() =>
         messages.filter(message => (
@@ -107,6 +109,7 @@ const TopicDetailsPage: React.FC = () => {
                                 partitions={partitionProps}
                                 onPartitionChange={handlePartitionNumberChange}
                                 onPartitionDetailsChange={handleOffsetChange}
+                                onOrderChange={setOrder}
                             />
                             <div>
                                 <MessageDataTable messages={messagesToShow}/>
@@ -1,5 +1,5 @@
 import {ModelJSONValue, ModelMessage, ModelPayloadType} from "../backend_api";
-import {JSONValue, MessageDetails, PayloadType} from "../model/MessageDetails.ts";
+import {ControlType, JSONValue, MessageDetails, PayloadType, TransactionState} from "../model/MessageDetails.ts";
 
 export const mapModelMessageToMessage = (model: ModelMessage[]): MessageDetails[] => {
     return  model.map((modelDetails) => {
@@ -13,7 +13,9 @@ export const mapModelMessageToMessage = (model: ModelMessage[]): MessageDetails[
             topic: modelDetails.topic,
             value: modelDetails.value,
             valueJsonPayload: mapJSONValueToNativeType(modelDetails.valueJsonPayload),
-            valuePayloadType: mapModelPayloadTypeToPayloadType(modelDetails.valuePayloadType)
+            valuePayloadType: mapModelPayloadTypeToPayloadType(modelDetails.valuePayloadType),
+            transactionState: modelDetails.transactionState as TransactionState | undefined,
+            controlType: modelDetails.controlType as ControlType | undefined
         }
     });
 }
@@ -49,6 +51,10 @@ const mapModelPayloadTypeToPayloadType = (type: ModelPayloadType): PayloadType =
             return 'json';
         case ModelPayloadType.ConsumerOffsetPayload:
             return 'consumerOffset';
+        case ModelPayloadType.TransactionStatePayload:
+            return 'transactionState';
+        case ModelPayloadType.NullPayload:
+            return 'null';
         default:
             throw new Error(`Unknown ModelPayloadType: ${type}`);
     }
@@ -8,6 +8,7 @@ export const mapModelTopicDetailsToTopicDetails = (model: ModelTopicDetails[]):
             numPartitions: modelDetails.numPartitions,
             replicationFactor: modelDetails.replicationFactor,
             isInternal: modelDetails.isInternal,
+            kind: modelDetails.kind,
             cleanupPolicy: modelDetails.cleanupPolicy,
             retentionMs: modelDetails.retentionMs,
             retentionBytes: modelDetails.retentionBytes,